@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// AppBaseURL returns the public URL of the website (the frontend), without a trailing slash.
+// It is used to build absolute links, e.g. in emails. Set it with the APP_BASE_URL environment variable.
+func AppBaseURL() string {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// AppHost returns the host name of AppBaseURL in lower case, without "www."
+func AppHost() string {
+	base, err := url.Parse(AppBaseURL())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(base.Hostname()), "www.")
+}
+
+// AbsoluteURL turns a site-relative path such as "/uploads/photo.jpg" into an absolute URL on
+// AppBaseURL. Values that already are absolute URLs are returned unchanged.
+func AbsoluteURL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return AppBaseURL() + path
+}
+
+// NewsURL returns the public URL of a news post on the website
+func NewsURL(slug string) string {
+	return AbsoluteURL("/berita/" + slug)
+}
+
+// ServiceURL returns the public URL of a village service on the website. Services have no page of
+// their own, so it points at the service's card on the /layanan page.
+func ServiceURL(id uint64) string {
+	return AbsoluteURL(fmt.Sprintf("/layanan#layanan-%d", id))
+}
+
+// NewsSchedulerInterval returns how often scheduled publishing and unpublishing is checked.
+// Set it with the NEWS_SCHEDULER_INTERVAL environment variable (e.g. "1m").
+func NewsSchedulerInterval() time.Duration {
+	return utils.DurationFromEnv("NEWS_SCHEDULER_INTERVAL", time.Minute)
+}
+
+// UploadGCInterval returns how often unused uploads are looked for (UPLOAD_GC_INTERVAL, default 24h)
+func UploadGCInterval() time.Duration {
+	return utils.DurationFromEnv("UPLOAD_GC_INTERVAL", 24*time.Hour)
+}
+
+// UploadGCGracePeriod returns how old an unused upload must be before it is quarantined
+// (UPLOAD_GC_GRACE_PERIOD, default 7 days). It leaves time to save the content an upload was made for.
+func UploadGCGracePeriod() time.Duration {
+	return utils.DurationFromEnv("UPLOAD_GC_GRACE_PERIOD", 7*24*time.Hour)
+}
+
+// UploadGCQuarantinePeriod returns how long a quarantined upload is kept before it is deleted
+// for good (UPLOAD_GC_QUARANTINE_PERIOD, default 30 days)
+func UploadGCQuarantinePeriod() time.Duration {
+	return utils.DurationFromEnv("UPLOAD_GC_QUARANTINE_PERIOD", 30*24*time.Hour)
+}
+
+// PageViewQueueSize returns how many page views may wait to be written before new ones are
+// dropped (PAGE_VIEW_QUEUE_SIZE, default 10000)
+func PageViewQueueSize() int {
+	return intFromEnv("PAGE_VIEW_QUEUE_SIZE", 10000)
+}
+
+// PageViewFlushInterval returns how often queued page views are written to the database
+// (PAGE_VIEW_FLUSH_INTERVAL, default 5s). A full batch is written right away.
+func PageViewFlushInterval() time.Duration {
+	return utils.DurationFromEnv("PAGE_VIEW_FLUSH_INTERVAL", 5*time.Second)
+}
+
+// AnalyticsRollupInterval returns how often finished days of page views are summarized
+// (ANALYTICS_ROLLUP_INTERVAL, default 1h). Days missed while the server was down are caught up.
+func AnalyticsRollupInterval() time.Duration {
+	return utils.DurationFromEnv("ANALYTICS_ROLLUP_INTERVAL", time.Hour)
+}
+
+// AnalyticsRawRetention returns how long raw page views are kept once summarized
+// (ANALYTICS_RAW_RETENTION, default 90 days)
+func AnalyticsRawRetention() time.Duration {
+	return utils.DurationFromEnv("ANALYTICS_RAW_RETENTION", 90*24*time.Hour)
+}
+
+// intFromEnv parses a positive integer from an environment variable, falling back to a default
+// if it is missing or invalid
+func intFromEnv(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
@@ -1,16 +1,19 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
-	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/db/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func InitDB() *gorm.DB {
+// ConnectDB opens the database connection configured by the DB_* environment variables
+func ConnectDB() *gorm.DB {
 	dbHost := os.Getenv("DB_HOST")
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
@@ -34,6 +37,18 @@ func InitDB() *gorm.DB {
 	}
 
 	log.Println("Database connection established")
+	return db
+}
+
+// InitDB connects to the database and applies pending migrations, unless DB_AUTO_MIGRATE is
+// "false" (then run "migrate up" before starting a new version)
+func InitDB() *gorm.DB {
+	db := ConnectDB()
+
+	if strings.EqualFold(os.Getenv("DB_AUTO_MIGRATE"), "false") {
+		log.Println("Automatic database migrations are disabled (DB_AUTO_MIGRATE=false)")
+		return db
+	}
 
 	// Run database migrations
 	log.Println("Running database migrations...")
@@ -45,32 +60,20 @@ func InitDB() *gorm.DB {
 	return db
 }
 
+// runMigrations applies the embedded SQL migrations that have not been applied yet
 func runMigrations(db *gorm.DB) error {
-	// Create custom ENUM types if they don't exist
-	enumTypes := []string{
-		"CREATE TYPE user_role AS ENUM ('superadmin', 'admin', 'author')",
-		"CREATE TYPE news_status AS ENUM ('draft', 'published', 'archived')",
-		"CREATE TYPE potential_type AS ENUM ('umkm', 'tourism', 'agriculture', 'other')",
-		"CREATE TYPE media_type AS ENUM ('image', 'video')",
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
 	}
-
-	for _, enumSQL := range enumTypes {
-		// Check if type exists, if not create it
-		if err := db.Exec(enumSQL).Error; err != nil {
-			// Ignore error if type already exists
-			log.Printf("Note: %v (this is normal if types already exist)", err)
-		}
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		return err
 	}
 
-	// Auto-migrate tables
-	return db.AutoMigrate(
-		&models.User{},
-		&models.News{},
-		&models.VillageOfficial{},
-		&models.Service{},
-		&models.Potential{},
-		&models.SiteSetting{},
-		&models.Contact{},
-		&models.HeroSlider{},
-	)
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		log.Printf("Applied migration %06d_%s", migration.Version, migration.Name)
+	}
+	return err
 }
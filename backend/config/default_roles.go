@@ -0,0 +1,123 @@
+package config
+
+import "github.com/ihsanularifinm/sid-seirotan/backend/models"
+
+// Named permissions checked by middlewares.RequirePermission
+const (
+	PermissionMediaUpload      = "media.upload"
+	PermissionMediaManage      = "media.manage"
+	PermissionUsersManage      = "users.manage"
+	PermissionUsersSecurity    = "users.security"
+	PermissionNewsWrite        = "news.write"
+	PermissionNewsPublish      = "news.publish"
+	PermissionNewsManageAll    = "news.manage_all"
+	PermissionCategoriesWrite  = "categories.write"
+	PermissionOfficialsWrite   = "officials.write"
+	PermissionServicesWrite    = "services.write"
+	PermissionPotentialsWrite  = "potentials.write"
+	PermissionContactsRead     = "contacts.read"
+	PermissionContactsManage   = "contacts.manage"
+	PermissionHeroSlidersWrite = "hero_sliders.write"
+	PermissionSettingsWrite    = "settings.write"
+	PermissionDashboardRead    = "dashboard.read"
+	PermissionRolesManage      = "roles.manage"
+)
+
+// PermissionSchema describes a permission for the role management UI
+type PermissionSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions is the catalog of every permission known to the application
+var Permissions = []PermissionSchema{
+	{Name: PermissionMediaUpload, Description: "Mengunggah file dan gambar"},
+	{Name: PermissionMediaManage, Description: "Mengubah dan menghapus file di pustaka media"},
+	{Name: PermissionUsersManage, Description: "Mengelola pengguna dan sesi login"},
+	{Name: PermissionUsersSecurity, Description: "Mereset 2FA dan membuat tautan atur ulang kata sandi pengguna"},
+	{Name: PermissionNewsWrite, Description: "Membuat dan mengubah berita"},
+	{Name: PermissionNewsPublish, Description: "Menerbitkan berita"},
+	{Name: PermissionNewsManageAll, Description: "Melihat, mengubah dan menghapus berita milik penulis lain"},
+	{Name: PermissionCategoriesWrite, Description: "Mengelola kategori dan tag berita"},
+	{Name: PermissionOfficialsWrite, Description: "Mengelola aparatur desa"},
+	{Name: PermissionServicesWrite, Description: "Mengelola layanan desa"},
+	{Name: PermissionPotentialsWrite, Description: "Mengelola potensi desa"},
+	{Name: PermissionContactsRead, Description: "Membaca pesan kontak"},
+	{Name: PermissionContactsManage, Description: "Mengelola pesan kontak (status, penanggung jawab, catatan, hapus)"},
+	{Name: PermissionHeroSlidersWrite, Description: "Mengelola hero slider"},
+	{Name: PermissionSettingsWrite, Description: "Mengubah pengaturan situs"},
+	{Name: PermissionDashboardRead, Description: "Melihat dasbor dan statistik"},
+	{Name: PermissionRolesManage, Description: "Mengelola role dan hak akses"},
+}
+
+// IsKnownPermission reports whether a permission name exists in the catalog
+func IsKnownPermission(name string) bool {
+	for _, p := range Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllPermissionNames returns the names of every permission in the catalog
+func AllPermissionNames() []string {
+	names := make([]string, len(Permissions))
+	for i, p := range Permissions {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// GetDefaultRoles returns the built-in roles with the access they had when roles were hard-coded.
+// Permissions are only granted once: removing one from a role later is not undone by the seeder.
+func GetDefaultRoles() []models.Role {
+	superadminDescription := "Akses penuh ke seluruh sistem"
+	adminDescription := "Mengelola konten dan pengguna desa"
+	authorDescription := "Menulis berita"
+
+	return []models.Role{
+		{
+			Name:        string(models.UserRoleSuperadmin),
+			Description: &superadminDescription,
+			IsSystem:    true,
+			Permissions: rolePermissions(AllPermissionNames()...),
+		},
+		{
+			Name:        string(models.UserRoleAdmin),
+			Description: &adminDescription,
+			IsSystem:    true,
+			Permissions: rolePermissions(
+				PermissionMediaUpload,
+				PermissionMediaManage,
+				PermissionUsersManage,
+				PermissionNewsWrite,
+				PermissionNewsPublish,
+				PermissionNewsManageAll,
+				PermissionCategoriesWrite,
+				PermissionOfficialsWrite,
+				PermissionServicesWrite,
+				PermissionPotentialsWrite,
+				PermissionContactsRead,
+				PermissionContactsManage,
+				PermissionHeroSlidersWrite,
+				PermissionSettingsWrite,
+				PermissionDashboardRead,
+			),
+		},
+		{
+			Name:        string(models.UserRoleAuthor),
+			Description: &authorDescription,
+			IsSystem:    true,
+			Permissions: rolePermissions(PermissionNewsWrite),
+		},
+	}
+}
+
+func rolePermissions(names ...string) []models.RolePermission {
+	permissions := make([]models.RolePermission, len(names))
+	for i, name := range names {
+		permissions[i] = models.RolePermission{Permission: name}
+	}
+	return permissions
+}
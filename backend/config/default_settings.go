@@ -191,6 +191,56 @@ var DefaultSettings = []SettingSchema{
 		Group:        "social",
 		Description:  "URL TikTok profile (opsional)",
 	},
+
+	// Security Settings (1 item)
+	{
+		Key:          "two_factor_required_roles",
+		DefaultValue: "",
+		Group:        "security",
+		Description:  "Role yang wajib memakai autentikasi dua faktor, pisahkan dengan koma (contoh: superadmin,admin). Hanya superadmin yang dapat mengubah",
+	},
+
+	// SEO Settings (2 items)
+	{
+		Key:          "search_engine_indexing",
+		DefaultValue: "true",
+		Group:        "seo",
+		Description:  "Izinkan mesin pencari mengindeks website (true/false). Jika false, robots.txt melarang seluruh halaman",
+	},
+	{
+		Key:          "robots_disallow",
+		DefaultValue: "/admin\n/api/",
+		Group:        "seo",
+		Description:  "Path yang tidak boleh dirayapi mesin pencari, satu per baris (ditulis ke robots.txt)",
+	},
+
+	// Privacy Settings (2 items)
+	{
+		Key:          AnalyticsRawRetentionSettingKey,
+		DefaultValue: "90",
+		Group:        "privacy",
+		Description:  "Lama data kunjungan rinci (halaman, perujuk, browser, ID pengunjung) disimpan, dalam hari. Data yang lebih lama dihapus setelah diringkas per hari; ringkasan harian tidak berisi data pribadi dan disimpan seterusnya",
+	},
+	{
+		Key:          "analytics_privacy_notice",
+		DefaultValue: "Website ini mencatat kunjungan halaman tanpa cookie. Alamat IP tidak disimpan: ID pengunjung dibuat dari alamat IP dan browser dengan kunci rahasia yang diganti dan dibuang setiap hari, sehingga tidak dapat dikembalikan ke alamat IP atau dihubungkan antar hari. Data kunjungan rinci dihapus setelah 90 hari. Kunjungan dari browser yang mengaktifkan Do Not Track atau Global Privacy Control tidak dicatat.",
+		Group:        "privacy",
+		Description:  "Penjelasan pencatatan kunjungan untuk pengunjung website (sesuaikan bila lama penyimpanan diubah)",
+	},
+}
+
+// AnalyticsRawRetentionSettingKey is the site setting holding how many days raw page views are kept
+const AnalyticsRawRetentionSettingKey = "analytics_raw_retention_days"
+
+// SuperadminOnlySettingKeys lists settings that only a superadmin may change
+var SuperadminOnlySettingKeys = map[string]bool{
+	"two_factor_required_roles": true,
+}
+
+// PrivateSettingKeys lists settings left out of the public settings endpoints: they are only
+// shown in the admin panel
+var PrivateSettingKeys = map[string]bool{
+	"two_factor_required_roles": true,
 }
 
 // GetDefaultSettingsByGroup returns default settings filtered by group
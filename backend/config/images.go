@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Image variant names
+const (
+	ImageVariantThumb  = "thumb"
+	ImageVariantMedium = "medium"
+	ImageVariantLarge  = "large"
+)
+
+// ImageVariant is a resized copy made of every uploaded image. The image is scaled down to fit
+// within MaxWidth x MaxHeight, keeping its aspect ratio.
+type ImageVariant struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// defaultImageVariants are the variant sizes used unless overridden with IMAGE_VARIANT_<NAME>
+var defaultImageVariants = []ImageVariant{
+	{Name: ImageVariantThumb, MaxWidth: 320, MaxHeight: 320},
+	{Name: ImageVariantMedium, MaxWidth: 800, MaxHeight: 800},
+	{Name: ImageVariantLarge, MaxWidth: 1600, MaxHeight: 1600},
+}
+
+// uploadTypeVariants lists which variants are made for each upload type. Types not listed
+// (such as the legacy upload endpoint) get every variant.
+var uploadTypeVariants = map[string][]string{
+	"news":        {ImageVariantThumb, ImageVariantMedium, ImageVariantLarge},
+	"official":    {ImageVariantThumb, ImageVariantMedium},
+	"logo":        {ImageVariantThumb},
+	"hero_slider": {ImageVariantMedium, ImageVariantLarge},
+	"struktur":    {ImageVariantMedium, ImageVariantLarge},
+}
+
+// ImageVariants returns every configured image variant. A size can be changed with an
+// environment variable such as IMAGE_VARIANT_THUMB=400x400.
+func ImageVariants() []ImageVariant {
+	variants := make([]ImageVariant, len(defaultImageVariants))
+	for i, variant := range defaultImageVariants {
+		if value := os.Getenv("IMAGE_VARIANT_" + strings.ToUpper(variant.Name)); value != "" {
+			var width, height int
+			if _, err := fmt.Sscanf(strings.ToLower(value), "%dx%d", &width, &height); err == nil && width > 0 && height > 0 {
+				variant.MaxWidth, variant.MaxHeight = width, height
+			}
+		}
+		variants[i] = variant
+	}
+	return variants
+}
+
+// ImageVariantNames returns the names of every image variant
+func ImageVariantNames() []string {
+	names := make([]string, len(defaultImageVariants))
+	for i, variant := range defaultImageVariants {
+		names[i] = variant.Name
+	}
+	return names
+}
+
+// ImageVariantsFor returns the variants made for an upload type
+func ImageVariantsFor(uploadType string) []ImageVariant {
+	all := ImageVariants()
+	names, ok := uploadTypeVariants[uploadType]
+	if !ok {
+		return all
+	}
+
+	var variants []ImageVariant
+	for _, variant := range all {
+		for _, name := range names {
+			if variant.Name == name {
+				variants = append(variants, variant)
+			}
+		}
+	}
+	return variants
+}
+
+// ImageJPEGQuality returns the JPEG quality (1-100) used for re-encoded images.
+// Set it with the IMAGE_JPEG_QUALITY environment variable.
+func ImageJPEGQuality() int {
+	quality, err := strconv.Atoi(os.Getenv("IMAGE_JPEG_QUALITY"))
+	if err != nil || quality < 1 || quality > 100 {
+		return 82
+	}
+	return quality
+}
+
+// ImageWebPQuality returns the WebP quality (1-100) used for the WebP variants of photos.
+// Set it with the IMAGE_WEBP_QUALITY environment variable.
+func ImageWebPQuality() int {
+	quality, err := strconv.Atoi(os.Getenv("IMAGE_WEBP_QUALITY"))
+	if err != nil || quality < 1 || quality > 100 {
+		return 80
+	}
+	return quality
+}
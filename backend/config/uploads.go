@@ -0,0 +1,62 @@
+package config
+
+import "slices"
+
+// MIME types accepted for uploads, detected from the file content
+const (
+	MIMETypeJPEG = "image/jpeg"
+	MIMETypePNG  = "image/png"
+	MIMETypeWebP = "image/webp"
+	MIMETypeGIF  = "image/gif"
+	MIMETypeSVG  = "image/svg+xml"
+	MIMETypeMP4  = "video/mp4"
+	MIMETypeWebM = "video/webm"
+)
+
+// MIMETypeExtensions lists the file extensions allowed for each accepted MIME type. The first
+// one is used when an uploaded file has no extension.
+var MIMETypeExtensions = map[string][]string{
+	MIMETypeJPEG: {".jpg", ".jpeg"},
+	MIMETypePNG:  {".png"},
+	MIMETypeWebP: {".webp"},
+	MIMETypeGIF:  {".gif"},
+	MIMETypeSVG:  {".svg"},
+	MIMETypeMP4:  {".mp4"},
+	MIMETypeWebM: {".webm"},
+}
+
+// UploadRule limits what may be uploaded for an upload type
+type UploadRule struct {
+	AllowedTypes []string // MIME types
+	MaxSize      int64    // Bytes
+}
+
+const megabyte = 1 << 20
+
+var photoTypes = []string{MIMETypeJPEG, MIMETypePNG, MIMETypeWebP}
+
+// uploadRules holds the rule of every upload type. The empty type is the legacy upload
+// endpoint, which is used for hero slider images and videos.
+var uploadRules = map[string]UploadRule{
+	"":            {AllowedTypes: slices.Concat(photoTypes, []string{MIMETypeGIF, MIMETypeMP4, MIMETypeWebM}), MaxSize: 50 * megabyte},
+	"news":        {AllowedTypes: slices.Concat(photoTypes, []string{MIMETypeGIF}), MaxSize: 10 * megabyte},
+	"official":    {AllowedTypes: photoTypes, MaxSize: 5 * megabyte},
+	"logo":        {AllowedTypes: []string{MIMETypePNG, MIMETypeSVG}, MaxSize: 2 * megabyte},
+	"hero_slider": {AllowedTypes: slices.Concat(photoTypes, []string{MIMETypeMP4, MIMETypeWebM}), MaxSize: 50 * megabyte},
+	"struktur":    {AllowedTypes: photoTypes, MaxSize: 10 * megabyte},
+}
+
+// UploadRuleFor returns the rule of an upload type and whether the type exists
+func UploadRuleFor(uploadType string) (UploadRule, bool) {
+	rule, ok := uploadRules[uploadType]
+	return rule, ok
+}
+
+// MaxUploadSize returns the largest file size allowed by any upload type
+func MaxUploadSize() int64 {
+	var largest int64
+	for _, rule := range uploadRules {
+		largest = max(largest, rule.MaxSize)
+	}
+	return largest
+}
@@ -19,4 +19,4 @@ DROP TABLE IF EXISTS contacts;
 DROP TYPE IF EXISTS media_type;
 DROP TYPE IF EXISTS user_role;
 DROP TYPE IF EXISTS news_status;
-DROP TYPE IF EXISTS potential_type;
\ No newline at end of file
+DROP TYPE IF EXISTS potential_type;
@@ -1,11 +1,26 @@
+-- Every statement is guarded with IF NOT EXISTS, so databases created by the former GORM
+-- AutoMigrate start from this migration without errors.
+
 -- Create ENUM types
-CREATE TYPE user_role AS ENUM ('superadmin', 'admin', 'author');
-CREATE TYPE news_status AS ENUM ('draft', 'published', 'archived');
-CREATE TYPE potential_type AS ENUM ('umkm', 'tourism', 'agriculture', 'other');
-CREATE TYPE media_type AS ENUM ('image', 'video');
+DO $$ BEGIN
+    CREATE TYPE user_role AS ENUM ('superadmin', 'admin', 'author');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+DO $$ BEGIN
+    CREATE TYPE news_status AS ENUM ('draft', 'published', 'archived');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+DO $$ BEGIN
+    CREATE TYPE potential_type AS ENUM ('umkm', 'tourism', 'agriculture', 'other');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+DO $$ BEGIN
+    CREATE TYPE media_type AS ENUM ('image', 'video');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
 
 -- Create users table
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id BIGSERIAL PRIMARY KEY,
     full_name VARCHAR(255) NOT NULL,
     username VARCHAR(100) UNIQUE NOT NULL,
@@ -17,7 +32,7 @@ CREATE TABLE users (
 );
 
 -- Create news table (with published_at column included)
-CREATE TABLE news (
+CREATE TABLE IF NOT EXISTS news (
     id BIGSERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     slug VARCHAR(255) UNIQUE NOT NULL,
@@ -33,7 +48,7 @@ CREATE TABLE news (
 );
 
 -- Create village_officials table (with hamlet_name column)
-CREATE TABLE village_officials (
+CREATE TABLE IF NOT EXISTS village_officials (
     id BIGSERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     position VARCHAR(255) NOT NULL,
@@ -50,7 +65,7 @@ CREATE TABLE village_officials (
 COMMENT ON COLUMN village_officials.hamlet_name IS 'Custom hamlet identifier (e.g., IX-A, Makmur). If null, hamlet_number will be converted to Roman numeral for display.';
 
 -- Create services table
-CREATE TABLE services (
+CREATE TABLE IF NOT EXISTS services (
     id BIGSERIAL PRIMARY KEY,
     service_name VARCHAR(255) NOT NULL,
     description TEXT,
@@ -61,7 +76,7 @@ CREATE TABLE services (
 );
 
 -- Create potentials table
-CREATE TABLE potentials (
+CREATE TABLE IF NOT EXISTS potentials (
     id BIGSERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     description TEXT,
@@ -73,7 +88,7 @@ CREATE TABLE potentials (
 );
 
 -- Create site_settings table
-CREATE TABLE site_settings (
+CREATE TABLE IF NOT EXISTS site_settings (
     id BIGSERIAL PRIMARY KEY,
     setting_key VARCHAR(100) UNIQUE NOT NULL,
     setting_value TEXT,
@@ -84,7 +99,7 @@ CREATE TABLE site_settings (
 );
 
 -- Create contacts table
-CREATE TABLE contacts (
+CREATE TABLE IF NOT EXISTS contacts (
     id BIGSERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL,
@@ -96,7 +111,7 @@ CREATE TABLE contacts (
 );
 
 -- Create hero_sliders table
-CREATE TABLE hero_sliders (
+CREATE TABLE IF NOT EXISTS hero_sliders (
     id BIGSERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     subtitle VARCHAR(255),
@@ -112,7 +127,7 @@ CREATE TABLE hero_sliders (
 );
 
 -- Create page_views table for analytics tracking
-CREATE TABLE page_views (
+CREATE TABLE IF NOT EXISTS page_views (
     id BIGSERIAL PRIMARY KEY,
     page_url VARCHAR(500) NOT NULL,
     page_title VARCHAR(255),
@@ -124,7 +139,7 @@ CREATE TABLE page_views (
 );
 
 -- Create indexes for page_views table
-CREATE INDEX idx_page_views_viewed_at ON page_views(viewed_at);
-CREATE INDEX idx_page_views_page_url ON page_views(page_url);
-CREATE INDEX idx_page_views_visitor_id ON page_views(visitor_id);
-CREATE INDEX idx_page_views_url_viewed ON page_views(page_url, viewed_at);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_page_views_viewed_at ON page_views(viewed_at);
+CREATE INDEX IF NOT EXISTS idx_page_views_page_url ON page_views(page_url);
+CREATE INDEX IF NOT EXISTS idx_page_views_visitor_id ON page_views(visitor_id);
+CREATE INDEX IF NOT EXISTS idx_page_views_url_viewed ON page_views(page_url, viewed_at);
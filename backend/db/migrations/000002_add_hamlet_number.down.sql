@@ -0,0 +1 @@
+ALTER TABLE village_officials DROP COLUMN IF EXISTS hamlet_number;
@@ -0,0 +1,2 @@
+-- Numbered hamlets ("Dusun I", "Dusun II", ...) next to the custom hamlet_name
+ALTER TABLE village_officials ADD COLUMN IF NOT EXISTS hamlet_number INTEGER;
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS user_sessions;
+
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_secret;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS last_failed_login_at;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
@@ -0,0 +1,10 @@
+-- Users with a custom role fall back to admin
+DROP INDEX IF EXISTS idx_users_role;
+CREATE TYPE user_role AS ENUM ('superadmin', 'admin', 'author');
+UPDATE users SET role = 'admin' WHERE role NOT IN ('superadmin', 'admin', 'author');
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'admin';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,29 @@
+-- Roles are rows instead of a fixed ENUM, so new ones can be created at runtime.
+-- The built-in roles and their permissions are seeded by the application.
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Revoked permissions are soft deleted, which keeps the seeder from granting them again
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id BIGSERIAL PRIMARY KEY,
+    role_id BIGINT NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_roles_permissions FOREIGN KEY (role_id) REFERENCES roles(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_permission ON role_permissions(role_id, permission);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_deleted_at ON role_permissions(deleted_at);
+
+-- users.role holds the name of a role; existing values are kept as they are
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'admin';
+DROP TYPE IF EXISTS user_role;
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
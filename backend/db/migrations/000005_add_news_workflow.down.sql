@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS news_revisions;
+DROP TABLE IF EXISTS news_status_histories;
+
+DROP INDEX IF EXISTS idx_news_unpublish_at;
+ALTER TABLE news DROP COLUMN IF EXISTS unpublish_at;
+
+-- ENUM values cannot be dropped: posts in review or scheduled go back to draft and the type is rebuilt
+UPDATE news SET status = 'draft' WHERE status IN ('in_review', 'scheduled');
+ALTER TYPE news_status RENAME TO news_status_old;
+CREATE TYPE news_status AS ENUM ('draft', 'published', 'archived');
+ALTER TABLE news ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE news ALTER COLUMN status TYPE news_status USING status::text::news_status;
+ALTER TABLE news ALTER COLUMN status SET DEFAULT 'draft';
+DROP TYPE news_status_old;
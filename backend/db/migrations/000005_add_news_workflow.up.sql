@@ -0,0 +1,39 @@
+-- Editorial review and scheduled publishing
+ALTER TYPE news_status ADD VALUE IF NOT EXISTS 'in_review' BEFORE 'published';
+ALTER TYPE news_status ADD VALUE IF NOT EXISTS 'scheduled' BEFORE 'published';
+
+-- Posts are archived automatically once unpublish_at is reached
+ALTER TABLE news ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_news_unpublish_at ON news(unpublish_at);
+
+-- Who moved a post to which status, and when
+CREATE TABLE IF NOT EXISTS news_status_histories (
+    id BIGSERIAL PRIMARY KEY,
+    news_id BIGINT NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    note TEXT,
+    changed_by_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_news_status_histories_changed_by FOREIGN KEY (changed_by_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_news_status_histories_news_id ON news_status_histories(news_id);
+
+-- Full snapshot of every saved version of a post
+CREATE TABLE IF NOT EXISTS news_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    news_id BIGINT NOT NULL,
+    revision_number BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    featured_image_url VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    published_at TIMESTAMPTZ,
+    restored_from BIGINT,
+    editor_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_news_revisions_editor FOREIGN KEY (editor_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_news_revision ON news_revisions(news_id, revision_number);
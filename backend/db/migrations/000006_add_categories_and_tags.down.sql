@@ -0,0 +1,7 @@
+ALTER TABLE news DROP CONSTRAINT IF EXISTS fk_news_category;
+DROP INDEX IF EXISTS idx_news_category_id;
+ALTER TABLE news DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS news_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
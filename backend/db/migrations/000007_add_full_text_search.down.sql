@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_potentials_search_vector;
+DROP INDEX IF EXISTS idx_services_search_vector;
+DROP INDEX IF EXISTS idx_news_search_vector;
+ALTER TABLE potentials DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE services DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE news DROP COLUMN IF EXISTS search_vector;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS sid_search;
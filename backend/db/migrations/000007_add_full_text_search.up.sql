@@ -0,0 +1,26 @@
+-- sid_search ignores accents and uses the Indonesian stemmer when the server ships one
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'sid_search') THEN
+        IF EXISTS (SELECT 1 FROM pg_ts_dict WHERE dictname = 'indonesian_stem') THEN
+            CREATE TEXT SEARCH CONFIGURATION sid_search (COPY = indonesian);
+            ALTER TEXT SEARCH CONFIGURATION sid_search
+                ALTER MAPPING FOR hword, hword_part, word WITH unaccent, indonesian_stem;
+        ELSE
+            CREATE TEXT SEARCH CONFIGURATION sid_search (COPY = simple);
+            ALTER TEXT SEARCH CONFIGURATION sid_search
+                ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+        END IF;
+    END IF;
+END
+$$;
+
+-- The vectors are filled in by the application (repositories.SearchRepository.RebuildMissing)
+ALTER TABLE news ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE services ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE potentials ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+CREATE INDEX IF NOT EXISTS idx_news_search_vector ON news USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_services_search_vector ON services USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_potentials_search_vector ON potentials USING GIN (search_vector);
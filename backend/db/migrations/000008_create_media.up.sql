@@ -0,0 +1,24 @@
+-- Media library: one row per uploaded file, resized variants belong to their original
+CREATE TABLE IF NOT EXISTS media (
+    id BIGSERIAL PRIMARY KEY,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    thumbnail_url VARCHAR(500),
+    original_name VARCHAR(255) NOT NULL,
+    upload_type VARCHAR(30) NOT NULL DEFAULT '',
+    mime_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    width BIGINT,
+    height BIGINT,
+    alt_text VARCHAR(255),
+    quarantined_at TIMESTAMPTZ,
+    uploaded_by_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_media_uploaded_by FOREIGN KEY (uploaded_by_id) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_media_upload_type ON media(upload_type);
+CREATE INDEX IF NOT EXISTS idx_media_mime_type ON media(mime_type);
+CREATE INDEX IF NOT EXISTS idx_media_quarantined_at ON media(quarantined_at);
+CREATE INDEX IF NOT EXISTS idx_media_uploaded_by_id ON media(uploaded_by_id);
+CREATE INDEX IF NOT EXISTS idx_media_created_at ON media(created_at);
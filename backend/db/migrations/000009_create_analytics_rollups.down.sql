@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS analytics_daily_referrers;
+DROP TABLE IF EXISTS analytics_daily_pages;
+DROP TABLE IF EXISTS analytics_daily_stats;
@@ -0,0 +1,26 @@
+-- Daily analytics rollups: page_views summarized per day, so raw rows can be pruned
+CREATE TABLE IF NOT EXISTS analytics_daily_stats (
+    day DATE PRIMARY KEY,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Most viewed pages of each day
+CREATE TABLE IF NOT EXISTS analytics_daily_pages (
+    day DATE NOT NULL,
+    page_url VARCHAR(500) NOT NULL,
+    page_title VARCHAR(255),
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, page_url)
+);
+
+-- Most common referring domains of each day
+CREATE TABLE IF NOT EXISTS analytics_daily_referrers (
+    day DATE NOT NULL,
+    referrer VARCHAR(255) NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, referrer)
+);
@@ -0,0 +1,2 @@
+-- Re-hashed visitor IDs cannot be restored
+DROP TABLE IF EXISTS analytics_salts;
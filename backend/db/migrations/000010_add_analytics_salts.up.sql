@@ -0,0 +1,13 @@
+-- Secret salts for visitor IDs: one per day, deleted once the day is over
+CREATE TABLE IF NOT EXISTS analytics_salts (
+    day DATE PRIMARY KEY,
+    salt BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Visitor IDs recorded so far are plain SHA-256 hashes of the IP address, which can be brute-forced.
+-- Hash them again with a random salt that is not kept, plus the day, so they still count unique
+-- visitors per day but can no longer be traced back to an IP address or linked across days.
+UPDATE page_views
+SET visitor_id = encode(sha256(convert_to(visitor_id || ':' || salt.value || ':' || viewed_at::date::text, 'UTF8')), 'hex')
+FROM (SELECT gen_random_uuid()::text AS value) salt;
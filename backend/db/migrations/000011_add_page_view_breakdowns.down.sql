@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS analytics_daily_breakdowns;
+
+ALTER TABLE page_views DROP COLUMN IF EXISTS utm_content;
+ALTER TABLE page_views DROP COLUMN IF EXISTS utm_term;
+ALTER TABLE page_views DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE page_views DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE page_views DROP COLUMN IF EXISTS utm_source;
+ALTER TABLE page_views DROP COLUMN IF EXISTS os;
+ALTER TABLE page_views DROP COLUMN IF EXISTS browser;
+ALTER TABLE page_views DROP COLUMN IF EXISTS device;
+ALTER TABLE page_views DROP COLUMN IF EXISTS source;
+ALTER TABLE page_views DROP COLUMN IF EXISTS referrer_domain;
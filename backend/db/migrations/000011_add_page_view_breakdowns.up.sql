@@ -0,0 +1,26 @@
+-- Referrer, device and campaign of each page view, parsed when it is recorded
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS referrer_domain VARCHAR(255);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS device VARCHAR(20);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS browser VARCHAR(50);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS os VARCHAR(50);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS utm_term VARCHAR(255);
+ALTER TABLE page_views ADD COLUMN IF NOT EXISTS utm_content VARCHAR(255);
+
+-- The referrer domain of earlier views can still be derived; their other breakdowns stay unknown
+UPDATE page_views
+SET referrer_domain = regexp_replace(lower((regexp_match(referer, '^https?://([^/:?#]+)', 'i'))[1]), '^www\.', '')
+WHERE referer IS NOT NULL AND referer <> '';
+
+-- Views per value of each breakdown (source, device, browser, os, utm_source, utm_medium, utm_campaign) and day
+CREATE TABLE IF NOT EXISTS analytics_daily_breakdowns (
+    day DATE NOT NULL,
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, dimension, value)
+);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS contact_notes;
+
+DROP INDEX IF EXISTS idx_contacts_created_at;
+DROP INDEX IF EXISTS idx_contacts_assigned_to_id;
+DROP INDEX IF EXISTS idx_contacts_status;
+DROP INDEX IF EXISTS idx_contacts_is_read;
+
+ALTER TABLE contacts DROP CONSTRAINT IF EXISTS fk_contacts_assigned_to;
+ALTER TABLE contacts DROP CONSTRAINT IF EXISTS chk_contacts_status;
+ALTER TABLE contacts DROP COLUMN IF EXISTS assigned_to_id;
+ALTER TABLE contacts DROP COLUMN IF EXISTS status;
+ALTER TABLE contacts DROP COLUMN IF EXISTS read_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS is_read;
@@ -0,0 +1,25 @@
+-- Contact inbox: read state, handling status and assignee of each message
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS is_read BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'new';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS assigned_to_id BIGINT;
+
+ALTER TABLE contacts ADD CONSTRAINT chk_contacts_status CHECK (status IN ('new', 'in_progress', 'resolved', 'spam'));
+ALTER TABLE contacts ADD CONSTRAINT fk_contacts_assigned_to FOREIGN KEY (assigned_to_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_is_read ON contacts(is_read);
+CREATE INDEX IF NOT EXISTS idx_contacts_status ON contacts(status);
+CREATE INDEX IF NOT EXISTS idx_contacts_assigned_to_id ON contacts(assigned_to_id);
+CREATE INDEX IF NOT EXISTS idx_contacts_created_at ON contacts(created_at);
+
+-- Internal notes of the staff on a message
+CREATE TABLE IF NOT EXISTS contact_notes (
+    id BIGSERIAL PRIMARY KEY,
+    contact_id BIGINT NOT NULL,
+    author_id BIGINT,
+    note TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_contact_notes_contact FOREIGN KEY (contact_id) REFERENCES contacts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contact_notes_author FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_contact_notes_contact_id ON contact_notes(contact_id);
@@ -0,0 +1,2 @@
+-- The trimmed paths and query strings of referrers cannot be restored
+SELECT 1;
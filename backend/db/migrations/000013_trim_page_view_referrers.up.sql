@@ -0,0 +1,4 @@
+-- Keep only the origin of referrers: their path and query string may hold search terms or tokens
+UPDATE page_views
+SET referer = substring(referer FROM '^https?://[^/?#]+')
+WHERE referer ~* '^https?://[^/?#]+[/?#]';
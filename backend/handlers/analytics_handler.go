@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/jobs"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// TrackPageViewInput is the beacon the website sends for every page a visitor opens
+type TrackPageViewInput struct {
+	Path     string `json:"path" binding:"required"`
+	Title    string `json:"title"`
+	Referrer string `json:"referrer"`
+}
+
+// AnalyticsHandler receives page views from the website
+type AnalyticsHandler struct {
+	Recorder     *jobs.PageViewRecorder
+	PageViewRepo repositories.PageViewRepository
+
+	saltMu  sync.Mutex
+	saltDay time.Time // Day the cached salt belongs to
+	salt    []byte
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler
+func NewAnalyticsHandler(recorder *jobs.PageViewRecorder, pageViewRepo repositories.PageViewRepository) *AnalyticsHandler {
+	return &AnalyticsHandler{Recorder: recorder, PageViewRepo: pageViewRepo}
+}
+
+// TrackPageView queues a page view for the dashboard statistics. Views from bots, of admin
+// pages and of visitors asking not to be tracked (Do Not Track or Global Privacy Control) are
+// ignored. Recorded and ignored views alike get an empty 204 response, which never waits on
+// the database.
+func (h *AnalyticsHandler) TrackPageView(c *gin.Context) {
+	// Beacons are sent as text/plain to avoid a CORS preflight, so the body is read as JSON
+	// whatever its content type
+	var input TrackPageViewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Only the path and its utm_* parameters are kept: query strings may hold search terms or tokens
+	page, err := url.Parse(strings.TrimSpace(input.Path))
+	if err != nil || !strings.HasPrefix(page.Path, "/") || page.Host != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page path"})
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	if utils.IsBot(userAgent) || optedOut(c) || page.Path == "/admin" || strings.HasPrefix(page.Path, "/admin/") {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	now := time.Now()
+	salt, err := h.dailySalt(now)
+	if err != nil {
+		log.Printf("Failed to get the visitor ID salt, page view not recorded: %v", err)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	pageView := models.PageView{
+		PageURL:   truncateRunes(page.Path, 500),
+		VisitorID: utils.VisitorID(salt, c.ClientIP(), userAgent),
+		UserAgent: &userAgent,
+		ViewedAt:  now,
+	}
+	if title := truncateRunes(strings.TrimSpace(input.Title), 255); title != "" {
+		pageView.PageTitle = &title
+	}
+	// Only the origin of the referrer is kept, for the same reason as the query string of the path
+	if referrer, err := url.Parse(strings.TrimSpace(input.Referrer)); err == nil && (referrer.Scheme == "http" || referrer.Scheme == "https") && referrer.Host != "" {
+		value := truncateRunes(referrer.Scheme+"://"+referrer.Host, 500)
+		pageView.Referer = &value
+	}
+
+	// Break the view down by referrer, device and campaign now, so reports need no parsing
+	query := page.Query()
+	pageView.UTMSource = utmParam(query, "utm_source")
+	pageView.UTMMedium = utmParam(query, "utm_medium")
+	pageView.UTMCampaign = utmParam(query, "utm_campaign")
+	pageView.UTMTerm = utmParam(query, "utm_term")
+	pageView.UTMContent = utmParam(query, "utm_content")
+
+	domain := ""
+	if pageView.Referer != nil {
+		domain = truncateRunes(utils.ReferrerDomain(*pageView.Referer), 255)
+	}
+	if domain != "" {
+		pageView.ReferrerDomain = &domain
+	}
+	utmSource := ""
+	if pageView.UTMSource != nil {
+		utmSource = *pageView.UTMSource
+	}
+	source := utils.ReferrerSource(domain, utmSource, config.AppHost())
+	agent := utils.ParseUserAgent(userAgent)
+	pageView.Source, pageView.Device, pageView.Browser, pageView.OS = &source, &agent.Device, &agent.Browser, &agent.OS
+
+	// A full queue drops the view; the recorder reports how many were lost
+	h.Recorder.Record(pageView)
+	c.Status(http.StatusNoContent)
+}
+
+// dailySalt returns the secret salt of visitor IDs for the day of now. It is shared by all
+// replicas through the database and replaced every day; old salts are deleted.
+func (h *AnalyticsHandler) dailySalt(now time.Time) ([]byte, error) {
+	day := utils.StartOfDay(now)
+
+	h.saltMu.Lock()
+	defer h.saltMu.Unlock()
+	if h.salt != nil && h.saltDay.Equal(day) {
+		return h.salt, nil
+	}
+
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, err
+	}
+	salt, err := h.PageViewRepo.GetDailySalt(day, candidate)
+	if err != nil {
+		return nil, err
+	}
+	h.saltDay, h.salt = day, salt
+	return salt, nil
+}
+
+// utmParam returns a utm_* parameter of the page URL, or nil if it is missing
+func utmParam(query url.Values, name string) *string {
+	value := truncateRunes(strings.TrimSpace(query.Get(name)), 255)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// optedOut reports whether the browser asks not to be tracked, with Do Not Track or Global
+// Privacy Control
+func optedOut(c *gin.Context) bool {
+	return c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1"
+}
+
+// truncateRunes shortens a string to at most maxRunes characters
+func truncateRunes(value string, maxRunes int) string {
+	if utf8.RuneCountInString(value) <= maxRunes {
+		return value
+	}
+	return string([]rune(value)[:maxRunes])
+}
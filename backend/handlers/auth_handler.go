@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // LoginRequest represents the request body for admin login
@@ -17,18 +23,43 @@ type LoginRequest struct {
 
 // LoginResponse represents the response body for successful admin login
 type LoginResponse struct {
-	Token string `json:"token"`
-	Role  string `json:"role"`
+	Token         string   `json:"token"`
+	RefreshToken  string   `json:"refresh_token"`
+	ExpiresIn     int64    `json:"expires_in"` // Access token lifetime in seconds
+	Role          string   `json:"role"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // Only set when 2FA was enrolled during login
+}
+
+// TwoFactorChallengeResponse is returned by Login instead of a token when a second factor is needed
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired      bool   `json:"two_factor_required"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required"`
+	ChallengeToken         string `json:"challenge_token"`
+}
+
+// RefreshTokenRequest represents the request body for refreshing or revoking a session
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // AuthHandler handles authentication related requests
 type AuthHandler struct {
-	UserRepository repositories.UserRepository
+	UserRepository         repositories.UserRepository
+	SessionRepository      repositories.SessionRepository
+	LoginAttemptRepository repositories.LoginAttemptRepository
+	RecoveryCodeRepository repositories.RecoveryCodeRepository
+	SettingsRepository     repositories.SiteSettingsRepository
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(userRepo repositories.UserRepository) *AuthHandler {
-	return &AuthHandler{UserRepository: userRepo}
+func NewAuthHandler(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, loginAttemptRepo repositories.LoginAttemptRepository, recoveryCodeRepo repositories.RecoveryCodeRepository, settingsRepo repositories.SiteSettingsRepository) *AuthHandler {
+	return &AuthHandler{
+		UserRepository:         userRepo,
+		SessionRepository:      sessionRepo,
+		LoginAttemptRepository: loginAttemptRepo,
+		RecoveryCodeRepository: recoveryCodeRepo,
+		SettingsRepository:     settingsRepo,
+	}
 }
 
 // Login handles admin login
@@ -42,24 +73,267 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Get user from repository
 	user, err := h.UserRepository.GetUserByUsername(req.Username)
 	if err != nil {
+		utils.CheckDummyPassword(req.Password)
+		h.recordLoginAttempt(c, nil, req.Username, false, "unknown_user")
 		utils.RespondError(c, http.StatusUnauthorized, "Invalid credentials", nil)
 		return
 	}
 
+	if !h.checkLoginAllowed(c, user) {
+		return
+	}
+
 	// Compare password with the hashed password from the database
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
+		h.recordFailedLogin(c, user, "invalid_password")
 		utils.RespondError(c, http.StatusUnauthorized, "Invalid credentials", nil)
 		return
 	}
 
+	// Users with 2FA (or whose role requires it) get a challenge instead of a session
+	setupRequired := !user.TwoFactorEnabled && h.isTwoFactorRequired(user.Role)
+	if user.TwoFactorEnabled || setupRequired {
+		challengeToken, err := utils.GenerateChallengeToken(user.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to generate challenge token", err)
+			return
+		}
+		c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+			TwoFactorRequired:      user.TwoFactorEnabled,
+			TwoFactorSetupRequired: setupRequired,
+			ChallengeToken:         challengeToken,
+		})
+		return
+	}
+
+	h.completeLogin(c, user, nil)
+}
+
+// completeLogin clears the failure counter, starts a new session and writes the tokens.
+// recoveryCodes is only passed when 2FA was enrolled as part of this login.
+func (h *AuthHandler) completeLogin(c *gin.Context, user *models.User, recoveryCodes []string) {
+	// Successful login clears the failure counter
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := h.UserRepository.ResetFailedLogins(user.ID); err != nil {
+			log.Printf("Failed to reset failed logins for user %d: %v", user.ID, err)
+		}
+	}
+	h.recordLoginAttempt(c, &user.ID, user.Username, true, "")
+
+	refreshToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	// Start a new server-side session for this login
+	userAgent := c.Request.UserAgent()
+	ipAddress := c.ClientIP()
+	session := models.UserSession{
+		UserID:           user.ID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        &userAgent,
+		IPAddress:        &ipAddress,
+		ExpiresAt:        utils.SessionExpiry(time.Now()),
+		LastUsedAt:       time.Now(),
+	}
+	if err := h.SessionRepository.CreateSession(&session); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create session", err)
+		return
+	}
+
 	// Generate JWT token including the user's role
-	token, err := utils.GenerateToken(user.ID, user.Username, user.FullName, string(user.Role))
+	token, err := utils.GenerateToken(user.ID, user.Username, user.FullName, string(user.Role), session.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:         token,
+		RefreshToken:  refreshToken,
+		ExpiresIn:     int64(utils.AccessTokenTTL().Seconds()),
+		Role:          string(user.Role),
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// Refresh exchanges a valid refresh token for a new access token and a new refresh token.
+// The old refresh token stops working as soon as it has been used, and using it again revokes
+// the whole session.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "refresh_token is required", err)
+		return
+	}
+
+	oldHash := utils.HashToken(req.RefreshToken)
+	session, err := h.SessionRepository.GetSessionByTokenHash(oldHash)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.revokeReusedSession(oldHash)
+			utils.RespondError(c, http.StatusUnauthorized, "Invalid refresh token", nil)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve session", err)
+		return
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		utils.RespondError(c, http.StatusUnauthorized, "Session has been revoked or expired", nil)
+		return
+	}
+
+	// Re-read the user so that role changes are reflected in the new access token
+	user, err := h.UserRepository.GetUserByID(session.UserID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.SessionRepository.RevokeSession(session.ID)
+			utils.RespondError(c, http.StatusUnauthorized, "User no longer exists", nil)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve user", err)
+		return
+	}
+
+	newRefreshToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	rotated, err := h.SessionRepository.RotateToken(session.ID, oldHash, utils.HashToken(newRefreshToken), utils.SessionExpiry(session.CreatedAt))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to rotate refresh token", err)
+		return
+	}
+	if !rotated {
+		// Another request rotated the same token first
+		h.revokeReusedSession(oldHash)
+		utils.RespondError(c, http.StatusUnauthorized, "Invalid refresh token", nil)
+		return
+	}
+
+	token, err := utils.GenerateToken(user.ID, user.Username, user.FullName, string(user.Role), session.ID)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate token", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{Token: token, Role: string(user.Role)})
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+		Role:         string(user.Role),
+	})
+}
+
+// revokeReusedSession revokes the session a refresh token belonged to before it was rotated.
+// An old token coming back means it was copied: whoever refreshed first may be the thief, so
+// neither copy may keep the session.
+func (h *AuthHandler) revokeReusedSession(tokenHash string) {
+	session, err := h.SessionRepository.GetSessionByPreviousTokenHash(tokenHash)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Failed to look up reused refresh token: %v", err)
+		}
+		return
+	}
+	if session.RevokedAt != nil {
+		return
+	}
+	log.Printf("Refresh token of session %d (user %d) was used again after rotation; revoking the session", session.ID, session.UserID)
+	if err := h.SessionRepository.RevokeSession(session.ID); err != nil {
+		log.Printf("Failed to revoke session %d: %v", session.ID, err)
+	}
 }
 
+// Logout revokes the session belonging to the given refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "refresh_token is required", err)
+		return
+	}
+
+	session, err := h.SessionRepository.GetSessionByTokenHash(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Unknown token: nothing to revoke, treat as already logged out
+			c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve session", err)
+		return
+	}
+
+	if err := h.SessionRepository.RevokeSession(session.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to revoke session", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// checkLoginAllowed rejects attempts while the account is locked or still inside the
+// progressive delay after a failure. It writes the error response itself and returns false when blocked.
+func (h *AuthHandler) checkLoginAllowed(c *gin.Context, user *models.User) bool {
+	now := time.Now()
+
+	// Reject attempts while the account is locked
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		h.recordLoginAttempt(c, &user.ID, user.Username, false, "locked")
+		setRetryAfter(c, user.LockedUntil.Sub(now))
+		utils.RespondError(c, http.StatusLocked, "Account is temporarily locked due to too many failed login attempts", nil)
+		return false
+	}
+
+	// Progressive delay: each consecutive failure doubles the wait before the next attempt is
+	// evaluated. Failures that led to an expired lockout no longer count.
+	lockExpired := user.LockedUntil != nil && !now.Before(*user.LockedUntil)
+	if user.FailedLoginAttempts > 0 && user.LastFailedLoginAt != nil && !lockExpired {
+		nextAllowed := user.LastFailedLoginAt.Add(utils.LoginDelay(user.FailedLoginAttempts))
+		if now.Before(nextAllowed) {
+			h.recordLoginAttempt(c, &user.ID, user.Username, false, "throttled")
+			setRetryAfter(c, nextAllowed.Sub(now))
+			utils.RespondError(c, http.StatusTooManyRequests, "Too many login attempts. Please wait before trying again.", nil)
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordFailedLogin counts a failed password or 2FA code against the account and stores it in the history
+func (h *AuthHandler) recordFailedLogin(c *gin.Context, user *models.User, reason string) {
+	if _, err := h.UserRepository.RecordFailedLogin(user.ID, utils.MaxLoginAttempts(), utils.LoginLockoutDuration()); err != nil {
+		log.Printf("Failed to record failed login for user %d: %v", user.ID, err)
+	}
+	h.recordLoginAttempt(c, &user.ID, user.Username, false, reason)
+}
+
+// recordLoginAttempt stores a login attempt in the history. Failures are only logged,
+// they never block the login itself.
+func (h *AuthHandler) recordLoginAttempt(c *gin.Context, userID *uint64, username string, success bool, reason string) {
+	userAgent := c.Request.UserAgent()
+	attempt := models.LoginAttempt{
+		UserID:    userID,
+		Username:  username,
+		IPAddress: c.ClientIP(),
+		UserAgent: &userAgent,
+		Success:   success,
+	}
+	if reason != "" {
+		attempt.Reason = &reason
+	}
+	if err := h.LoginAttemptRepository.Create(&attempt); err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", username, err)
+	}
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds (rounded up)
+func setRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// CategoryInput defines the expected input for creating or updating a category
+type CategoryInput struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description"`
+}
+
+// CategoryHandler handles news category requests
+type CategoryHandler struct {
+	CategoryRepository repositories.CategoryRepository
+}
+
+// NewCategoryHandler creates a new CategoryHandler
+func NewCategoryHandler(categoryRepo repositories.CategoryRepository) *CategoryHandler {
+	return &CategoryHandler{CategoryRepository: categoryRepo}
+}
+
+// GetPublicCategories lists all categories with the number of published posts in each
+func (h *CategoryHandler) GetPublicCategories(c *gin.Context) {
+	categories, err := h.CategoryRepository.GetAllWithPostCount()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve categories", err)
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetAllCategories lists all categories (Admin protected)
+func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
+	categories, err := h.CategoryRepository.GetAll()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve categories", err)
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory creates a new category (Admin protected)
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	slug, err := h.generateUniqueSlug(name, 0)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate unique slug", err)
+		return
+	}
+
+	category := models.Category{Name: name, Slug: slug, Description: input.Description}
+	if err := h.CategoryRepository.Create(&category); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create category", err)
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory updates an existing category, regenerating the slug when the name changes (Admin protected)
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	category, ok := h.getCategory(c)
+	if !ok {
+		return
+	}
+
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name != category.Name {
+		slug, err := h.generateUniqueSlug(name, category.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to generate unique slug", err)
+			return
+		}
+		category.Name = name
+		category.Slug = slug
+	}
+	if input.Description != nil {
+		category.Description = input.Description
+	}
+
+	if err := h.CategoryRepository.Update(category); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update category", err)
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory deletes a category, its posts are kept without a category (Admin protected)
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	category, ok := h.getCategory(c)
+	if !ok {
+		return
+	}
+
+	if err := h.CategoryRepository.Delete(category.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete category", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// generateUniqueSlug creates a slug for a category name that no other category uses
+func (h *CategoryHandler) generateUniqueSlug(name string, currentID uint64) (string, error) {
+	return generateUniqueSlug(name, "kategori", func(slug string) (bool, error) {
+		return h.CategoryRepository.IsSlugExist(slug, currentID)
+	})
+}
+
+// getCategory loads the category identified by the :id parameter, responding with an error if it fails
+func (h *CategoryHandler) getCategory(c *gin.Context) (*models.Category, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return nil, false
+	}
+
+	category, err := h.CategoryRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Category not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve category", err)
+		return nil, false
+	}
+	return category, true
+}
@@ -2,22 +2,26 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
 )
 
-// ContactHandler handles contact form submissions
+// ContactHandler handles contact form submissions and the contact inbox of the admin panel
 type ContactHandler struct {
-	Repo repositories.ContactRepository
+	Repo     repositories.ContactRepository
+	UserRepo repositories.UserRepository
 }
 
 // NewContactHandler creates a new ContactHandler
-func NewContactHandler(repo repositories.ContactRepository) *ContactHandler {
-	return &ContactHandler{Repo: repo}
+func NewContactHandler(repo repositories.ContactRepository, userRepo repositories.UserRepository) *ContactHandler {
+	return &ContactHandler{Repo: repo, UserRepo: userRepo}
 }
 
 // CreateContactInput defines the input for creating a contact message
@@ -28,6 +32,35 @@ type CreateContactInput struct {
 	Message string `json:"message" binding:"required"`
 }
 
+// UpdateContactInput defines the inbox fields of a contact message an admin can change.
+// Omitted fields are left as they are; an assigned_to_id of 0 removes the assignee.
+type UpdateContactInput struct {
+	Status       *models.ContactStatus `json:"status" binding:"omitempty,oneof=new in_progress resolved spam"`
+	IsRead       *bool                 `json:"is_read"`
+	AssignedToID *uint64               `json:"assigned_to_id"`
+}
+
+// ContactNoteInput defines an internal note added to a contact message
+type ContactNoteInput struct {
+	Note string `json:"note" binding:"required,max=5000"`
+}
+
+// ContactIDsInput identifies the contact messages of a bulk action
+type ContactIDsInput struct {
+	IDs []uint64 `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+}
+
+// ContactReadInput chooses whether contact messages are marked as read or unread
+type ContactReadInput struct {
+	Read *bool `json:"read"` // Defaults to true
+}
+
+// MarkContactsReadInput identifies contact messages to mark as read, or as unread when read is false
+type MarkContactsReadInput struct {
+	ContactIDsInput
+	ContactReadInput
+}
+
 // CreateContact handles the creation of a new contact message
 func (h *ContactHandler) CreateContact(c *gin.Context) {
 	var input CreateContactInput
@@ -84,14 +117,251 @@ func (h *ContactHandler) CreateContact(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Message sent successfully!"})
 }
 
-// GetAllContacts retrieves all contact messages
+// GetAllContacts lists contact messages with pagination, newest first. Supports ?q= (name,
+// email, subject or message), ?status= (a status, or all to include spam, which is hidden by
+// default), ?is_read=true|false and ?assigned_to= (a user ID, me or none).
 func (h *ContactHandler) GetAllContacts(c *gin.Context) {
-	contacts, err := h.Repo.GetAllContacts()
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := repositories.ContactFilter{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Status: c.Query("status"),
+	}
+	switch models.ContactStatus(filter.Status) {
+	case "", repositories.ContactStatusAll, models.ContactStatusNew, models.ContactStatusInProgress, models.ContactStatusResolved, models.ContactStatusSpam:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter"})
+		return
+	}
+
+	if value := c.Query("is_read"); value != "" {
+		isRead, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid is_read filter"})
+			return
+		}
+		filter.IsRead = &isRead
+	}
+
+	switch value := c.Query("assigned_to"); value {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		userID := c.GetUint64("userID")
+		filter.AssignedToID = &userID
+	default:
+		userID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assigned_to filter"})
+			return
+		}
+		filter.AssignedToID = &userID
+	}
+
+	contacts, total, err := h.Repo.GetAll(page, limit, filter)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve contacts", err)
 		return
 	}
-	c.JSON(http.StatusOK, contacts)
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        contacts,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  total,
+	})
+}
+
+// GetContactByID returns a contact message with its assignee and internal notes. Opening a
+// message does not change its read state: see MarkContactRead.
+func (h *ContactHandler) GetContactByID(c *gin.Context) {
+	contact, ok := h.getContact(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// MarkContactRead marks a contact message as read, or as unread when the body is {"read": false}
+func (h *ContactHandler) MarkContactRead(c *gin.Context) {
+	contact, ok := h.getContact(c)
+	if !ok {
+		return
+	}
+
+	var input ContactReadInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+
+	read := input.Read == nil || *input.Read
+	if read != contact.IsRead {
+		if _, err := h.Repo.MarkRead([]uint64{contact.ID}, read); err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to update contact", err)
+			return
+		}
+		contact.IsRead, contact.ReadAt = read, nil
+		if read {
+			now := time.Now()
+			contact.ReadAt = &now
+		}
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// UpdateContact changes the status, read state or assignee of a contact message
+func (h *ContactHandler) UpdateContact(c *gin.Context) {
+	contact, ok := h.getContact(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateContactInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if input.Status != nil {
+		contact.Status = *input.Status
+	}
+	if input.IsRead != nil && *input.IsRead != contact.IsRead {
+		contact.IsRead, contact.ReadAt = *input.IsRead, nil
+		if contact.IsRead {
+			now := time.Now()
+			contact.ReadAt = &now
+		}
+	}
+	if input.AssignedToID != nil {
+		if *input.AssignedToID == 0 {
+			contact.AssignedToID, contact.AssignedTo = nil, nil
+		} else {
+			assignee, err := h.UserRepo.GetUserByID(*input.AssignedToID)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					utils.RespondError(c, http.StatusBadRequest, "Assigned user not found", err)
+					return
+				}
+				utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve assigned user", err)
+				return
+			}
+			contact.AssignedToID, contact.AssignedTo = &assignee.ID, assignee
+		}
+	}
+
+	if err := h.Repo.Update(contact); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update contact", err)
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// AddContactNote adds an internal note of the current user to a contact message
+func (h *ContactHandler) AddContactNote(c *gin.Context) {
+	contact, ok := h.getContact(c)
+	if !ok {
+		return
+	}
+
+	var input ContactNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	input.Note = trimString(input.Note)
+	if input.Note == "" {
+		utils.RespondError(c, http.StatusBadRequest, "Note cannot be empty", nil)
+		return
+	}
+
+	authorID := c.GetUint64("userID")
+	note := models.ContactNote{ContactID: contact.ID, AuthorID: &authorID, Note: input.Note}
+	if err := h.Repo.AddNote(&note); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to add note", err)
+		return
+	}
+	c.JSON(http.StatusCreated, note)
+}
+
+// MarkContactsRead marks several contact messages as read or unread at once
+func (h *ContactHandler) MarkContactsRead(c *gin.Context) {
+	var input MarkContactsReadInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	read := input.Read == nil || *input.Read
+	updated, err := h.Repo.MarkRead(input.IDs, read)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update contacts", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Contacts updated successfully", "updated": updated})
+}
+
+// DeleteContact deletes a contact message
+func (h *ContactHandler) DeleteContact(c *gin.Context) {
+	contact, ok := h.getContact(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.Repo.Delete([]uint64{contact.ID}); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete contact", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted successfully"})
+}
+
+// BulkDeleteContacts deletes several contact messages at once
+func (h *ContactHandler) BulkDeleteContacts(c *gin.Context) {
+	var input ContactIDsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	deleted, err := h.Repo.Delete(input.IDs)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete contacts", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Contacts deleted successfully", "deleted": deleted})
+}
+
+// getContact loads the contact message identified by the :id parameter, responding with an error if it fails
+func (h *ContactHandler) getContact(c *gin.Context) (*models.Contact, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return nil, false
+	}
+
+	contact, err := h.Repo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Contact not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve contact", err)
+		return nil, false
+	}
+	return contact, true
 }
 
 // trimString trims whitespace from a string
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// Periods the analytics series can be grouped by
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// maxAnalyticsRange is the longest date range the analytics series can cover
+const maxAnalyticsRange = 5 * 366
+
+// AnalyticsPoint is the number of views and visitors of one period of the series
+type AnalyticsPoint struct {
+	Period         string `json:"period"` // First day of the period, YYYY-MM-DD
+	Views          int64  `json:"views"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// AnalyticsTotals is the number of views and visitors of the whole date range
+type AnalyticsTotals struct {
+	Views          int64 `json:"views"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+// AnalyticsReport is the page-view series of a date range with its most viewed pages and
+// most common referrers
+type AnalyticsReport struct {
+	From         string                       `json:"from"`
+	To           string                       `json:"to"`
+	Granularity  string                       `json:"granularity"`
+	Series       []AnalyticsPoint             `json:"series"`
+	Totals       AnalyticsTotals              `json:"totals"`
+	TopPages     []repositories.PopularPage   `json:"top_pages"`
+	TopReferrers []repositories.ReferrerCount `json:"top_referrers"`
+}
+
+// GetAnalytics returns page views and unique visitors per day, week (starting Monday) or month
+// for ?from=YYYY-MM-DD&to=YYYY-MM-DD (default: the last 30 days) and ?granularity=day|week|month.
+// Past days come from the daily rollups and today from the raw page views. Visitors are counted
+// once per day, so a visitor coming back on another day of a week or month counts again.
+func (h *DashboardHandler) GetAnalytics(c *gin.Context) {
+	now := time.Now()
+	today := utils.StartOfDay(now)
+
+	from, to, ok := analyticsDateRange(c, today)
+	if !ok {
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", GranularityDay)
+	if granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid granularity, expected day, week or month"})
+		return
+	}
+
+	days, err := h.pageViewRepo.GetDailyStats(from, to)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve analytics", err)
+		return
+	}
+	// Today is not rolled up yet
+	if !today.Before(from) && !today.After(to) {
+		views, err := h.pageViewRepo.CountViews(today, now)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve analytics", err)
+			return
+		}
+		visitors, err := h.pageViewRepo.CountUniqueVisitors(today, now)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve analytics", err)
+			return
+		}
+		days = append(days, repositories.DailyStat{Day: today.Format("2006-01-02"), Views: views, UniqueVisitors: visitors})
+	}
+
+	topPages, err := h.pageViewRepo.GetTopPages(from, to, 10)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve top pages", err)
+		return
+	}
+	topReferrers, err := h.pageViewRepo.GetTopReferrers(from, to, 10)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve top referrers", err)
+		return
+	}
+
+	report := AnalyticsReport{
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Granularity:  granularity,
+		Series:       analyticsSeries(days, from, to, granularity),
+		TopPages:     topPages,
+		TopReferrers: topReferrers,
+	}
+	for _, point := range report.Series {
+		report.Totals.Views += point.Views
+		report.Totals.UniqueVisitors += point.UniqueVisitors
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// AnalyticsSources are where visits came from in a date range
+type AnalyticsSources struct {
+	From      string                        `json:"from"`
+	To        string                        `json:"to"`
+	Sources   []repositories.BreakdownCount `json:"sources"`
+	Referrers []repositories.ReferrerCount  `json:"referrers"`
+}
+
+// AnalyticsDevices are the devices visitors used in a date range
+type AnalyticsDevices struct {
+	From             string                        `json:"from"`
+	To               string                        `json:"to"`
+	Devices          []repositories.BreakdownCount `json:"devices"`
+	Browsers         []repositories.BreakdownCount `json:"browsers"`
+	OperatingSystems []repositories.BreakdownCount `json:"operating_systems"`
+}
+
+// AnalyticsCampaigns are the tagged links (utm_* parameters) visits came from in a date range
+type AnalyticsCampaigns struct {
+	From      string                        `json:"from"`
+	To        string                        `json:"to"`
+	Campaigns []repositories.BreakdownCount `json:"campaigns"`
+	Sources   []repositories.BreakdownCount `json:"sources"`
+	Mediums   []repositories.BreakdownCount `json:"mediums"`
+}
+
+// GetAnalyticsSources returns the traffic sources (direct, search, social, whatsapp, campaign,
+// referral) and the top referring domains of a date range. Navigation inside the website is
+// left out. Like the other breakdowns, it covers the days already rolled up (up to yesterday).
+func (h *DashboardHandler) GetAnalyticsSources(c *gin.Context) {
+	from, to, limit, ok := analyticsBreakdownParams(c)
+	if !ok {
+		return
+	}
+
+	sources, err := h.pageViewRepo.GetBreakdown(repositories.BreakdownSource, from, to, limit+1)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve traffic sources", err)
+		return
+	}
+	external := make([]repositories.BreakdownCount, 0, len(sources))
+	for _, source := range sources {
+		if source.Value != utils.SourceInternal && len(external) < limit {
+			external = append(external, source)
+		}
+	}
+
+	referrers, err := h.pageViewRepo.GetTopReferrers(from, to, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve top referrers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AnalyticsSources{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Sources:   external,
+		Referrers: referrers,
+	})
+}
+
+// GetAnalyticsDevices returns the device classes, browsers and operating systems of a date range
+func (h *DashboardHandler) GetAnalyticsDevices(c *gin.Context) {
+	from, to, limit, ok := analyticsBreakdownParams(c)
+	if !ok {
+		return
+	}
+
+	breakdowns, err := h.getBreakdowns(from, to, limit, repositories.BreakdownDevice, repositories.BreakdownBrowser, repositories.BreakdownOS)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve devices", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AnalyticsDevices{
+		From:             from.Format("2006-01-02"),
+		To:               to.Format("2006-01-02"),
+		Devices:          breakdowns[0],
+		Browsers:         breakdowns[1],
+		OperatingSystems: breakdowns[2],
+	})
+}
+
+// GetAnalyticsCampaigns returns the utm_campaign, utm_source and utm_medium values of a date range
+func (h *DashboardHandler) GetAnalyticsCampaigns(c *gin.Context) {
+	from, to, limit, ok := analyticsBreakdownParams(c)
+	if !ok {
+		return
+	}
+
+	breakdowns, err := h.getBreakdowns(from, to, limit, repositories.BreakdownUTMCampaign, repositories.BreakdownUTMSource, repositories.BreakdownUTMMedium)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve campaigns", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AnalyticsCampaigns{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Campaigns: breakdowns[0],
+		Sources:   breakdowns[1],
+		Mediums:   breakdowns[2],
+	})
+}
+
+// getBreakdowns returns the most common values of each of the given breakdowns, in order
+func (h *DashboardHandler) getBreakdowns(from, to time.Time, limit int, dimensions ...string) ([][]repositories.BreakdownCount, error) {
+	breakdowns := make([][]repositories.BreakdownCount, len(dimensions))
+	for i, dimension := range dimensions {
+		counts, err := h.pageViewRepo.GetBreakdown(dimension, from, to, limit)
+		if err != nil {
+			return nil, err
+		}
+		breakdowns[i] = counts
+	}
+	return breakdowns, nil
+}
+
+// analyticsBreakdownParams reads the date range and the ?limit= (default 10, at most 50) of a
+// breakdown, responding with an error if they are invalid
+func analyticsBreakdownParams(c *gin.Context) (time.Time, time.Time, int, bool) {
+	from, to, ok := analyticsDateRange(c, utils.StartOfDay(time.Now()))
+	if !ok {
+		return time.Time{}, time.Time{}, 0, false
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 10
+	}
+	return from, to, limit, true
+}
+
+// analyticsDateRange reads ?from=YYYY-MM-DD&to=YYYY-MM-DD, by default the 30 days up to today,
+// responding with an error if they are invalid
+func analyticsDateRange(c *gin.Context, today time.Time) (time.Time, time.Time, bool) {
+	to := today
+	if value := c.Query("to"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid 'to' date, expected YYYY-MM-DD", err)
+			return time.Time{}, time.Time{}, false
+		}
+		to = day
+	}
+	from := to.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid 'from' date, expected YYYY-MM-DD", err)
+			return time.Time{}, time.Time{}, false
+		}
+		from = day
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must not be after 'to'"})
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Sub(from) > maxAnalyticsRange*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The date range must not exceed 5 years"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// analyticsSeries groups daily stats into periods, including the periods without views so the
+// series has no gaps
+func analyticsSeries(days []repositories.DailyStat, from, to time.Time, granularity string) []AnalyticsPoint {
+	series := []AnalyticsPoint{}
+	index := make(map[string]int)
+	for period := periodStart(from, granularity); !period.After(to); period = nextPeriod(period, granularity) {
+		key := period.Format("2006-01-02")
+		index[key] = len(series)
+		series = append(series, AnalyticsPoint{Period: key})
+	}
+
+	for _, stat := range days {
+		day, err := time.ParseInLocation("2006-01-02", stat.Day, time.Local)
+		if err != nil {
+			continue
+		}
+		if i, ok := index[periodStart(day, granularity).Format("2006-01-02")]; ok {
+			series[i].Views += stat.Views
+			series[i].UniqueVisitors += stat.UniqueVisitors
+		}
+	}
+	return series
+}
+
+// periodStart returns the first day of the day, week (Monday) or month containing day
+func periodStart(day time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// nextPeriod returns the first day of the period after the one starting at start
+func nextPeriod(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
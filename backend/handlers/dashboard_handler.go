@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
 )
 
 type DashboardHandler struct {
@@ -35,8 +37,13 @@ type ContentStats struct {
 	TotalServices  int64 `json:"total_services"`
 	TotalContacts  int64 `json:"total_contacts"`
 	UnreadContacts int64 `json:"unread_contacts"`
+	OpenContacts   int64 `json:"open_contacts"` // New or in progress
+	SpamContacts   int64 `json:"spam_contacts"`
 }
 
+// AnalyticsStats summarizes visits of today and the last 7 and 30 days. Visitors are counted
+// once per day, so week and month visitors are sums of daily unique visitors and count a
+// visitor again on each day they return.
 type AnalyticsStats struct {
 	TodayViews      int64 `json:"today_views"`
 	TodayVisitors   int64 `json:"today_visitors"`
@@ -125,6 +132,10 @@ func (h *DashboardHandler) getContentStats() ContentStats {
 	stats.TotalServices, _ = h.serviceRepo.Count()
 	stats.TotalContacts, _ = h.contactRepo.Count()
 	stats.UnreadContacts, _ = h.contactRepo.CountUnread()
+	if byStatus, err := h.contactRepo.CountByStatus(); err == nil {
+		stats.OpenContacts = byStatus[models.ContactStatusNew] + byStatus[models.ContactStatusInProgress]
+		stats.SpamContacts = byStatus[models.ContactStatusSpam]
+	}
 
 	return stats
 }
@@ -161,7 +172,7 @@ func (h *DashboardHandler) getRecentContacts(limit int) []RecentContact {
 			ID:        c.ID,
 			Name:      c.Name,
 			Subject:   c.Subject,
-			IsRead:    false, // TODO: Update when is_read field is added
+			IsRead:    c.IsRead,
 			CreatedAt: c.CreatedAt,
 		}
 	}
@@ -173,27 +184,41 @@ func (h *DashboardHandler) getAnalyticsStats() AnalyticsStats {
 	stats := AnalyticsStats{}
 	now := time.Now()
 
-	// Today
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Today, from the raw page views
+	todayStart := utils.StartOfDay(now)
 	stats.TodayViews, _ = h.pageViewRepo.CountViews(todayStart, now)
 	stats.TodayVisitors, _ = h.pageViewRepo.CountUniqueVisitors(todayStart, now)
 
-	// This week (last 7 days)
-	weekStart := now.AddDate(0, 0, -7)
-	stats.WeekViews, _ = h.pageViewRepo.CountViews(weekStart, now)
-	stats.WeekVisitors, _ = h.pageViewRepo.CountUniqueVisitors(weekStart, now)
+	// Last 7 and 30 days: today plus the daily rollups of the days before
+	stats.WeekViews, stats.WeekVisitors = h.sumDailyStats(todayStart.AddDate(0, 0, -6), todayStart.AddDate(0, 0, -1))
+	stats.WeekViews += stats.TodayViews
+	stats.WeekVisitors += stats.TodayVisitors
 
-	// This month (last 30 days)
-	monthStart := now.AddDate(0, 0, -30)
-	stats.MonthViews, _ = h.pageViewRepo.CountViews(monthStart, now)
-	stats.MonthVisitors, _ = h.pageViewRepo.CountUniqueVisitors(monthStart, now)
+	stats.MonthViews, stats.MonthVisitors = h.sumDailyStats(todayStart.AddDate(0, 0, -29), todayStart.AddDate(0, 0, -1))
+	stats.MonthViews += stats.TodayViews
+	stats.MonthVisitors += stats.TodayVisitors
 
 	return stats
 }
 
+// sumDailyStats adds up the rolled up views and visitors of a range of days (visitors are
+// counted once per day)
+func (h *DashboardHandler) sumDailyStats(from, to time.Time) (int64, int64) {
+	days, err := h.pageViewRepo.GetDailyStats(from, to)
+	if err != nil {
+		return 0, 0
+	}
+	var views, visitors int64
+	for _, day := range days {
+		views += day.Views
+		visitors += day.UniqueVisitors
+	}
+	return views, visitors
+}
+
 func (h *DashboardHandler) getPopularPages(limit int, days int) []repositories.PopularPage {
-	since := time.Now().AddDate(0, 0, -days)
-	pages, err := h.pageViewRepo.GetPopularPages(limit, since)
+	today := utils.StartOfDay(time.Now())
+	pages, err := h.pageViewRepo.GetTopPages(today.AddDate(0, 0, -days), today.AddDate(0, 0, -1), limit)
 	if err != nil {
 		return []repositories.PopularPage{}
 	}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	feedItemLimit     = 20
+	feedExcerptLength = 300
+)
+
+// --- RSS 2.0 ---
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Description string        `xml:"description"`
+	Author      string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// --- Atom ---
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+// FeedHandler serves the RSS and Atom feeds of published news
+type FeedHandler struct {
+	NewsRepository     repositories.NewsRepository
+	CategoryRepository repositories.CategoryRepository
+	SettingsRepository repositories.SiteSettingsRepository
+	MediaRepository    repositories.MediaRepository
+	Storage            storage.Storage
+}
+
+// NewFeedHandler creates a new FeedHandler
+func NewFeedHandler(newsRepo repositories.NewsRepository, categoryRepo repositories.CategoryRepository, settingsRepo repositories.SiteSettingsRepository, mediaRepo repositories.MediaRepository, store storage.Storage) *FeedHandler {
+	return &FeedHandler{NewsRepository: newsRepo, CategoryRepository: categoryRepo, SettingsRepository: settingsRepo, MediaRepository: mediaRepo, Storage: store}
+}
+
+// feedData is what both feed formats are built from
+type feedData struct {
+	title        string
+	description  string
+	selfPath     string
+	news         []models.News
+	media        map[string]models.Media // Featured images of the posts, by storage key
+	lastModified time.Time
+}
+
+// GetRSS serves the latest published news as RSS 2.0 (?category=<slug> for a single category)
+func (h *FeedHandler) GetRSS(c *gin.Context) {
+	data, ok := h.loadFeed(c, "feed.rss")
+	if !ok {
+		return
+	}
+
+	channel := rssChannel{
+		Title:       data.title,
+		Link:        config.AppBaseURL(),
+		Description: data.description,
+		Language:    "id",
+		AtomLink:    atomLink{Href: data.selfPath, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(data.news)),
+	}
+	if !data.lastModified.IsZero() {
+		channel.LastBuildDate = data.lastModified.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, news := range data.news {
+		link := config.NewsURL(news.Slug)
+		item := rssItem{
+			Title:       news.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Description: utils.Excerpt(news.Content, feedExcerptLength),
+			Author:      news.Author.FullName,
+			Categories:  feedCategories(&news),
+		}
+		if news.PublishedAt != nil {
+			item.PubDate = news.PublishedAt.UTC().Format(time.RFC1123Z)
+		}
+		if url, mimeType, length, ok := h.feedEnclosure(&news, data.media); ok {
+			item.Enclosure = &rssEnclosure{URL: url, Length: length, Type: mimeType}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+	writeFeed(c, "application/rss+xml; charset=utf-8", feed)
+}
+
+// GetAtom serves the latest published news as an Atom feed (?category=<slug> for a single category)
+func (h *FeedHandler) GetAtom(c *gin.Context) {
+	data, ok := h.loadFeed(c, "feed.atom")
+	if !ok {
+		return
+	}
+
+	updated := data.lastModified
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	feed := atomFeed{
+		ID:       data.selfPath,
+		Title:    data.title,
+		Subtitle: data.description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: data.selfPath, Rel: "self", Type: "application/atom+xml"},
+			{Href: config.AppBaseURL(), Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, 0, len(data.news)),
+	}
+
+	for _, news := range data.news {
+		link := config.NewsURL(news.Slug)
+		entry := atomEntry{
+			ID:      link,
+			Title:   news.Title,
+			Updated: news.UpdatedAt.UTC().Format(time.RFC3339),
+			Links:   []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Summary: utils.Excerpt(news.Content, feedExcerptLength),
+			Author:  atomAuthor{Name: news.Author.FullName},
+		}
+		if news.PublishedAt != nil {
+			entry.Published = news.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		for _, term := range feedCategories(&news) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: term})
+		}
+		if url, mimeType, length, ok := h.feedEnclosure(&news, data.media); ok {
+			entry.Links = append(entry.Links, atomLink{Href: url, Rel: "enclosure", Type: mimeType, Length: length})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	writeFeed(c, "application/atom+xml; charset=utf-8", feed)
+}
+
+// loadFeed fetches the posts for a feed and answers conditional requests. It returns false
+// when a response has already been written (an error or 304 Not Modified).
+func (h *FeedHandler) loadFeed(c *gin.Context, name string) (*feedData, bool) {
+	siteName := settingValue(h.SettingsRepository, "site_name", "Website Desa")
+	data := &feedData{
+		title:       siteName,
+		description: settingValue(h.SettingsRepository, "site_description", "Berita terbaru dari "+siteName),
+		selfPath:    config.AbsoluteURL("/api/v1/" + name),
+	}
+
+	filter := repositories.NewsFilter{CategorySlug: c.Query("category")}
+	if filter.CategorySlug != "" {
+		category, err := h.CategoryRepository.GetBySlug(filter.CategorySlug)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.RespondError(c, http.StatusNotFound, "Category not found", err)
+				return nil, false
+			}
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve category", err)
+			return nil, false
+		}
+		data.title = fmt.Sprintf("%s - %s", siteName, category.Name)
+		if category.Description != nil && *category.Description != "" {
+			data.description = *category.Description
+		}
+		data.selfPath += "?category=" + category.Slug
+	}
+
+	news, _, err := h.NewsRepository.GetAllNews(1, feedItemLimit, filter)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news", err)
+		return nil, false
+	}
+	data.news = news
+
+	// Enclosure sizes come from the media library: reading every file from the storage is too slow
+	var keys []string
+	for _, n := range news {
+		if n.FeaturedImageURL != nil {
+			if key, ok := storage.KeyFromURL(h.Storage, *n.FeaturedImageURL); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	media, err := h.MediaRepository.GetByKeys(keys)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve media", err)
+		return nil, false
+	}
+	data.media = make(map[string]models.Media, len(media))
+	for _, m := range media {
+		data.media[m.StorageKey] = m
+	}
+
+	// The feed changes when a post is added, edited or removed from the list
+	fingerprint := sha256.New()
+	fmt.Fprint(fingerprint, name, filter.CategorySlug)
+	for _, n := range news {
+		fmt.Fprint(fingerprint, n.ID, n.UpdatedAt.UnixNano())
+		if n.UpdatedAt.After(data.lastModified) {
+			data.lastModified = n.UpdatedAt
+		}
+		if n.PublishedAt != nil && n.PublishedAt.After(data.lastModified) {
+			data.lastModified = *n.PublishedAt
+		}
+	}
+	etag := `"` + hex.EncodeToString(fingerprint.Sum(nil))[:32] + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !data.lastModified.IsZero() {
+		c.Header("Last-Modified", data.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c, etag, data.lastModified) {
+		c.Status(http.StatusNotModified)
+		return nil, false
+	}
+	return data, true
+}
+
+// notModified reports whether the client's cached copy is still current. If-None-Match takes
+// precedence over If-Modified-Since, as required by RFC 9110.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == etag || candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// writeFeed encodes a feed as indented XML
+func writeFeed(c *gin.Context, contentType string, feed interface{}) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to build feed", err)
+		return
+	}
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), body...))
+}
+
+// feedCategories returns the category and tag names of a post
+func feedCategories(news *models.News) []string {
+	var names []string
+	if news.Category != nil {
+		names = append(names, news.Category.Name)
+	}
+	for _, tag := range news.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// feedEnclosure describes the featured image of a post. The size and type of uploaded images
+// are taken from the media library records loaded with the feed; the size is 0 (unknown) otherwise.
+func (h *FeedHandler) feedEnclosure(news *models.News, media map[string]models.Media) (string, string, int64, bool) {
+	if news.FeaturedImageURL == nil || *news.FeaturedImageURL == "" {
+		return "", "", 0, false
+	}
+	imageURL := *news.FeaturedImageURL
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(imageURL)))
+	if mimeType == "" {
+		mimeType = "image/jpeg"
+	}
+
+	var length int64
+	if key, ok := storage.KeyFromURL(h.Storage, imageURL); ok {
+		if file, found := media[key]; found {
+			length = file.Size
+			if file.MIMEType != "" {
+				mimeType = file.MIMEType
+			}
+		}
+	}
+	return config.AbsoluteURL(imageURL), mimeType, length, true
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// Qualities used when an uploaded JPEG or WebP is re-encoded to strip its metadata
+const (
+	originalJPEGQuality = 92
+	originalWebPQuality = 92
+)
+
+// errInvalidImage is returned when a file looks like an image but cannot be decoded
+var errInvalidImage = errors.New("invalid or unsupported image")
+
+// ImageVariantResponse describes one resized copy of an uploaded image
+type ImageVariantResponse struct {
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	URL     string `json:"url"`                // JPEG, or PNG for images with transparency
+	WebPURL string `json:"webp_url,omitempty"` // Only written when smaller than the JPEG/PNG
+}
+
+// storedFile is a file waiting to be written to the storage
+type storedFile struct {
+	Key         string
+	Data        []byte
+	ContentType string
+}
+
+// processedImage is the result of running an uploaded image through the pipeline
+type processedImage struct {
+	Original []byte // Upright and without metadata
+	Width    int
+	Height   int
+	Files    []storedFile // The variants
+	Variants map[string]ImageVariantResponse
+}
+
+// processImage turns an uploaded image upright, strips its metadata and renders the resized
+// variants of its upload type. Files that are not raster images (such as SVG logos or videos)
+// return nil.
+func (h *UploadHandler) processImage(data []byte, filename string, uploadType string) (*processedImage, error) {
+	img, format, err := utils.DecodeImage(data)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", errInvalidImage, err)
+	}
+
+	result := &processedImage{
+		Original: data,
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		Variants: make(map[string]ImageVariantResponse),
+	}
+
+	// Photos carry EXIF or XMP with the camera position: replace the original with an upright copy without it
+	switch {
+	case format == "jpeg" && utils.HasJPEGMetadata(data):
+		result.Original, err = utils.EncodeJPEG(img, originalJPEGQuality)
+	case format == "png" && utils.HasPNGMetadata(data):
+		result.Original, err = utils.EncodePNG(img)
+	case format == "webp" && utils.HasWebPMetadata(data):
+		result.Original, err = utils.EncodeWebP(img, originalWebPQuality)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opaque := utils.IsOpaque(img)
+	quality := config.ImageJPEGQuality()
+	webpQuality := config.ImageWebPQuality()
+
+	for _, variant := range config.ImageVariantsFor(uploadType) {
+		resized := utils.ResizeToFit(img, variant.MaxWidth, variant.MaxHeight)
+
+		fallback := storedFile{Key: utils.GenerateVariantFilename(filename, variant.Name, ".jpg"), ContentType: config.MIMETypeJPEG}
+		if opaque {
+			fallback.Data, err = utils.EncodeJPEG(resized, quality)
+		} else {
+			fallback = storedFile{Key: utils.GenerateVariantFilename(filename, variant.Name, ".png"), ContentType: config.MIMETypePNG}
+			fallback.Data, err = utils.EncodePNG(resized)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, fallback)
+
+		response := ImageVariantResponse{
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+			URL:    h.Storage.URL(fallback.Key),
+		}
+
+		// Transparent images are encoded lossless, which only wins for graphics and small images
+		webp, err := utils.EncodeWebP(resized, webpQuality)
+		if err != nil {
+			return nil, err
+		}
+		if len(webp) < len(fallback.Data) {
+			key := utils.GenerateVariantFilename(filename, variant.Name, ".webp")
+			result.Files = append(result.Files, storedFile{Key: key, Data: webp, ContentType: config.MIMETypeWebP})
+			response.WebPURL = h.Storage.URL(key)
+		}
+
+		result.Variants[variant.Name] = response
+	}
+	return result, nil
+}
+
+// thumbnailURL returns the URL of the smallest variant, or nil when no variant was made
+func (p *processedImage) thumbnailURL() *string {
+	var smallest *ImageVariantResponse
+	for _, variant := range p.Variants {
+		if smallest == nil || variant.Width < smallest.Width {
+			smallest = &variant
+		}
+	}
+	if smallest == nil {
+		return nil
+	}
+	return &smallest.URL
+}
+
+// variantKeys lists every key a variant of an uploaded file may be stored under
+func variantKeys(key string) []string {
+	var keys []string
+	for _, variant := range config.ImageVariants() {
+		for _, ext := range []string{".jpg", ".png", ".webp"} {
+			keys = append(keys, utils.GenerateVariantFilename(key, variant.Name, ext))
+		}
+	}
+	return keys
+}
+
+// isFileOrVariant reports whether key is a file named base (with any extension) or one of its variants
+func isFileOrVariant(key string, base string) bool {
+	return utils.VariantBase(key, config.ImageVariantNames()...) == base
+}
+
+// removeFiles deletes stored files, ignoring errors: it is used to clean up
+func removeFiles(store storage.Storage, keys ...string) {
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/jobs"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// MediaInput defines the editable details of a media file
+type MediaInput struct {
+	AltText *string `json:"alt_text" binding:"omitempty,max=255"`
+}
+
+// MediaDetail is a media file together with the content that uses it
+type MediaDetail struct {
+	models.Media
+	References []repositories.MediaReference `json:"references"`
+}
+
+// UploadGCInput defines the options of a garbage collection run requested by an admin
+type UploadGCInput struct {
+	DryRun *bool `json:"dry_run"` // Defaults to true: only report what would be done
+}
+
+// UploadRestoreInput identifies a quarantined file to restore
+type UploadRestoreInput struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// MediaHandler handles the media library: every uploaded file and where it is used
+type MediaHandler struct {
+	MediaRepository repositories.MediaRepository
+	Storage         storage.Storage
+	Collector       *jobs.UploadCollector
+}
+
+// NewMediaHandler creates a new MediaHandler
+func NewMediaHandler(mediaRepo repositories.MediaRepository, store storage.Storage, collector *jobs.UploadCollector) *MediaHandler {
+	return &MediaHandler{MediaRepository: mediaRepo, Storage: store, Collector: collector}
+}
+
+// GetAllMedia lists uploaded files with pagination. Supports ?q= (file name or alt text),
+// ?kind=image|video|document and ?upload_type=.
+func (h *MediaHandler) GetAllMedia(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "24"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 24
+	}
+
+	filter := repositories.MediaFilter{
+		Query:      strings.TrimSpace(c.Query("q")),
+		Kind:       c.Query("kind"),
+		UploadType: c.Query("upload_type"),
+	}
+
+	media, total, err := h.MediaRepository.GetAll(page, limit, filter)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve media", err)
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        media,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  total,
+	})
+}
+
+// GetMediaByID returns a media file and the content that uses it
+func (h *MediaHandler) GetMediaByID(c *gin.Context) {
+	media, ok := h.getMedia(c)
+	if !ok {
+		return
+	}
+
+	references, err := h.MediaRepository.FindReferences(mediaURLs(h.Storage, media.StorageKey), mediaKeys(media.StorageKey))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to find where the file is used", err)
+		return
+	}
+	c.JSON(http.StatusOK, MediaDetail{Media: *media, References: references})
+}
+
+// UpdateMedia updates the alt text of a media file
+func (h *MediaHandler) UpdateMedia(c *gin.Context) {
+	media, ok := h.getMedia(c)
+	if !ok {
+		return
+	}
+
+	var input MediaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	media.AltText = nil
+	if input.AltText != nil && strings.TrimSpace(*input.AltText) != "" {
+		altText := strings.TrimSpace(*input.AltText)
+		media.AltText = &altText
+	}
+
+	if err := h.MediaRepository.Update(media); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update media", err)
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteMedia deletes a file and its variants. Files still used by content cannot be deleted:
+// the response lists where they are used instead.
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	media, ok := h.getMedia(c)
+	if !ok {
+		return
+	}
+
+	references, err := h.MediaRepository.FindReferences(mediaURLs(h.Storage, media.StorageKey), mediaKeys(media.StorageKey))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to find where the file is used", err)
+		return
+	}
+	if len(references) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "The file is still in use and cannot be deleted",
+			"references": references,
+		})
+		return
+	}
+
+	// Remove the record first: a file left behind is harmless, a record without its file is not
+	if err := h.MediaRepository.Delete(media.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete media", err)
+		return
+	}
+	for _, key := range append([]string{media.StorageKey}, variantKeys(media.StorageKey)...) {
+		for _, storedKey := range []string{key, storage.QuarantinePrefix + key} {
+			if err := h.Storage.Delete(storedKey); err != nil {
+				log.Printf("Failed to delete stored file %s: %v", storedKey, err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
+}
+
+// CollectUnusedUploads looks for uploaded files that no content uses. A dry run (the default)
+// only reports them; otherwise unused files past the grace period are quarantined and files
+// quarantined long enough are deleted.
+func (h *MediaHandler) CollectUnusedUploads(c *gin.Context) {
+	var input UploadGCInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+	dryRun := input.DryRun == nil || *input.DryRun
+
+	report, err := h.Collector.Run(time.Now(), dryRun)
+	if err != nil {
+		if errors.Is(err, jobs.ErrCollectorBusy) {
+			utils.RespondError(c, http.StatusConflict, "Garbage collection is already running", err)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to collect unused uploads", err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RestoreQuarantinedUpload moves a quarantined file back so it is served again
+func (h *MediaHandler) RestoreQuarantinedUpload(c *gin.Context) {
+	var input UploadRestoreInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	key := strings.TrimPrefix(input.Key, storage.QuarantinePrefix)
+	if !storage.ValidKey(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file key"})
+		return
+	}
+
+	if err := h.Collector.Restore(key); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			utils.RespondError(c, http.StatusNotFound, "File not found in the quarantine", err)
+		case errors.Is(err, jobs.ErrRestoreConflict):
+			utils.RespondError(c, http.StatusConflict, "A file with the same name already exists", err)
+		default:
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to restore the file", err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "File restored successfully", "url": h.Storage.URL(key)})
+}
+
+// getMedia loads the media file identified by the :id parameter, responding with an error if it fails
+func (h *MediaHandler) getMedia(c *gin.Context) (*models.Media, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+		return nil, false
+	}
+
+	media, err := h.MediaRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Media not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve media", err)
+		return nil, false
+	}
+	return media, true
+}
+
+// mediaKeys lists the keys of a stored file and of every variant it may have
+func mediaKeys(key string) []string {
+	return append([]string{key}, variantKeys(key)...)
+}
+
+// mediaURLs lists the URLs content may use to point to a stored file or one of its variants:
+// the URL of the configured storage and the /uploads/ path used before it
+func mediaURLs(store storage.Storage, key string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, k := range mediaKeys(key) {
+		for _, url := range []string{store.URL(k), storage.UploadsURLPrefix + k} {
+			if !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+const metaDescriptionLength = 200
+
+// MetaTag is a single <meta> tag. Open Graph tags use the property attribute, Twitter Card
+// tags the name attribute; both are returned as Name.
+type MetaTag struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// PageMetadata is everything a page needs in its <head> to be shared and indexed well
+type PageMetadata struct {
+	Title        string                   `json:"title"`
+	Description  string                   `json:"description"`
+	CanonicalURL string                   `json:"canonical_url"`
+	Image        string                   `json:"image,omitempty"`
+	OpenGraph    []MetaTag                `json:"open_graph"`
+	Twitter      []MetaTag                `json:"twitter"`
+	JSONLD       []map[string]interface{} `json:"json_ld"` // schema.org objects, one <script type="application/ld+json"> each
+}
+
+// MetaHandler builds link preview and structured-data metadata for public pages
+type MetaHandler struct {
+	NewsRepository     repositories.NewsRepository
+	ServiceRepository  repositories.ServiceRepository
+	SettingsRepository repositories.SiteSettingsRepository
+}
+
+// NewMetaHandler creates a new MetaHandler
+func NewMetaHandler(newsRepo repositories.NewsRepository, serviceRepo repositories.ServiceRepository, settingsRepo repositories.SiteSettingsRepository) *MetaHandler {
+	return &MetaHandler{NewsRepository: newsRepo, ServiceRepository: serviceRepo, SettingsRepository: settingsRepo}
+}
+
+// siteInfo holds the site settings used as metadata and as fallbacks
+type siteInfo struct {
+	name        string
+	description string
+	logo        string
+	settings    map[string]string
+}
+
+// GetHomeMetadata returns the metadata of the home page
+func (h *MetaHandler) GetHomeMetadata(c *gin.Context) {
+	site, ok := h.loadSite(c)
+	if !ok {
+		return
+	}
+
+	homeURL := config.AppBaseURL() + "/"
+	meta := site.page(site.name, site.description, homeURL, site.logo, "website")
+	meta.JSONLD = []map[string]interface{}{
+		site.organization(),
+		{
+			"@context":    "https://schema.org",
+			"@type":       "WebSite",
+			"name":        site.name,
+			"description": site.description,
+			"url":         homeURL,
+			"inLanguage":  "id",
+			"publisher":   map[string]interface{}{"@id": site.organizationID()},
+		},
+	}
+
+	c.JSON(http.StatusOK, meta)
+}
+
+// GetNewsMetadata returns the metadata of a published news post by its slug
+func (h *MetaHandler) GetNewsMetadata(c *gin.Context) {
+	news, err := h.NewsRepository.GetVisibleNewsBySlug(c.Param("slug"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "News not found", err)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news", err)
+		return
+	}
+
+	site, ok := h.loadSite(c)
+	if !ok {
+		return
+	}
+
+	description := utils.Excerpt(news.Content, metaDescriptionLength)
+	if description == "" {
+		description = site.description
+	}
+	image := site.logo
+	if news.FeaturedImageURL != nil && *news.FeaturedImageURL != "" {
+		image = config.AbsoluteURL(*news.FeaturedImageURL)
+	}
+	link := config.NewsURL(news.Slug)
+
+	meta := site.page(news.Title, description, link, image, "article")
+	if news.PublishedAt != nil {
+		meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "article:published_time", Content: news.PublishedAt.UTC().Format(time.RFC3339)})
+	}
+	meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "article:modified_time", Content: news.UpdatedAt.UTC().Format(time.RFC3339)})
+	if news.Category != nil {
+		meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "article:section", Content: news.Category.Name})
+	}
+	for _, tag := range news.Tags {
+		meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "article:tag", Content: tag.Name})
+	}
+
+	article := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "NewsArticle",
+		"headline":         utils.Excerpt(news.Title, 110), // Google ignores longer headlines
+		"description":      description,
+		"url":              link,
+		"mainEntityOfPage": map[string]interface{}{"@type": "WebPage", "@id": link},
+		"dateModified":     news.UpdatedAt.UTC().Format(time.RFC3339),
+		"inLanguage":       "id",
+		"publisher":        site.organization(),
+	}
+	if image != "" {
+		article["image"] = []string{image}
+	}
+	if news.PublishedAt != nil {
+		article["datePublished"] = news.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	if news.Author.FullName != "" {
+		article["author"] = map[string]interface{}{"@type": "Person", "name": news.Author.FullName}
+	}
+	if news.Category != nil {
+		article["articleSection"] = news.Category.Name
+	}
+	if keywords := feedCategories(news); len(keywords) > 0 {
+		article["keywords"] = strings.Join(keywords, ", ")
+	}
+	meta.JSONLD = []map[string]interface{}{article}
+
+	c.JSON(http.StatusOK, meta)
+}
+
+// GetServiceMetadata returns the metadata of a village service
+func (h *MetaHandler) GetServiceMetadata(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service ID"})
+		return
+	}
+
+	service, err := h.ServiceRepository.GetServiceByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Service not found", err)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve service", err)
+		return
+	}
+
+	site, ok := h.loadSite(c)
+	if !ok {
+		return
+	}
+
+	description := site.description
+	if service.Description != nil {
+		if excerpt := utils.Excerpt(*service.Description, metaDescriptionLength); excerpt != "" {
+			description = excerpt
+		}
+	}
+	link := config.ServiceURL(service.ID)
+
+	meta := site.page(service.ServiceName, description, link, site.logo, "website")
+	governmentService := map[string]interface{}{
+		"@context":    "https://schema.org",
+		"@type":       "GovernmentService",
+		"name":        service.ServiceName,
+		"description": description,
+		"url":         link,
+		"provider":    site.organization(),
+	}
+	if area := site.settings["village_name"]; area != "" {
+		governmentService["areaServed"] = map[string]interface{}{"@type": "AdministrativeArea", "name": area}
+	}
+	meta.JSONLD = []map[string]interface{}{governmentService}
+
+	c.JSON(http.StatusOK, meta)
+}
+
+// loadSite reads the site settings, responding with an error if it fails
+func (h *MetaHandler) loadSite(c *gin.Context) (*siteInfo, bool) {
+	settings, err := filledInSettings(h.SettingsRepository)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve site settings", err)
+		return nil, false
+	}
+
+	site := &siteInfo{
+		name:        settings["site_name"],
+		description: settings["site_description"],
+		settings:    settings,
+	}
+	if site.name == "" {
+		site.name = "Website Desa"
+	}
+	if site.description == "" {
+		site.description = "Website resmi " + site.name
+	}
+	if logo := settings["site_logo"]; logo != "" {
+		site.logo = config.AbsoluteURL(logo)
+	}
+	return site, true
+}
+
+// page builds the common title, description, Open Graph and Twitter Card tags of a page
+func (s *siteInfo) page(title, description, link, image, ogType string) PageMetadata {
+	fullTitle := title
+	if title != s.name {
+		fullTitle = title + " - " + s.name
+	}
+
+	meta := PageMetadata{
+		Title:        fullTitle,
+		Description:  description,
+		CanonicalURL: link,
+		Image:        image,
+		OpenGraph: []MetaTag{
+			{Name: "og:site_name", Content: s.name},
+			{Name: "og:locale", Content: "id_ID"},
+			{Name: "og:type", Content: ogType},
+			{Name: "og:title", Content: title},
+			{Name: "og:description", Content: description},
+			{Name: "og:url", Content: link},
+		},
+	}
+
+	card := "summary"
+	if image != "" {
+		meta.OpenGraph = append(meta.OpenGraph, MetaTag{Name: "og:image", Content: image}, MetaTag{Name: "og:image:alt", Content: title})
+		if image != s.logo {
+			card = "summary_large_image"
+		}
+	}
+
+	meta.Twitter = []MetaTag{
+		{Name: "twitter:card", Content: card},
+		{Name: "twitter:title", Content: title},
+		{Name: "twitter:description", Content: description},
+	}
+	if image != "" {
+		meta.Twitter = append(meta.Twitter, MetaTag{Name: "twitter:image", Content: image})
+	}
+	if handle := twitterHandle(s.settings["twitter_url"]); handle != "" {
+		meta.Twitter = append(meta.Twitter, MetaTag{Name: "twitter:site", Content: handle})
+	}
+	return meta
+}
+
+// organizationID identifies the village government across the JSON-LD objects of the site
+func (s *siteInfo) organizationID() string {
+	return config.AppBaseURL() + "/#organization"
+}
+
+// organization describes the village government as a schema.org GovernmentOrganization
+func (s *siteInfo) organization() map[string]interface{} {
+	org := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "GovernmentOrganization",
+		"@id":      s.organizationID(),
+		"name":     s.name,
+		"url":      config.AppBaseURL() + "/",
+	}
+	if s.logo != "" {
+		org["logo"] = map[string]interface{}{"@type": "ImageObject", "url": s.logo}
+	}
+	if email := s.settings["contact_email"]; email != "" {
+		org["email"] = email
+	}
+	if phone := s.settings["contact_phone"]; phone != "" {
+		org["telephone"] = phone
+	}
+
+	address := map[string]interface{}{"@type": "PostalAddress", "addressCountry": "ID"}
+	for key, property := range map[string]string{
+		"village_address":     "streetAddress",
+		"village_regency":     "addressLocality",
+		"village_province":    "addressRegion",
+		"village_postal_code": "postalCode",
+	} {
+		if value := s.settings[key]; value != "" {
+			address[property] = value
+		}
+	}
+	if len(address) > 2 {
+		org["address"] = address
+	}
+
+	var sameAs []string
+	for _, key := range []string{"facebook_url", "instagram_url", "twitter_url", "youtube_url", "tiktok_url"} {
+		if value := s.settings[key]; value != "" {
+			sameAs = append(sameAs, value)
+		}
+	}
+	if len(sameAs) > 0 {
+		org["sameAs"] = sameAs
+	}
+	return org
+}
+
+// twitterHandle turns a profile URL such as https://twitter.com/desaku into "@desaku"
+func twitterHandle(profileURL string) string {
+	u, err := url.Parse(strings.TrimSpace(profileURL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	handle := strings.Trim(u.Path, "/")
+	if handle == "" || strings.Contains(handle, "/") {
+		return ""
+	}
+	return "@" + strings.TrimPrefix(handle, "@")
+}
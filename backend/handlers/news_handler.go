@@ -3,57 +3,108 @@ package handlers
 import (
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
 )
 
 // NewsHandler handles news related requests
 type NewsHandler struct {
-	NewsRepository repositories.NewsRepository
+	NewsRepository     repositories.NewsRepository
+	RoleRepository     repositories.RoleRepository
+	CategoryRepository repositories.CategoryRepository
+	TagRepository      repositories.TagRepository
 }
 
 // NewNewsHandler creates a new NewsHandler
-func NewNewsHandler(newsRepo repositories.NewsRepository) *NewsHandler {
-	return &NewsHandler{NewsRepository: newsRepo}
+func NewNewsHandler(newsRepo repositories.NewsRepository, roleRepo repositories.RoleRepository, categoryRepo repositories.CategoryRepository, tagRepo repositories.TagRepository) *NewsHandler {
+	return &NewsHandler{NewsRepository: newsRepo, RoleRepository: roleRepo, CategoryRepository: categoryRepo, TagRepository: tagRepo}
 }
 
-// generateUniqueSlug creates a URL-friendly slug and ensures it is unique in the database.
-func (h *NewsHandler) generateUniqueSlug(title string, currentID uint64) (string, error) {
-	// Create the base slug
-	baseSlug := strings.ToLower(title)
-	re := regexp.MustCompile(`[^a-z0-9]+`)
-	baseSlug = re.ReplaceAllString(baseSlug, "-")
-	baseSlug = strings.Trim(baseSlug, "-")
-
-	// Check if the base slug exists
-	slug := baseSlug
-	exists, err := h.NewsRepository.IsSlugExist(slug, currentID)
+// hasPermission checks whether the role of the authenticated user grants a permission
+func (h *NewsHandler) hasPermission(c *gin.Context, permission string) (bool, error) {
+	role, exists := c.Get("userRole")
+	if !exists {
+		return false, nil
+	}
+	return h.RoleRepository.RoleHasPermission(role.(string), permission)
+}
+
+// canManageNews responds with an error and returns false if the authenticated user may not
+// modify the given post. Users without news.manage_all can only modify their own posts.
+func (h *NewsHandler) canManageNews(c *gin.Context, news *models.News) bool {
+	manageAll, err := h.hasPermission(c, config.PermissionNewsManageAll)
 	if err != nil {
-		return "", fmt.Errorf("failed to check slug existence: %w", err)
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify permissions", err)
+		return false
 	}
+	if !manageAll && news.AuthorID != c.GetUint64("userID") {
+		utils.RespondError(c, http.StatusForbidden, "You can only manage your own posts", nil)
+		return false
+	}
+	return true
+}
 
-	// If it exists, append a random suffix until it's unique
-	for exists {
-		rand.Seed(time.Now().UnixNano())
-		suffix := strconv.Itoa(rand.Intn(1000)) // Generate a random number between 0-999
-		slug = fmt.Sprintf("%s-%s", baseSlug, suffix)
+// canChangeStatus responds with an error and returns false if the authenticated user may not
+// move a post from one status to another. Publishing, scheduling and unpublishing need news.publish.
+func (h *NewsHandler) canChangeStatus(c *gin.Context, from, to models.NewsStatus) bool {
+	if from == to || (!isPublicationStatus(from) && !isPublicationStatus(to)) {
+		return true
+	}
+	return h.canPublish(c)
+}
 
-		exists, err = h.NewsRepository.IsSlugExist(slug, currentID)
-		if err != nil {
-			return "", fmt.Errorf("failed to check slug existence during retry: %w", err)
+// canUpdateStatus checks a status change made through UpdateNews, responding with an error
+// and returning false if it is not allowed. Changing the dates of a published or scheduled
+// post counts as publishing too, and so does editing its title, content or featured image,
+// since those changes go live without review.
+func (h *NewsHandler) canUpdateStatus(c *gin.Context, from, to models.NewsStatus, scheduleChanged, contentChanged bool) bool {
+	if from != to {
+		if err := validateNewsTransition(from, to); err != nil {
+			utils.RespondError(c, http.StatusConflict, "Invalid status change: "+err.Error(), nil)
+			return false
 		}
+		// Review decisions need a reviewer and are recorded with a note
+		if to == models.NewsStatusInReview || from == models.NewsStatusInReview {
+			utils.RespondError(c, http.StatusConflict, "Use the submit, approve or reject endpoints to move a post in or out of review", nil)
+			return false
+		}
+	}
+	if scheduleChanged && (isPublicationStatus(from) || isPublicationStatus(to)) {
+		return h.canPublish(c)
+	}
+	if contentChanged && isPublicationStatus(from) && isPublicationStatus(to) {
+		return h.canPublish(c)
+	}
+	return h.canChangeStatus(c, from, to)
+}
+
+// canPublish responds with an error and returns false if the authenticated user lacks news.publish
+func (h *NewsHandler) canPublish(c *gin.Context) bool {
+	canPublish, err := h.hasPermission(c, config.PermissionNewsPublish)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify permissions", err)
+		return false
+	}
+	if !canPublish {
+		utils.RespondError(c, http.StatusForbidden, "You do not have permission to publish or unpublish posts", nil)
+		return false
 	}
+	return true
+}
 
-	return slug, nil
+// generateUniqueSlug creates a URL-friendly slug and ensures it is unique in the database.
+func (h *NewsHandler) generateUniqueSlug(title string, currentID uint64) (string, error) {
+	return generateUniqueSlug(title, "berita", func(slug string) (bool, error) {
+		return h.NewsRepository.IsSlugExist(slug, currentID)
+	})
 }
 
 // CreateNewsInput defines the expected input for creating a news post
@@ -62,7 +113,10 @@ type CreateNewsInput struct {
 	Content          string            `json:"content" binding:"required,min=20"`
 	Status           models.NewsStatus `json:"status"`
 	FeaturedImageURL string            `json:"featured_image_url"`
-	PublishedAt      *time.Time        `json:"published_at"`
+	PublishedAt      *time.Time        `json:"published_at"` // A future date schedules the post
+	UnpublishAt      *time.Time        `json:"unpublish_at"` // The post is archived at this date
+	CategoryID       *uint64           `json:"category_id"`
+	TagIDs           []uint64          `json:"tag_ids"`
 }
 
 // UpdateNewsInput defines the expected input for updating a news post
@@ -72,6 +126,11 @@ type UpdateNewsInput struct {
 	Status           models.NewsStatus `json:"status"`
 	FeaturedImageURL string            `json:"featured_image_url"`
 	PublishedAt      *time.Time        `json:"published_at"`
+	UnpublishAt      *time.Time        `json:"unpublish_at"`
+	ClearUnpublishAt bool              `json:"clear_unpublish_at"` // Removes a planned unpublish date
+	CategoryID       *uint64           `json:"category_id"`
+	ClearCategory    bool              `json:"clear_category"` // Removes the post from its category
+	TagIDs           *[]uint64         `json:"tag_ids"`        // Replaces all tags when present, [] removes them
 }
 
 // GetAllNewsForAdmin retrieves all news posts with pagination for the admin panel
@@ -89,7 +148,18 @@ func (h *NewsHandler) GetAllNewsForAdmin(c *gin.Context) {
 		limit = 10
 	}
 
-	news, total, err := h.NewsRepository.GetAllNewsForAdmin(page, limit)
+	// Users who cannot manage every post only see their own
+	manageAll, err := h.hasPermission(c, config.PermissionNewsManageAll)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify permissions", err)
+		return
+	}
+	var authorID uint64
+	if !manageAll {
+		authorID = c.GetUint64("userID")
+	}
+
+	news, total, err := h.NewsRepository.GetAllNewsForAdmin(page, limit, authorID)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news", err)
 		return
@@ -97,8 +167,13 @@ func (h *NewsHandler) GetAllNewsForAdmin(c *gin.Context) {
 
 	totalPages := (total + int64(limit) - 1) / int64(limit)
 
+	publicNews := make([]PublicNews, len(news))
+	for i, n := range news {
+		publicNews[i] = toPublicNews(n)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":         news,
+		"data":         publicNews,
 		"currentPage":  page,
 		"totalPages":   totalPages,
 		"totalItems":   total,
@@ -110,6 +185,24 @@ func (h *NewsHandler) GetAllNewsForAdmin(c *gin.Context) {
 	}
 }
 
+// PublicAuthor holds the details of a news author that the public may see
+type PublicAuthor struct {
+	ID       uint64 `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+// PublicNews is a news post as shown on public routes. Its author replaces the full user
+// account of models.News, which holds the email address and login state.
+type PublicNews struct {
+	models.News
+	Author PublicAuthor `json:"author"`
+}
+
+// toPublicNews hides the account details of the author of a post
+func toPublicNews(news models.News) PublicNews {
+	return PublicNews{News: news, Author: PublicAuthor{ID: news.Author.ID, FullName: news.Author.FullName}}
+}
+
 // GetPublishedNews retrieves all news posts with pagination
 func (h *NewsHandler) GetPublishedNews(c *gin.Context) {
 	pageStr := c.DefaultQuery("page", "1")
@@ -125,7 +218,12 @@ func (h *NewsHandler) GetPublishedNews(c *gin.Context) {
 		limit = 10
 	}
 
-	news, total, err := h.NewsRepository.GetAllNews(page, limit)
+	filter := repositories.NewsFilter{
+		CategorySlug: c.Query("category"),
+		TagSlug:      c.Query("tag"),
+	}
+
+	news, total, err := h.NewsRepository.GetAllNews(page, limit, filter)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news", err)
 		return
@@ -133,8 +231,13 @@ func (h *NewsHandler) GetPublishedNews(c *gin.Context) {
 
 	totalPages := (total + int64(limit) - 1) / int64(limit)
 
+	publicNews := make([]PublicNews, len(news))
+	for i, n := range news {
+		publicNews[i] = toPublicNews(n)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":         news,
+		"data":         publicNews,
 		"currentPage":  page,
 		"totalPages":   totalPages,
 		"totalItems":   total,
@@ -146,7 +249,7 @@ func (h *NewsHandler) GetPublishedNews(c *gin.Context) {
 	}
 }
 
-// GetNewsByID retrieves a single news post by ID
+// GetNewsByID retrieves a single published news post by ID
 func (h *NewsHandler) GetNewsByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -155,24 +258,33 @@ func (h *NewsHandler) GetNewsByID(c *gin.Context) {
 		return
 	}
 
-	news, err := h.NewsRepository.GetNewsByID(id)
+	news, err := h.NewsRepository.GetVisibleNewsByID(id)
 	if err != nil {
 		utils.RespondError(c, http.StatusNotFound, "News not found", err)
 		return
 	}
+	c.JSON(http.StatusOK, toPublicNews(*news))
+}
+
+// GetNewsForAdmin retrieves a single news post by ID whatever its status, for editing (Admin protected)
+func (h *NewsHandler) GetNewsForAdmin(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
 	c.JSON(http.StatusOK, news)
 }
 
-// GetNewsBySlug retrieves a single news post by slug
+// GetNewsBySlug retrieves a single published news post by slug
 func (h *NewsHandler) GetNewsBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
-	news, err := h.NewsRepository.GetNewsBySlug(slug)
+	news, err := h.NewsRepository.GetVisibleNewsBySlug(slug)
 	if err != nil {
 		utils.RespondError(c, http.StatusNotFound, "News not found", err)
 		return
 	}
-	c.JSON(http.StatusOK, news)
+	c.JSON(http.StatusOK, toPublicNews(*news))
 }
 
 // CreateNews creates a new news post (Admin protected)
@@ -205,22 +317,40 @@ func (h *NewsHandler) CreateNews(c *gin.Context) {
 		AuthorID:         authorID.(uint64),
 		FeaturedImageURL: &input.FeaturedImageURL,
 		PublishedAt:      input.PublishedAt,
+		UnpublishAt:      input.UnpublishAt,
+	}
+
+	if !h.applyTaxonomy(c, &news, input.CategoryID, &input.TagIDs) {
+		return
 	}
 
 	if news.Status == "" {
 		news.Status = models.NewsStatusDraft // Default status
 	}
+	if !isValidNewsStatus(news.Status) {
+		utils.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Unknown status '%s'", news.Status), nil)
+		return
+	}
+	// New posts are drafts or go live right away: the review queue is entered through the submit endpoint
+	if news.Status != models.NewsStatusDraft && !isPublicationStatus(news.Status) {
+		utils.RespondError(c, http.StatusBadRequest, fmt.Sprintf("A new post cannot have status '%s': create it as a draft, then submit it for review", news.Status), nil)
+		return
+	}
 
-	// If status is published but no published_at date, set it to now
-	if news.Status == models.NewsStatusPublished && news.PublishedAt == nil {
-		now := time.Now()
-		news.PublishedAt = &now
+	// Published posts with a future date become scheduled
+	if err := applySchedule(&news, time.Now()); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if !h.canChangeStatus(c, models.NewsStatusDraft, news.Status) {
+		return
 	}
 
 	if err := h.NewsRepository.CreateNews(&news); err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to create news", err)
 		return
 	}
+	h.recordStatusChange(c, news.ID, newsActionCreate, "", news.Status)
 	c.JSON(http.StatusCreated, news)
 }
 
@@ -246,6 +376,14 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 		return
 	}
 
+	if !h.canManageNews(c, existingNews) {
+		return
+	}
+	previousStatus := existingNews.Status
+	contentChanged := (input.Title != "" && input.Title != existingNews.Title) ||
+		(input.Content != "" && input.Content != existingNews.Content) ||
+		(input.FeaturedImageURL != "" && (existingNews.FeaturedImageURL == nil || input.FeaturedImageURL != *existingNews.FeaturedImageURL))
+
 	// Update fields if they are provided in the input
 	log.Printf("DEBUG UpdateNews: input.Title='%s', existingNews.Title='%s'", input.Title, existingNews.Title)
 	if input.Title != "" && input.Title != existingNews.Title {
@@ -283,18 +421,39 @@ func (h *NewsHandler) UpdateNews(c *gin.Context) {
 	if input.PublishedAt != nil {
 		existingNews.PublishedAt = input.PublishedAt
 	}
-	// If status changes to published and no published date exists, set it
-	if existingNews.Status == models.NewsStatusPublished && existingNews.PublishedAt == nil {
-		now := time.Now()
-		existingNews.PublishedAt = &now
+	if input.ClearCategory {
+		existingNews.CategoryID = nil
+		existingNews.Category = nil
+	}
+	if !h.applyTaxonomy(c, existingNews, input.CategoryID, input.TagIDs) {
+		return
+	}
+	if input.ClearUnpublishAt {
+		existingNews.UnpublishAt = nil
+	} else if input.UnpublishAt != nil {
+		existingNews.UnpublishAt = input.UnpublishAt
+	}
+
+	// Published posts with a future date become scheduled and vice versa
+	if err := applySchedule(existingNews, time.Now()); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	scheduleChanged := input.PublishedAt != nil || input.UnpublishAt != nil || input.ClearUnpublishAt
+	if !h.canUpdateStatus(c, previousStatus, existingNews.Status, scheduleChanged, contentChanged) {
+		return
 	}
 
-	if err := h.NewsRepository.UpdateNews(existingNews); err != nil {
+	if err := h.NewsRepository.UpdateNews(existingNews, c.GetUint64("userID"), nil); err != nil {
 		log.Printf("ERROR UpdateNews: Failed to update news in repository: %v", err)
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to update news", err)
 		return
 	}
 	log.Printf("DEBUG UpdateNews: News updated successfully in repository.")
+	if existingNews.Status != previousStatus {
+		h.recordStatusChange(c, existingNews.ID, newsActionUpdate, previousStatus, existingNews.Status)
+	}
 	c.JSON(http.StatusOK, existingNews)
 }
 
@@ -308,12 +467,16 @@ func (h *NewsHandler) DeleteNews(c *gin.Context) {
 	}
 
 	// First, check if the news exists
-	_, err = h.NewsRepository.GetNewsByID(id)
+	existingNews, err := h.NewsRepository.GetNewsByID(id)
 	if err != nil {
 		utils.RespondError(c, http.StatusNotFound, "News not found", err)
 		return
 	}
 
+	if !h.canManageNews(c, existingNews) {
+		return
+	}
+
 	if err := h.NewsRepository.DeleteNews(id); err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete news", err)
 		return
@@ -321,3 +484,41 @@ func (h *NewsHandler) DeleteNews(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "News deleted successfully"})
 }
+
+// applyTaxonomy sets the category and tags of a post from the given IDs, responding with an
+// error and returning false if one of them does not exist. Nil arguments leave the post unchanged.
+func (h *NewsHandler) applyTaxonomy(c *gin.Context, news *models.News, categoryID *uint64, tagIDs *[]uint64) bool {
+	if categoryID != nil {
+		category, err := h.CategoryRepository.GetByID(*categoryID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.RespondError(c, http.StatusBadRequest, "Category not found", err)
+				return false
+			}
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve category", err)
+			return false
+		}
+		news.CategoryID = &category.ID
+		news.Category = category
+	}
+
+	if tagIDs != nil {
+		tags, err := h.TagRepository.GetByIDs(*tagIDs)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve tags", err)
+			return false
+		}
+		found := make(map[uint64]bool, len(tags))
+		for _, tag := range tags {
+			found[tag.ID] = true
+		}
+		for _, id := range *tagIDs {
+			if !found[id] {
+				utils.RespondError(c, http.StatusBadRequest, fmt.Sprintf("Tag %d not found", id), nil)
+				return false
+			}
+		}
+		news.Tags = tags
+	}
+	return true
+}
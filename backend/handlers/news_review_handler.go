@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the news status history
+const (
+	newsActionCreate  = "create"
+	newsActionUpdate  = "update"
+	newsActionSubmit  = "submit"
+	newsActionApprove = "approve"
+	newsActionReject  = "reject"
+)
+
+// newsTransitions lists the status changes a post may go through
+var newsTransitions = map[models.NewsStatus][]models.NewsStatus{
+	models.NewsStatusDraft:     {models.NewsStatusInReview, models.NewsStatusScheduled, models.NewsStatusPublished, models.NewsStatusArchived},
+	models.NewsStatusInReview:  {models.NewsStatusScheduled, models.NewsStatusPublished, models.NewsStatusDraft},
+	models.NewsStatusScheduled: {models.NewsStatusPublished, models.NewsStatusDraft, models.NewsStatusArchived},
+	models.NewsStatusPublished: {models.NewsStatusScheduled, models.NewsStatusDraft, models.NewsStatusArchived},
+	models.NewsStatusArchived:  {models.NewsStatusDraft, models.NewsStatusScheduled, models.NewsStatusPublished},
+}
+
+// ReviewNoteInput defines the optional note sent with a review action
+type ReviewNoteInput struct {
+	Note string `json:"note"`
+}
+
+// isValidNewsStatus reports whether a status is one of the known news statuses
+func isValidNewsStatus(status models.NewsStatus) bool {
+	_, ok := newsTransitions[status]
+	return ok
+}
+
+// isPublicationStatus reports whether a post with this status is, or will become, publicly visible
+func isPublicationStatus(status models.NewsStatus) bool {
+	return status == models.NewsStatusPublished || status == models.NewsStatusScheduled
+}
+
+// applySchedule sets the status of a published or scheduled post from its dates: posts with a
+// future publication date are scheduled, the others are published right away.
+func applySchedule(news *models.News, now time.Time) error {
+	if !isPublicationStatus(news.Status) {
+		return nil
+	}
+
+	if news.PublishedAt == nil {
+		if news.Status == models.NewsStatusScheduled {
+			return errors.New("published_at is required to schedule a post")
+		}
+		news.PublishedAt = &now
+	}
+	if news.PublishedAt.After(now) {
+		news.Status = models.NewsStatusScheduled
+	} else {
+		news.Status = models.NewsStatusPublished
+	}
+
+	if news.UnpublishAt != nil {
+		if !news.UnpublishAt.After(*news.PublishedAt) {
+			return errors.New("unpublish_at must be after published_at")
+		}
+		if !news.UnpublishAt.After(now) {
+			return errors.New("unpublish_at must be in the future")
+		}
+	}
+	return nil
+}
+
+// validateNewsTransition returns an error describing why a post cannot move from one status to another
+func validateNewsTransition(from, to models.NewsStatus) error {
+	if !isValidNewsStatus(to) {
+		return fmt.Errorf("unknown status '%s'", to)
+	}
+	for _, allowed := range newsTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("a post with status '%s' cannot be moved to '%s'", from, to)
+}
+
+// SubmitNewsForReview moves a draft post into the review queue
+func (h *NewsHandler) SubmitNewsForReview(c *gin.Context) {
+	h.transitionNews(c, newsActionSubmit, models.NewsStatusDraft, models.NewsStatusInReview, false, true)
+}
+
+// ApproveNews publishes a post that is in review (requires news.publish)
+func (h *NewsHandler) ApproveNews(c *gin.Context) {
+	h.transitionNews(c, newsActionApprove, models.NewsStatusInReview, models.NewsStatusPublished, false, false)
+}
+
+// RejectNews sends a post in review back to draft with a note for the author (requires news.publish)
+func (h *NewsHandler) RejectNews(c *gin.Context) {
+	h.transitionNews(c, newsActionReject, models.NewsStatusInReview, models.NewsStatusDraft, true, false)
+}
+
+// GetReviewQueue lists the posts waiting for review, oldest first (requires news.publish)
+func (h *NewsHandler) GetReviewQueue(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	news, total, err := h.NewsRepository.GetNewsByStatus(models.NewsStatusInReview, page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve review queue", err)
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        news,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  total,
+	})
+}
+
+// GetNewsHistory returns who changed the status of a post, and when
+func (h *NewsHandler) GetNewsHistory(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.NewsRepository.GetStatusHistory(news.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news history", err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// transitionNews moves a post from one review status to another and records it in the history.
+// Reviewers act on posts of any author, ownOnly limits the action to posts the user may manage.
+func (h *NewsHandler) transitionNews(c *gin.Context, action string, from, to models.NewsStatus, noteRequired bool, ownOnly bool) {
+	var input ReviewNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	note := strings.TrimSpace(input.Note)
+	if noteRequired && note == "" {
+		utils.RespondError(c, http.StatusBadRequest, "A note explaining the decision is required", nil)
+		return
+	}
+
+	news, ok := h.getNews(c)
+	if !ok || (ownOnly && !h.canManageNews(c, news)) {
+		return
+	}
+	if news.Status != from {
+		utils.RespondError(c, http.StatusConflict, fmt.Sprintf("Cannot %s a post with status '%s', it must be '%s'", action, news.Status, from), nil)
+		return
+	}
+
+	news.Status = to
+	// An approved post with a future publication date is scheduled instead
+	if err := applySchedule(news, time.Now()); err != nil {
+		utils.RespondError(c, http.StatusConflict, "Cannot "+action+" the post: "+err.Error(), nil)
+		return
+	}
+
+	changedBy := c.GetUint64("userID")
+	history := models.NewsStatusHistory{
+		NewsID:      news.ID,
+		FromStatus:  from,
+		ToStatus:    news.Status,
+		Action:      action,
+		ChangedByID: &changedBy,
+	}
+	if note != "" {
+		history.Note = &note
+	}
+
+	updated, err := h.NewsRepository.TransitionStatus(news, from, &history)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update news status", err)
+		return
+	}
+	if !updated {
+		utils.RespondError(c, http.StatusConflict, "The post status was changed by someone else, reload and try again", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, news)
+}
+
+// recordStatusChange stores a history entry for a status change made through create or update.
+// The post itself is already saved, so a failure is only reported in the log.
+func (h *NewsHandler) recordStatusChange(c *gin.Context, newsID uint64, action string, from, to models.NewsStatus) {
+	changedBy := c.GetUint64("userID")
+	history := models.NewsStatusHistory{
+		NewsID:      newsID,
+		FromStatus:  from,
+		ToStatus:    to,
+		Action:      action,
+		ChangedByID: &changedBy,
+	}
+	if err := h.NewsRepository.RecordStatusChange(&history); err != nil {
+		log.Printf("Failed to record status change of news %d: %v", newsID, err)
+	}
+}
+
+// getManageableNews loads the post identified by the :id parameter and checks that the
+// authenticated user may manage it, responding with an error if not
+func (h *NewsHandler) getManageableNews(c *gin.Context) (*models.News, bool) {
+	news, ok := h.getNews(c)
+	if !ok || !h.canManageNews(c, news) {
+		return nil, false
+	}
+	return news, true
+}
+
+// getNews loads the post identified by the :id parameter, responding with an error if it fails
+func (h *NewsHandler) getNews(c *gin.Context) (*models.News, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+		return nil, false
+	}
+
+	news, err := h.NewsRepository.GetNewsByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "News not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve news", err)
+		return nil, false
+	}
+	return news, true
+}
+
+// ScheduledChange is a publication or unpublication that the scheduler will apply
+type ScheduledChange struct {
+	Action string       `json:"action"` // publish or unpublish
+	At     time.Time    `json:"at"`
+	News   *models.News `json:"news"`
+}
+
+// GetScheduledChanges lists the upcoming scheduled publications and unpublications, soonest first
+func (h *NewsHandler) GetScheduledChanges(c *gin.Context) {
+	now := time.Now()
+
+	toPublish, err := h.NewsRepository.GetUpcomingPublications(now)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve scheduled posts", err)
+		return
+	}
+	toUnpublish, err := h.NewsRepository.GetUpcomingUnpublications(now)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve scheduled posts", err)
+		return
+	}
+
+	changes := make([]ScheduledChange, 0, len(toPublish)+len(toUnpublish))
+	for i := range toPublish {
+		changes = append(changes, ScheduledChange{Action: "publish", At: *toPublish[i].PublishedAt, News: &toPublish[i]})
+	}
+	for i := range toUnpublish {
+		changes = append(changes, ScheduledChange{Action: "unpublish", At: *toUnpublish[i].UnpublishAt, News: &toUnpublish[i]})
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].At.Before(changes[j].At)
+	})
+
+	c.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// FieldChange holds the old and new value of a field that differs between two revisions
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// RevisionDiffResponse describes the differences between two revisions of a post
+type RevisionDiffResponse struct {
+	From    int                    `json:"from"`
+	To      int                    `json:"to"`
+	Changes map[string]FieldChange `json:"changes"`
+	Content []utils.DiffLine       `json:"content"`
+}
+
+// GetNewsRevisions lists the revisions of a post, newest first
+func (h *NewsHandler) GetNewsRevisions(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.NewsRepository.GetRevisions(news.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve revisions", err)
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetNewsRevision retrieves a single revision of a post including its content
+func (h *NewsHandler) GetNewsRevision(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.getRevision(c, news.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffNewsRevisions compares two revisions of a post (?from=&to=)
+func (h *NewsHandler) DiffNewsRevisions(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
+
+	from, ok := h.getRevision(c, news.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := h.getRevision(c, news.ID, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	changes := make(map[string]FieldChange)
+	if from.Title != to.Title {
+		changes["title"] = FieldChange{From: from.Title, To: to.Title}
+	}
+	if from.Slug != to.Slug {
+		changes["slug"] = FieldChange{From: from.Slug, To: to.Slug}
+	}
+	if stringValue(from.FeaturedImageURL) != stringValue(to.FeaturedImageURL) {
+		changes["featured_image_url"] = FieldChange{From: from.FeaturedImageURL, To: to.FeaturedImageURL}
+	}
+	if from.Status != to.Status {
+		changes["status"] = FieldChange{From: from.Status, To: to.Status}
+	}
+	if !sameTime(from.PublishedAt, to.PublishedAt) {
+		changes["published_at"] = FieldChange{From: from.PublishedAt, To: to.PublishedAt}
+	}
+
+	c.JSON(http.StatusOK, RevisionDiffResponse{
+		From:    from.RevisionNumber,
+		To:      to.RevisionNumber,
+		Changes: changes,
+		Content: utils.DiffLines(from.Content, to.Content),
+	})
+}
+
+// RestoreNewsRevision puts the title, content and featured image of an earlier revision back
+// into the post. The status is left alone, and the restore itself becomes a new revision.
+// Changing a published or scheduled post this way needs news.publish, as it does in UpdateNews.
+func (h *NewsHandler) RestoreNewsRevision(c *gin.Context) {
+	news, ok := h.getManageableNews(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.getRevision(c, news.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	contentChanged := revision.Title != news.Title || revision.Content != news.Content ||
+		stringValue(revision.FeaturedImageURL) != stringValue(news.FeaturedImageURL)
+	if contentChanged && isPublicationStatus(news.Status) && !h.canPublish(c) {
+		return
+	}
+
+	if revision.Title != news.Title {
+		slug, err := h.generateUniqueSlug(revision.Title, news.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to generate unique slug", err)
+			return
+		}
+		news.Title = revision.Title
+		news.Slug = slug
+	}
+	news.Content = revision.Content
+	news.FeaturedImageURL = revision.FeaturedImageURL
+
+	restoredFrom := revision.RevisionNumber
+	if err := h.NewsRepository.UpdateNews(news, c.GetUint64("userID"), &restoredFrom); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to restore revision", err)
+		return
+	}
+	c.JSON(http.StatusOK, news)
+}
+
+// getRevision loads a revision of a post by its number, responding with an error if it fails
+func (h *NewsHandler) getRevision(c *gin.Context, newsID uint64, number string) (*models.NewsRevision, bool) {
+	revisionNumber, err := strconv.Atoi(number)
+	if err != nil || revisionNumber < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return nil, false
+	}
+
+	revision, err := h.NewsRepository.GetRevision(newsID, revisionNumber)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Revision not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve revision", err)
+		return nil, false
+	}
+	return revision, true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
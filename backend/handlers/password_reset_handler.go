@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/mailer"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// ForgotPasswordRequest defines the input for requesting a password reset link
+type ForgotPasswordRequest struct {
+	Identifier string `json:"identifier" binding:"required"` // Username or email
+}
+
+// ResetPasswordRequest defines the input for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token           string `json:"token" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+// PasswordResetHandler handles the self-service password reset flow
+type PasswordResetHandler struct {
+	UserRepository          repositories.UserRepository
+	PasswordResetRepository repositories.PasswordResetRepository
+	SessionRepository       repositories.SessionRepository
+	Mailer                  mailer.Sender
+}
+
+// NewPasswordResetHandler creates a new PasswordResetHandler
+func NewPasswordResetHandler(userRepo repositories.UserRepository, resetRepo repositories.PasswordResetRepository, sessionRepo repositories.SessionRepository, sender mailer.Sender) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		UserRepository:          userRepo,
+		PasswordResetRepository: resetRepo,
+		SessionRepository:       sessionRepo,
+		Mailer:                  sender,
+	}
+}
+
+// ForgotPassword emails a reset link to the user. The response is the same whether or not
+// the account exists, so the endpoint cannot be used to discover usernames. The link is
+// created and sent in the background, so the response time does not reveal it either.
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Username or email is required", err)
+		return
+	}
+
+	response := gin.H{"message": "If the account exists and has an email address, a reset link has been sent"}
+
+	identifier := strings.TrimSpace(req.Identifier)
+	user, err := h.UserRepository.GetUserByUsername(identifier)
+	if err == gorm.ErrRecordNotFound {
+		user, err = h.UserRepository.GetUserByEmail(identifier)
+	}
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Failed to look up user for password reset: %v", err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if user.Email == nil || *user.Email == "" {
+		log.Printf("Password reset requested for user %d without an email address", user.ID)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Failures are only logged, answering differently would reveal that the account exists
+	go func() {
+		link, expiresAt, err := h.createResetLink(user.ID, nil)
+		if err != nil {
+			log.Printf("Failed to create reset link for user %d: %v", user.ID, err)
+			return
+		}
+		if err := h.sendResetEmail(user, link, expiresAt); err != nil {
+			log.Printf("Failed to send reset email to user %d: %v", user.ID, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using a valid reset token
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if req.NewPassword != req.ConfirmPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new password and confirm password do not match"})
+		return
+	}
+
+	token, err := h.PasswordResetRepository.GetValidToken(utils.HashToken(req.Token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusBadRequest, "Reset link is invalid or has expired", nil)
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify reset link", err)
+		return
+	}
+
+	// Consume the token first so it cannot be used twice, even concurrently
+	used, err := h.PasswordResetRepository.MarkUsed(token.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify reset link", err)
+		return
+	}
+	if !used {
+		utils.RespondError(c, http.StatusBadRequest, "Reset link is invalid or has expired", nil)
+		return
+	}
+
+	if err := h.UserRepository.SetPassword(token.UserID, req.NewPassword); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update password", err)
+		return
+	}
+
+	// Whoever knew the old password must not stay logged in
+	if err := h.SessionRepository.RevokeAllUserSessions(token.UserID); err != nil {
+		log.Printf("Failed to revoke sessions after password reset for user %d: %v", token.UserID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+}
+
+// CreateResetLinkForUser lets an admin create a reset link for a user they may manage (Admin
+// protected). The link is returned so it can be handed over directly, and also emailed if the user has an address.
+func (h *PasswordResetHandler) CreateResetLinkForUser(c *gin.Context) {
+	// A reset link takes over the account, so only superadmin may create one for a superadmin
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	var createdBy *uint64
+	if requesterID, exists := c.Get("userID"); exists {
+		requester := requesterID.(uint64)
+		createdBy = &requester
+	}
+
+	link, expiresAt, err := h.createResetLink(user.ID, createdBy)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create reset link", err)
+		return
+	}
+
+	emailed := false
+	if user.Email != nil && *user.Email != "" {
+		if err := h.sendResetEmail(user, link, expiresAt); err != nil {
+			log.Printf("Failed to email reset link to user %d: %v", user.ID, err)
+		} else {
+			emailed = true
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"reset_url":  link,
+		"expires_at": expiresAt,
+		"emailed":    emailed,
+	})
+}
+
+// createResetLink stores a new hashed token and returns the link containing the plain token
+func (h *PasswordResetHandler) createResetLink(userID uint64, createdBy *uint64) (string, time.Time, error) {
+	plainToken, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(utils.PasswordResetTTL())
+	token := models.PasswordResetToken{
+		UserID:      userID,
+		TokenHash:   utils.HashToken(plainToken),
+		ExpiresAt:   expiresAt,
+		CreatedByID: createdBy,
+	}
+	if err := h.PasswordResetRepository.CreateToken(&token); err != nil {
+		return "", time.Time{}, err
+	}
+
+	link := fmt.Sprintf("%s/admin/reset-password?token=%s", config.AppBaseURL(), url.QueryEscape(plainToken))
+	return link, expiresAt, nil
+}
+
+// sendResetEmail sends the reset link to the user's email address
+func (h *PasswordResetHandler) sendResetEmail(user *models.User, link string, expiresAt time.Time) error {
+	body := fmt.Sprintf(`Halo %s,
+
+Kami menerima permintaan untuk mengatur ulang kata sandi akun "%s".
+Buka tautan berikut untuk membuat kata sandi baru:
+
+%s
+
+Tautan ini hanya dapat dipakai sekali dan berlaku sampai %s.
+Jika Anda tidak meminta pengaturan ulang, abaikan email ini.
+`, user.FullName, user.Username, link, expiresAt.Format("02-01-2006 15:04 MST"))
+
+	return h.Mailer.Send(mailer.Message{
+		To:      *user.Email,
+		Subject: "Atur ulang kata sandi",
+		Body:    body,
+	})
+}
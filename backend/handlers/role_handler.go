@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// CreateRoleInput defines the expected input for creating a role
+type CreateRoleInput struct {
+	Name        string   `json:"name" binding:"required"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleInput defines the expected input for updating a role
+type UpdateRoleInput struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"` // Omit to keep the current permissions
+}
+
+// RoleResponse is a role together with the names of its permissions
+type RoleResponse struct {
+	ID          uint64    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	IsSystem    bool      `json:"is_system"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RoleHandler handles role and permission management requests
+type RoleHandler struct {
+	RoleRepository repositories.RoleRepository
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(roleRepo repositories.RoleRepository) *RoleHandler {
+	return &RoleHandler{RoleRepository: roleRepo}
+}
+
+// GetPermissions lists every permission that can be granted to a role
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, config.Permissions)
+}
+
+// GetAllRoles lists all roles with their permissions
+func (h *RoleHandler) GetAllRoles(c *gin.Context) {
+	roles, err := h.RoleRepository.GetAllRoles()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve roles", err)
+		return
+	}
+
+	response := make([]RoleResponse, len(roles))
+	for i := range roles {
+		response[i] = toRoleResponse(&roles[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRoleByID retrieves a single role with its permissions
+func (h *RoleHandler) GetRoleByID(c *gin.Context) {
+	role, ok := h.getRole(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+// CreateRole creates a new role
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var input CreateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if !roleNamePattern.MatchString(name) {
+		utils.RespondError(c, http.StatusBadRequest, "Role name must be 2-50 lowercase letters, digits or underscores", nil)
+		return
+	}
+	if err := validatePermissions(input.Permissions); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if !canGrantPermissions(c, h.RoleRepository, input.Permissions, "You cannot grant permissions you do not have") {
+		return
+	}
+
+	if _, err := h.RoleRepository.GetRoleByName(name); err == nil {
+		utils.RespondError(c, http.StatusConflict, "Role already exists", nil)
+		return
+	} else if err != gorm.ErrRecordNotFound {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create role", err)
+		return
+	}
+
+	role := models.Role{Name: name, Description: input.Description}
+	if err := h.RoleRepository.CreateRole(&role, input.Permissions); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create role", err)
+		return
+	}
+
+	h.respondWithRole(c, http.StatusCreated, role.ID)
+}
+
+// UpdateRole changes the description and permission set of a role
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	role, ok := h.getRole(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	isSuperadminRole := role.Name == string(models.UserRoleSuperadmin)
+	if isSuperadminRole && models.UserRole(c.GetString("userRole")) != models.UserRoleSuperadmin {
+		utils.RespondError(c, http.StatusForbidden, "Only superadmin can change the superadmin role", nil)
+		return
+	}
+
+	// Changing a role's permissions needs every permission it has now and will have afterwards,
+	// so users cannot grant themselves more or strip roles above their own
+	permissions := rolePermissionNames(role)
+	if input.Permissions != nil {
+		// Superadmin must always keep every permission, otherwise the system could be locked
+		if isSuperadminRole {
+			utils.RespondError(c, http.StatusForbidden, "Permissions of the superadmin role cannot be changed", nil)
+			return
+		}
+		if err := validatePermissions(input.Permissions); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if !canGrantPermissions(c, h.RoleRepository, append(permissions, input.Permissions...), "You cannot change permissions you do not have") {
+			return
+		}
+		permissions = input.Permissions
+	}
+
+	if input.Description != nil {
+		role.Description = input.Description
+	}
+	if err := h.RoleRepository.UpdateRole(role, permissions); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update role", err)
+		return
+	}
+
+	h.respondWithRole(c, http.StatusOK, role.ID)
+}
+
+// DeleteRole deletes a role that is not built in and not assigned to any user
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	role, ok := h.getRole(c)
+	if !ok {
+		return
+	}
+
+	if role.IsSystem {
+		utils.RespondError(c, http.StatusForbidden, "Built-in roles cannot be deleted", nil)
+		return
+	}
+
+	count, err := h.RoleRepository.CountUsersWithRole(role.Name)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete role", err)
+		return
+	}
+	if count > 0 {
+		utils.RespondError(c, http.StatusConflict, fmt.Sprintf("Role is still assigned to %d user(s)", count), nil)
+		return
+	}
+
+	if err := h.RoleRepository.DeleteRole(role.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// canGrantPermissions responds with message and returns false if the role of the authenticated
+// user lacks any of the given permissions. Superadmin holds every permission.
+func canGrantPermissions(c *gin.Context, roleRepo repositories.RoleRepository, permissions []string, message string) bool {
+	requestingRole := c.GetString("userRole")
+	if models.UserRole(requestingRole) == models.UserRoleSuperadmin {
+		return true
+	}
+
+	requester, err := roleRepo.GetRoleByName(requestingRole)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify role", err)
+		return false
+	}
+	granted := make(map[string]bool, len(requester.Permissions))
+	for _, p := range requester.Permissions {
+		granted[p.Permission] = true
+	}
+	for _, permission := range permissions {
+		if !granted[permission] {
+			utils.RespondError(c, http.StatusForbidden, message, nil)
+			return false
+		}
+	}
+	return true
+}
+
+// rolePermissionNames returns the names of the permissions of a role
+func rolePermissionNames(role *models.Role) []string {
+	names := make([]string, len(role.Permissions))
+	for i, p := range role.Permissions {
+		names[i] = p.Permission
+	}
+	return names
+}
+
+// getRole loads the role identified by the :id parameter, responding with an error if it fails
+func (h *RoleHandler) getRole(c *gin.Context) (*models.Role, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return nil, false
+	}
+
+	role, err := h.RoleRepository.GetRoleByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Role not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve role", err)
+		return nil, false
+	}
+	return role, true
+}
+
+// respondWithRole reloads a role so the response contains its current permissions
+func (h *RoleHandler) respondWithRole(c *gin.Context, status int, id uint64) {
+	role, err := h.RoleRepository.GetRoleByID(id)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve role", err)
+		return
+	}
+	c.JSON(status, toRoleResponse(role))
+}
+
+// validatePermissions rejects permission names that are not in the catalog
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !config.IsKnownPermission(permission) {
+			return fmt.Errorf("Unknown permission: %s", permission)
+		}
+	}
+	return nil
+}
+
+func toRoleResponse(role *models.Role) RoleResponse {
+	return RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: rolePermissionNames(role),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
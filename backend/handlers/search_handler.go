@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+const maxSearchQueryLength = 200
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	SearchRepository repositories.SearchRepository
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(searchRepo repositories.SearchRepository) *SearchHandler {
+	return &SearchHandler{SearchRepository: searchRepo}
+}
+
+// Search searches published news, services and potentials (?q=&type=&page=&limit=)
+func (h *SearchHandler) Search(c *gin.Context) {
+	h.search(c, false)
+}
+
+// SearchAdmin searches all content including news that is not published (Admin protected)
+func (h *SearchHandler) SearchAdmin(c *gin.Context) {
+	h.search(c, true)
+}
+
+func (h *SearchHandler) search(c *gin.Context, includeUnpublished bool) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.RespondError(c, http.StatusBadRequest, "Query parameter q is required", nil)
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		utils.RespondError(c, http.StatusBadRequest, "Search query is too long", nil)
+		return
+	}
+
+	var types []string
+	if typeParam := c.Query("type"); typeParam != "" {
+		for _, t := range strings.Split(typeParam, ",") {
+			switch t {
+			case repositories.SearchTypeNews, repositories.SearchTypeService, repositories.SearchTypePotential:
+				types = append(types, t)
+			default:
+				utils.RespondError(c, http.StatusBadRequest, "Invalid type, use news, service or potential", nil)
+				return
+			}
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	results, total, err := h.SearchRepository.Search(query, repositories.SearchOptions{
+		Types:              types,
+		IncludeUnpublished: includeUnpublished,
+		Page:               page,
+		Limit:              limit,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to search", err)
+		return
+	}
+
+	totalPages := (total + int64(limit) - 1) / int64(limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        results,
+		"currentPage": page,
+		"totalPages":  totalPages,
+		"totalItems":  total,
+	})
+}
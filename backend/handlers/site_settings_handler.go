@@ -3,6 +3,8 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ihsanularifinm/sid-seirotan/backend/config"
@@ -36,6 +38,9 @@ func (h *SiteSettingsHandler) GetAll(c *gin.Context) {
 	// Convert to map for easier frontend consumption
 	settingsMap := make(map[string]interface{})
 	for _, setting := range settings {
+		if config.PrivateSettingKeys[setting.SettingKey] {
+			continue
+		}
 		if setting.SettingValue != nil {
 			settingsMap[setting.SettingKey] = *setting.SettingValue
 		} else {
@@ -70,6 +75,9 @@ func (h *SiteSettingsHandler) GetByGroup(c *gin.Context) {
 	// Convert to map for easier frontend consumption
 	settingsMap := make(map[string]interface{})
 	for _, setting := range settings {
+		if config.PrivateSettingKeys[setting.SettingKey] {
+			continue
+		}
 		if setting.SettingValue != nil {
 			settingsMap[setting.SettingKey] = *setting.SettingValue
 		} else {
@@ -169,6 +177,10 @@ func (h *SiteSettingsHandler) BulkUpdate(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if !canEditSetting(c, setting.SettingKey) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only superadmin can change " + setting.SettingKey})
+			return
+		}
 	}
 
 	// SYNC LOGIC: If profile settings are updated, sync to general settings
@@ -258,6 +270,10 @@ func (h *SiteSettingsHandler) Upsert(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !canEditSetting(c, setting.SettingKey) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only superadmin can change " + setting.SettingKey})
+		return
+	}
 
 	if err := h.repo.Upsert(&setting); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save setting"})
@@ -275,10 +291,60 @@ func validateSetting(setting *models.SiteSetting) error {
 			return &ValidationError{Message: setting.SettingKey + " must be 255 characters or less"}
 		}
 	}
+
+	// Raw page views are kept for a whole number of days
+	if setting.SettingKey == config.AnalyticsRawRetentionSettingKey && setting.SettingValue != nil {
+		if days, err := strconv.Atoi(strings.TrimSpace(*setting.SettingValue)); err != nil || days < 1 {
+			return &ValidationError{Message: setting.SettingKey + " must be a number of days of at least 1"}
+		}
+	}
 	
 	return nil
 }
 
+// settingValue returns the value of a site setting, or the fallback if it is missing, empty
+// or still the "[...]" placeholder of the default settings
+func settingValue(repo repositories.SiteSettingsRepository, key string, fallback string) string {
+	setting, err := repo.GetByKey(key)
+	if err != nil || setting.SettingValue == nil || !isSettingFilledIn(*setting.SettingValue) {
+		return fallback
+	}
+	return *setting.SettingValue
+}
+
+// filledInSettings returns all site settings that have a real value, keyed by setting key.
+// Use it instead of settingValue when many settings are needed at once.
+func filledInSettings(repo repositories.SiteSettingsRepository) (map[string]string, error) {
+	settings, err := repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		if setting.SettingValue != nil && isSettingFilledIn(*setting.SettingValue) {
+			values[setting.SettingKey] = *setting.SettingValue
+		}
+	}
+	return values, nil
+}
+
+// isSettingFilledIn reports whether a setting value is set, as opposed to empty or a default placeholder
+func isSettingFilledIn(value string) bool {
+	value = strings.TrimSpace(value)
+	return value != "" && !(strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"))
+}
+
+// canEditSetting checks whether the requesting user may change a setting key
+func canEditSetting(c *gin.Context, key string) bool {
+	if !config.SuperadminOnlySettingKeys[key] {
+		return true
+	}
+	role, _ := c.Get("userRole")
+	roleStr, _ := role.(string)
+	return models.UserRole(roleStr) == models.UserRoleSuperadmin
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Message string
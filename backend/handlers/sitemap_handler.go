@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// sitemapMaxURLs is the number of URLs a single sitemap file may hold (the protocol allows 50,000).
+// Larger sites get a sitemap index pointing at numbered sitemap files.
+const sitemapMaxURLs = 50000
+
+// defaultRobotsDisallow is used while the robots_disallow setting has not been saved yet
+const defaultRobotsDisallow = "/admin\n/api/"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// staticPage is a page of the website that is not backed by a single content row.
+// contentType names the content whose last change also changes the page, if any.
+type staticPage struct {
+	path        string
+	changeFreq  string
+	priority    string
+	contentType string
+}
+
+// staticPages are the fixed pages of the website, listed first in the sitemap
+var staticPages = []staticPage{
+	{path: "/", changeFreq: "daily", priority: "1.0", contentType: repositories.SitemapTypeNews},
+	{path: "/profil", changeFreq: "monthly", priority: "0.8"},
+	{path: "/pemerintahan", changeFreq: "monthly", priority: "0.8"},
+	{path: "/layanan", changeFreq: "weekly", priority: "0.7", contentType: repositories.SitemapTypeService},
+	{path: "/potensi", changeFreq: "weekly", priority: "0.7", contentType: repositories.SitemapTypePotential},
+	{path: "/berita", changeFreq: "daily", priority: "0.9", contentType: repositories.SitemapTypeNews},
+	{path: "/kontak", changeFreq: "monthly", priority: "0.6"},
+}
+
+// SitemapHandler serves sitemap.xml and robots.txt for search engines
+type SitemapHandler struct {
+	SitemapRepository  repositories.SitemapRepository
+	SettingsRepository repositories.SiteSettingsRepository
+}
+
+// NewSitemapHandler creates a new SitemapHandler
+func NewSitemapHandler(sitemapRepo repositories.SitemapRepository, settingsRepo repositories.SiteSettingsRepository) *SitemapHandler {
+	return &SitemapHandler{SitemapRepository: sitemapRepo, SettingsRepository: settingsRepo}
+}
+
+// GetSitemap serves the sitemap, or a sitemap index when there are more URLs than fit in one file
+func (h *SitemapHandler) GetSitemap(c *gin.Context) {
+	total, err := h.totalURLs()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+		return
+	}
+	if total <= sitemapMaxURLs {
+		h.writeSitemapPage(c, 1)
+		return
+	}
+
+	lastModified, err := h.SitemapRepository.GetLastModified()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+		return
+	}
+	lastMod := ""
+	if newest := latest(lastModified); !newest.IsZero() {
+		lastMod = newest.UTC().Format(time.RFC3339)
+	}
+
+	pages := int((total + sitemapMaxURLs - 1) / sitemapMaxURLs)
+	index := sitemapIndex{Sitemaps: make([]sitemapPointer, pages)}
+	for i := range index.Sitemaps {
+		index.Sitemaps[i] = sitemapPointer{
+			Loc:     config.AbsoluteURL(fmt.Sprintf("/sitemaps/%d.xml", i+1)),
+			LastMod: lastMod,
+		}
+	}
+	writeXML(c, index)
+}
+
+// GetSitemapPage serves one numbered sitemap file of a sitemap index, e.g. /sitemaps/2.xml
+func (h *SitemapHandler) GetSitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || page < 1 || !strings.HasSuffix(c.Param("file"), ".xml") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+
+	total, err := h.totalURLs()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+		return
+	}
+	if int64(page-1)*sitemapMaxURLs >= total {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+
+	h.writeSitemapPage(c, page)
+}
+
+// GetRobots serves robots.txt. The disallowed paths come from the robots_disallow setting, and
+// the whole site is disallowed when search_engine_indexing is false.
+func (h *SitemapHandler) GetRobots(c *gin.Context) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+
+	if strings.EqualFold(strings.TrimSpace(settingValue(h.SettingsRepository, "search_engine_indexing", "true")), "false") {
+		b.WriteString("Disallow: /\n")
+	} else {
+		disallow := defaultRobotsDisallow
+		if setting, err := h.SettingsRepository.GetByKey("robots_disallow"); err == nil && setting.SettingValue != nil {
+			disallow = *setting.SettingValue
+		}
+
+		paths := robotsPaths(disallow)
+		if len(paths) == 0 {
+			b.WriteString("Disallow:\n")
+		}
+		for _, path := range paths {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+	}
+
+	b.WriteString("\nSitemap: " + config.AbsoluteURL("/sitemap.xml") + "\n")
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.String(http.StatusOK, b.String())
+}
+
+// totalURLs counts the static pages and content entries in the sitemap
+func (h *SitemapHandler) totalURLs() (int64, error) {
+	count, err := h.SitemapRepository.CountEntries()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(staticPages)) + count, nil
+}
+
+// writeSitemapPage writes one sitemap file. The static pages come first, followed by the
+// content entries, so page n holds positions (n-1)*sitemapMaxURLs up to n*sitemapMaxURLs.
+func (h *SitemapHandler) writeSitemapPage(c *gin.Context, page int) {
+	start := (page - 1) * sitemapMaxURLs
+	end := start + sitemapMaxURLs
+
+	set := sitemapURLSet{}
+
+	if start < len(staticPages) {
+		lastModified, err := h.SitemapRepository.GetLastModified()
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+			return
+		}
+		for _, p := range staticPages[start:min(end, len(staticPages))] {
+			u := sitemapURL{Loc: config.AbsoluteURL(p.path), ChangeFreq: p.changeFreq, Priority: p.priority}
+			if t, ok := lastModified[p.contentType]; ok {
+				u.LastMod = t.UTC().Format(time.RFC3339)
+			}
+			set.URLs = append(set.URLs, u)
+		}
+	}
+
+	offset := max(start-len(staticPages), 0)
+	limit := end - len(staticPages) - offset
+	if limit > 0 {
+		entries, err := h.SitemapRepository.GetEntries(offset, limit)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+			return
+		}
+		for _, entry := range entries {
+			if entry.Type != repositories.SitemapTypeNews {
+				continue
+			}
+			set.URLs = append(set.URLs, sitemapURL{
+				Loc:        config.NewsURL(entry.Slug),
+				LastMod:    entry.UpdatedAt.UTC().Format(time.RFC3339),
+				ChangeFreq: "weekly",
+				Priority:   "0.6",
+			})
+		}
+	}
+
+	writeXML(c, set)
+}
+
+// writeXML encodes a sitemap document as XML
+func writeXML(c *gin.Context, document interface{}) {
+	body, err := xml.Marshal(document)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to build sitemap", err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// robotsPaths splits the robots_disallow setting (one path per line, commas also accepted)
+// into clean paths that start with a slash
+func robotsPaths(value string) []string {
+	var paths []string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == '\r' || r == ',' }) {
+		path := strings.TrimSpace(field)
+		if path == "" {
+			continue
+		}
+		if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "*") {
+			path = "/" + path
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// latest returns the most recent of the given times
+func latest(times map[string]time.Time) time.Time {
+	var newest time.Time
+	for _, t := range times {
+		if t.After(newest) {
+			newest = t
+		}
+	}
+	return newest
+}
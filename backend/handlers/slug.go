@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// generateUniqueSlug creates a URL-friendly slug from a title and appends a random suffix
+// until the exists callback reports that no other record uses it. Titles without any letter
+// or digit, such as emoji only, get the fallback as their base slug.
+func generateUniqueSlug(title, fallback string, exists func(slug string) (bool, error)) (string, error) {
+	// Create the base slug
+	baseSlug := strings.ToLower(title)
+	baseSlug = slugPattern.ReplaceAllString(baseSlug, "-")
+	baseSlug = strings.Trim(baseSlug, "-")
+	if baseSlug == "" {
+		baseSlug = fallback
+	}
+
+	// Check if the base slug exists
+	slug := baseSlug
+	taken, err := exists(slug)
+	if err != nil {
+		return "", fmt.Errorf("failed to check slug existence: %w", err)
+	}
+
+	// If it exists, append a random suffix until it's unique
+	for taken {
+		suffix := strconv.Itoa(rand.IntN(1000)) // Generate a random number between 0-999
+		slug = fmt.Sprintf("%s-%s", baseSlug, suffix)
+
+		taken, err = exists(slug)
+		if err != nil {
+			return "", fmt.Errorf("failed to check slug existence during retry: %w", err)
+		}
+	}
+
+	return slug, nil
+}
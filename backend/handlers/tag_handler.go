@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"gorm.io/gorm"
+)
+
+// TagInput defines the expected input for creating or updating a tag
+type TagInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// TagHandler handles news tag requests
+type TagHandler struct {
+	TagRepository repositories.TagRepository
+}
+
+// NewTagHandler creates a new TagHandler
+func NewTagHandler(tagRepo repositories.TagRepository) *TagHandler {
+	return &TagHandler{TagRepository: tagRepo}
+}
+
+// GetAllTags lists all tags
+func (h *TagHandler) GetAllTags(c *gin.Context) {
+	tags, err := h.TagRepository.GetAll()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve tags", err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag creates a new tag (Admin protected)
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var input TagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	slug, err := h.generateUniqueSlug(name, 0)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate unique slug", err)
+		return
+	}
+
+	tag := models.Tag{Name: name, Slug: slug}
+	if err := h.TagRepository.Create(&tag); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to create tag", err)
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag updates an existing tag, regenerating the slug when the name changes (Admin protected)
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	tag, ok := h.getTag(c)
+	if !ok {
+		return
+	}
+
+	var input TagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name != tag.Name {
+		slug, err := h.generateUniqueSlug(name, tag.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to generate unique slug", err)
+			return
+		}
+		tag.Name = name
+		tag.Slug = slug
+	}
+
+	if err := h.TagRepository.Update(tag); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to update tag", err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag deletes a tag and removes it from all posts (Admin protected)
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	tag, ok := h.getTag(c)
+	if !ok {
+		return
+	}
+
+	if err := h.TagRepository.Delete(tag.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to delete tag", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// generateUniqueSlug creates a slug for a tag name that no other tag uses
+func (h *TagHandler) generateUniqueSlug(name string, currentID uint64) (string, error) {
+	return generateUniqueSlug(name, "tag", func(slug string) (bool, error) {
+		return h.TagRepository.IsSlugExist(slug, currentID)
+	})
+}
+
+// getTag loads the tag identified by the :id parameter, responding with an error if it fails
+func (h *TagHandler) getTag(c *gin.Context) (*models.Tag, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return nil, false
+	}
+
+	tag, err := h.TagRepository.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "Tag not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve tag", err)
+		return nil, false
+	}
+	return tag, true
+}
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recoveryCodeCount is the number of one-time recovery codes issued when 2FA is enabled
+const recoveryCodeCount = 10
+
+// TwoFactorChallengeRequest identifies the user of a pending two-step login
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorVerifyRequest completes a two-step login with a TOTP code or a recovery code
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// TwoFactorEnableRequest confirms a pending TOTP secret during a two-step login
+type TwoFactorEnableRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code (or recovery code) for profile 2FA actions
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorDisableRequest requires both the password and a second factor
+type TwoFactorDisableRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorSetupResponse contains the new secret for the authenticator app
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // Render as QR code
+}
+
+// TwoFactorStatusResponse describes the 2FA state of the current user
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// --- Two-step login (public, authenticated by the challenge token) ---
+
+// VerifyTwoFactor completes a login for a user with 2FA enabled
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "challenge_token is required", err)
+		return
+	}
+
+	user, ok := h.getChallengeUser(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+	if !user.TwoFactorEnabled {
+		utils.RespondError(c, http.StatusBadRequest, "Two-factor authentication is not enabled for this account", nil)
+		return
+	}
+	if !h.checkLoginAllowed(c, user) {
+		return
+	}
+
+	valid, err := h.verifySecondFactor(user, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+		return
+	}
+	if !valid {
+		h.recordFailedLogin(c, user, "invalid_2fa_code")
+		utils.RespondError(c, http.StatusUnauthorized, "Invalid two-factor code", nil)
+		return
+	}
+
+	h.completeLogin(c, user, nil)
+}
+
+// SetupTwoFactorChallenge starts enrollment for a user whose role requires 2FA but who has not enrolled yet
+func (h *AuthHandler) SetupTwoFactorChallenge(c *gin.Context) {
+	var req TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "challenge_token is required", err)
+		return
+	}
+
+	user, ok := h.getChallengeUser(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+
+	h.beginTwoFactorSetup(c, user)
+}
+
+// EnableTwoFactorChallenge confirms enrollment during login and completes the login
+func (h *AuthHandler) EnableTwoFactorChallenge(c *gin.Context) {
+	var req TwoFactorEnableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "challenge_token and code are required", err)
+		return
+	}
+
+	user, ok := h.getChallengeUser(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+	if !h.checkLoginAllowed(c, user) {
+		return
+	}
+
+	recoveryCodes, ok := h.confirmTwoFactorSetup(c, user, req.Code)
+	if !ok {
+		return
+	}
+
+	h.completeLogin(c, user, recoveryCodes)
+}
+
+// --- Profile 2FA management (requires a valid access token) ---
+
+// GetTwoFactorStatus returns the 2FA state of the current user
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	remaining, err := h.RecoveryCodeRepository.CountRemaining(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to count recovery codes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorStatusResponse{
+		Enabled:                user.TwoFactorEnabled,
+		Required:               h.isTwoFactorRequired(user.Role),
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+// SetupTwoFactor generates a new pending TOTP secret for the current user
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	h.beginTwoFactorSetup(c, user)
+}
+
+// EnableTwoFactor confirms the pending secret of the current user and returns recovery codes
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		utils.RespondError(c, http.StatusBadRequest, "code is required", err)
+		return
+	}
+
+	recoveryCodes, ok := h.confirmTwoFactorSetup(c, user, req.Code)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled successfully",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableTwoFactor turns off 2FA for the current user
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "password is required", err)
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.RespondError(c, http.StatusBadRequest, "Two-factor authentication is not enabled", nil)
+		return
+	}
+	if h.isTwoFactorRequired(user.Role) {
+		utils.RespondError(c, http.StatusForbidden, "Two-factor authentication is required for your role and cannot be disabled", nil)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Password is incorrect", nil)
+		return
+	}
+
+	valid, err := h.verifySecondFactor(user, req.Code, req.RecoveryCode)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+		return
+	}
+	if !valid {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid two-factor code", nil)
+		return
+	}
+
+	if err := h.UserRepository.DisableTwoFactor(user.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled successfully"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the current user
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := h.getCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		utils.RespondError(c, http.StatusBadRequest, "code is required", err)
+		return
+	}
+
+	if !user.TwoFactorEnabled {
+		utils.RespondError(c, http.StatusBadRequest, "Two-factor authentication is not enabled", nil)
+		return
+	}
+
+	// Only a TOTP code is accepted here, a recovery code cannot be used to mint new ones
+	valid, err := h.verifySecondFactor(user, req.Code, "")
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+		return
+	}
+	if !valid {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid two-factor code", nil)
+		return
+	}
+
+	recoveryCodes, err := h.issueRecoveryCodes(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate recovery codes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// ResetUserTwoFactor removes 2FA from another user, e.g. after a lost phone. Only superadmin
+// can reset the 2FA of a superadmin (Admin protected).
+func (h *AuthHandler) ResetUserTwoFactor(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	if err := h.UserRepository.DisableTwoFactor(user.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset successfully"})
+}
+
+// --- Helpers ---
+
+// beginTwoFactorSetup stores a new pending secret and returns it with the provisioning URI
+func (h *AuthHandler) beginTwoFactorSetup(c *gin.Context, user *models.User) {
+	if user.TwoFactorEnabled {
+		utils.RespondError(c, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate secret", err)
+		return
+	}
+
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to encrypt secret", err)
+		return
+	}
+
+	if err := h.UserRepository.SetTwoFactorSecret(user.ID, encrypted); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to save secret", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(h.twoFactorIssuer(), user.Username, secret),
+	})
+}
+
+// confirmTwoFactorSetup checks a code against the pending secret, enables 2FA and issues
+// recovery codes. It writes the error response itself and returns false on failure.
+func (h *AuthHandler) confirmTwoFactorSetup(c *gin.Context, user *models.User, code string) ([]string, bool) {
+	if user.TwoFactorEnabled {
+		utils.RespondError(c, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return nil, false
+	}
+	if user.TwoFactorSecret == nil {
+		utils.RespondError(c, http.StatusBadRequest, "Two-factor setup has not been started", nil)
+		return nil, false
+	}
+
+	secret, err := utils.DecryptSecret(*user.TwoFactorSecret)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to read secret", err)
+		return nil, false
+	}
+
+	step, valid := utils.ValidateTOTP(secret, code, time.Now())
+	if !valid {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid two-factor code", nil)
+		return nil, false
+	}
+
+	if err := h.UserRepository.EnableTwoFactor(user.ID, step); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to enable two-factor authentication", err)
+		return nil, false
+	}
+
+	recoveryCodes, err := h.issueRecoveryCodes(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to generate recovery codes", err)
+		return nil, false
+	}
+
+	return recoveryCodes, true
+}
+
+// verifySecondFactor checks a TOTP code (rejecting reuse) or consumes a recovery code
+func (h *AuthHandler) verifySecondFactor(user *models.User, code string, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		codeHash, err := utils.HashRecoveryCode(recoveryCode)
+		if err != nil {
+			return false, err
+		}
+		return h.RecoveryCodeRepository.UseCode(user.ID, codeHash)
+	}
+	if code == "" || user.TwoFactorSecret == nil {
+		return false, nil
+	}
+
+	secret, err := utils.DecryptSecret(*user.TwoFactorSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, valid := utils.ValidateTOTP(secret, code, time.Now())
+	if !valid {
+		return false, nil
+	}
+	return h.UserRepository.MarkTOTPStepUsed(user.ID, step)
+}
+
+// issueRecoveryCodes generates new recovery codes, stores their hashes and returns the plain codes
+func (h *AuthHandler) issueRecoveryCodes(userID uint64) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		if hashes[i], err = utils.HashRecoveryCode(code); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.RecoveryCodeRepository.ReplaceCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// getChallengeUser loads the user a challenge token was issued for. It writes the error
+// response itself and returns false on failure.
+func (h *AuthHandler) getChallengeUser(c *gin.Context, challengeToken string) (*models.User, bool) {
+	userID, err := utils.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnauthorized, "Invalid or expired challenge token", nil)
+		return nil, false
+	}
+
+	user, err := h.UserRepository.GetUserByID(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnauthorized, "Invalid or expired challenge token", nil)
+		return nil, false
+	}
+	return user, true
+}
+
+// getCurrentUser loads the authenticated user from the context set by AuthMiddleware
+func (h *AuthHandler) getCurrentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: User ID not found in token"})
+		return nil, false
+	}
+
+	user, err := h.UserRepository.GetUserByID(userID.(uint64))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve user", err)
+		return nil, false
+	}
+	return user, true
+}
+
+// isTwoFactorRequired reports whether the two_factor_required_roles setting forces 2FA for a role
+func (h *AuthHandler) isTwoFactorRequired(role models.UserRole) bool {
+	setting, err := h.SettingsRepository.GetByKey("two_factor_required_roles")
+	if err != nil || setting.SettingValue == nil {
+		return false
+	}
+
+	for _, r := range strings.Split(*setting.SettingValue, ",") {
+		if strings.TrimSpace(r) == string(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// twoFactorIssuer returns the issuer name shown in authenticator apps
+func (h *AuthHandler) twoFactorIssuer() string {
+	return settingValue(h.SettingsRepository, "site_name", "SID")
+}
@@ -1,55 +1,64 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
 	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
 )
 
+// UploadHandler handles file uploads and serves the uploaded files
+type UploadHandler struct {
+	Storage         storage.Storage
+	MediaRepository repositories.MediaRepository
+}
+
+// NewUploadHandler creates a new UploadHandler
+func NewUploadHandler(store storage.Storage, mediaRepo repositories.MediaRepository) *UploadHandler {
+	return &UploadHandler{Storage: store, MediaRepository: mediaRepo}
+}
+
 // UploadFile handles file uploads (legacy endpoint with UUID naming)
 // Kept for backward compatibility
-func UploadFile(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file is received"})
+func (h *UploadHandler) UploadFile(c *gin.Context) {
+	limitUploadBody(c)
+	upload, ok := receiveUpload(c, "")
+	if !ok {
 		return
 	}
 
 	// Generate a new UUID for the filename
-	filename := uuid.New().String() + filepath.Ext(file.Filename)
-	filepath := fmt.Sprintf("uploads/%s", filename)
+	filename := uuid.New().String() + strings.ToLower(filepath.Ext(upload.Filename))
 
-	// Save the file
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
+	response, _, ok := h.storeUpload(c, filename, upload, "")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"url": fmt.Sprintf("/uploads/%s", filename)})
+	c.JSON(http.StatusOK, response)
 }
 
 // UploadWithNaming handles file uploads with intelligent naming based on upload type
 // Supports: news, official, logo, hero_slider, struktur
-func UploadWithNaming(c *gin.Context) {
+func (h *UploadHandler) UploadWithNaming(c *gin.Context) {
+	limitUploadBody(c)
+
 	// Get form data
 	uploadType := c.PostForm("upload_type")
 	title := c.PostForm("title")
 	name := c.PostForm("name")
 	position := c.PostForm("position")
 
-	// Get uploaded file
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file is received"})
-		return
-	}
-
 	// Validate upload_type
 	validTypes := []string{"news", "official", "logo", "hero_slider", "struktur"}
 	isValidType := false
@@ -66,6 +75,12 @@ func UploadWithNaming(c *gin.Context) {
 		return
 	}
 
+	// Get uploaded file and check its content against the rules of the upload type
+	upload, ok := receiveUpload(c, uploadType)
+	if !ok {
+		return
+	}
+
 	// Generate appropriate filename based on upload type
 	var filename string
 	switch uploadType {
@@ -74,69 +89,168 @@ func UploadWithNaming(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required for news upload"})
 			return
 		}
-		filename = utils.GenerateNewsFilename(title, file.Filename)
+		filename = utils.GenerateNewsFilename(title, upload.Filename)
 
 	case "official":
 		if name == "" || position == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Name and position are required for official upload"})
 			return
 		}
-		filename = utils.GenerateOfficialFilename(name, position, file.Filename)
+		filename = utils.GenerateOfficialFilename(name, position, upload.Filename)
 
 	case "logo":
-		// Validate PNG or SVG only for logo
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if ext != ".png" && ext != ".svg" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Logo must be PNG or SVG format"})
-			return
-		}
-		filename = utils.GenerateLogoFilename(file.Filename)
-
-		// Delete old logo file if exists (to avoid accumulation)
-		oldLogoPattern := "uploads/logo.*"
-		matches, _ := filepath.Glob(oldLogoPattern)
-		for _, match := range matches {
-			os.Remove(match)
-		}
+		// Only PNG or SVG is accepted for the logo (see config.UploadRuleFor)
+		filename = utils.GenerateLogoFilename(upload.Filename)
 
 	case "hero_slider":
 		if title == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required for hero slider upload"})
 			return
 		}
-		filename = utils.GenerateHeroSliderFilename(title, file.Filename)
+		filename = utils.GenerateHeroSliderFilename(title, upload.Filename)
 
 	case "struktur":
-		filename = utils.GenerateStrukturFilename(file.Filename)
-
-		// Delete old struktur file if exists
-		oldStrukturPattern := "uploads/struktur-organisasi-*"
-		matches, _ := filepath.Glob(oldStrukturPattern)
-		for _, match := range matches {
-			os.Remove(match)
-		}
+		filename = utils.GenerateStrukturFilename(upload.Filename)
 
 	default:
 		// Fallback to UUID (should not reach here due to validation above)
-		filename = uuid.New().String() + filepath.Ext(file.Filename)
+		filename = uuid.New().String() + filepath.Ext(upload.Filename)
 	}
 
-	// Ensure uploads directory exists
-	if err := os.MkdirAll("uploads", 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create uploads directory"})
+	response, stored, ok := h.storeUpload(c, filename, upload, uploadType)
+	if !ok {
 		return
 	}
 
-	// Save the file
-	savePath := fmt.Sprintf("uploads/%s", filename)
-	if err := c.SaveUploadedFile(file, savePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save the file"})
-		return
+	// The logo and the organisational structure exist only once: delete the previous file
+	// and its variants (to avoid accumulation)
+	switch uploadType {
+	case "logo":
+		h.removeOlderFiles("logo", stored, func(key string) bool { return isFileOrVariant(key, "logo") })
+	case "struktur":
+		h.removeOlderFiles("struktur-organisasi-", stored, func(string) bool { return true })
 	}
 
 	// Return both URL and filename
-	c.JSON(http.StatusOK, gin.H{
-		"url":      fmt.Sprintf("/uploads/%s", filename),
-		"filename": filename,
-	})
-}
\ No newline at end of file
+	response["filename"] = filename
+	c.JSON(http.StatusOK, response)
+}
+
+// ServeUpload streams an uploaded file from the storage, with support for range requests
+// (used by browsers to seek in videos) and conditional requests
+func (h *UploadHandler) ServeUpload(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("filepath"), "/")
+	if strings.HasPrefix(key, storage.QuarantinePrefix) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	file, object, err := h.Storage.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to read the file", err)
+		return
+	}
+	defer file.Close()
+
+	if object.ContentType != "" {
+		c.Header("Content-Type", object.ContentType)
+	}
+	http.ServeContent(c.Writer, c.Request, path.Base(key), object.LastModified, file)
+}
+
+// storeUpload writes an upload and, for images, its variants to the storage. It returns the
+// response describing the stored files and the set of keys written. Uploads that cannot be
+// decoded are rejected; false means an error response was written.
+func (h *UploadHandler) storeUpload(c *gin.Context, filename string, upload *validatedUpload, uploadType string) (gin.H, map[string]bool, bool) {
+	processed, err := h.processImage(upload.Data, filename, uploadType)
+	if err != nil {
+		if errors.Is(err, errInvalidImage) {
+			utils.RespondError(c, http.StatusBadRequest, "The file is not a valid image", err)
+			return nil, nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to process the image", err)
+		return nil, nil, false
+	}
+
+	files := []storedFile{{Key: filename, Data: upload.Data, ContentType: upload.MIMEType}}
+	if processed != nil {
+		files[0].Data = processed.Original
+		files = append(files, processed.Files...)
+	}
+
+	stored := make(map[string]bool, len(files))
+	for _, file := range files {
+		if err := h.Storage.Put(file.Key, file.Data, file.ContentType); err != nil {
+			for key := range stored {
+				removeFiles(h.Storage, key)
+			}
+			utils.RespondError(c, http.StatusInternalServerError, "Unable to save the file", err)
+			return nil, nil, false
+		}
+		stored[file.Key] = true
+	}
+
+	// Variants left over from an earlier upload under the same name would not match the new image
+	for _, key := range variantKeys(filename) {
+		if !stored[key] {
+			removeFiles(h.Storage, key)
+		}
+	}
+
+	// Record the file in the media library
+	media := &models.Media{
+		StorageKey:   filename,
+		URL:          h.Storage.URL(filename),
+		OriginalName: upload.Filename,
+		UploadType:   uploadType,
+		MIMEType:     upload.MIMEType,
+		Size:         int64(len(files[0].Data)),
+	}
+	if userID := c.GetUint64("userID"); userID != 0 {
+		media.UploadedByID = &userID
+	}
+	if altText := strings.TrimSpace(c.PostForm("alt_text")); altText != "" {
+		media.AltText = &altText
+	}
+	if processed != nil {
+		media.Width = &processed.Width
+		media.Height = &processed.Height
+		media.ThumbnailURL = processed.thumbnailURL()
+	}
+	if err := h.MediaRepository.Upsert(media); err != nil {
+		for key := range stored {
+			removeFiles(h.Storage, key)
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Unable to record the file in the media library", err)
+		return nil, nil, false
+	}
+
+	response := gin.H{"url": media.URL, "media_id": media.ID}
+	if processed != nil {
+		response["width"] = processed.Width
+		response["height"] = processed.Height
+		response["variants"] = processed.Variants
+	}
+	return response, stored, true
+}
+
+// removeOlderFiles deletes the stored files starting with prefix that match, except the ones just stored
+func (h *UploadHandler) removeOlderFiles(prefix string, keep map[string]bool, match func(key string) bool) {
+	objects, err := h.Storage.List(prefix)
+	if err != nil {
+		log.Printf("Failed to list old %s files: %v", prefix, err)
+		return
+	}
+	for _, object := range objects {
+		if !keep[object.Key] && match(object.Key) {
+			removeFiles(h.Storage, object.Key)
+			if err := h.MediaRepository.DeleteByKey(object.Key); err != nil {
+				log.Printf("Failed to remove %s from the media library: %v", object.Key, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// validatedUpload is an uploaded file whose content passed the checks of its upload type
+type validatedUpload struct {
+	Filename string // Original name, with the extension added if it had none
+	MIMEType string // Detected from the content
+	Data     []byte // SVG files are already sanitised
+}
+
+// receiveUpload reads the "file" form field and checks it against the rule of the upload type:
+// maximum size, MIME type sniffed from the content, and an extension that matches that type.
+// It responds with an error and returns false if the file is rejected.
+func receiveUpload(c *gin.Context, uploadType string) (*validatedUpload, bool) {
+	rule, ok := config.UploadRuleFor(uploadType)
+	if !ok {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid upload_type", nil)
+		return nil, false
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File is too large, the maximum is %s", formatSize(rule.MaxSize)), err)
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file is received"})
+		return nil, false
+	}
+	if file.Size > rule.MaxSize {
+		utils.RespondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File is too large, the maximum is %s", formatSize(rule.MaxSize)), nil)
+		return nil, false
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Unable to read the file", err)
+		return nil, false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, rule.MaxSize+1))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Unable to read the file", err)
+		return nil, false
+	}
+	if int64(len(data)) > rule.MaxSize {
+		utils.RespondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File is too large, the maximum is %s", formatSize(rule.MaxSize)), nil)
+		return nil, false
+	}
+
+	// Trust the content, not the name or the Content-Type sent by the browser
+	detected := mimetype.Detect(data)
+	mimeType := ""
+	for _, allowed := range rule.AllowedTypes {
+		if detected.Is(allowed) {
+			mimeType = allowed
+			break
+		}
+	}
+	if mimeType == "" {
+		utils.RespondError(c, http.StatusUnsupportedMediaType, fmt.Sprintf("File type %s is not allowed, allowed types: %s", detected.String(), strings.Join(rule.AllowedTypes, ", ")), nil)
+		return nil, false
+	}
+
+	filename := file.Filename
+	ext := strings.ToLower(filepath.Ext(filename))
+	extensions := config.MIMETypeExtensions[mimeType]
+	if ext == "" {
+		filename += extensions[0]
+	} else if !slices.Contains(extensions, ext) {
+		utils.RespondError(c, http.StatusBadRequest, fmt.Sprintf("File extension %s does not match its content (%s)", ext, mimeType), nil)
+		return nil, false
+	}
+
+	if mimeType == config.MIMETypeSVG {
+		data, err = utils.SanitizeSVG(data)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid SVG file", err)
+			return nil, false
+		}
+	}
+
+	return &validatedUpload{Filename: filename, MIMEType: mimeType, Data: data}, true
+}
+
+// limitUploadBody stops reading the request body after the largest allowed upload, so an
+// oversized request is rejected without being stored in full
+func limitUploadBody(c *gin.Context) {
+	const formOverhead = 1 << 20 // Room for the other form fields and multipart boundaries
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxUploadSize()+formOverhead)
+}
+
+// formatSize formats a byte count in megabytes, e.g. "10 MB"
+func formatSize(bytes int64) string {
+	return fmt.Sprintf("%d MB", bytes>>20)
+}
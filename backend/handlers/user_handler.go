@@ -15,6 +15,7 @@ import (
 type CreateUserPayload struct {
 	FullName string          `json:"full_name" binding:"required"`
 	Username string          `json:"username" binding:"required"`
+	Email    string          `json:"email" binding:"omitempty,email"`
 	Password string          `json:"password" binding:"required"`
 	Role     models.UserRole `json:"role" binding:"required"`
 }
@@ -23,6 +24,7 @@ type CreateUserPayload struct {
 type UpdateUserPayload struct {
 	FullName string          `json:"full_name"`
 	Username string          `json:"username"`
+	Email    string          `json:"email" binding:"omitempty,email"`
 	Password string          `json:"password"` // Optional
 	Role     models.UserRole `json:"role"`
 }
@@ -41,12 +43,15 @@ type ChangePasswordResponse struct {
 
 // UserHandler handles user-related requests
 type UserHandler struct {
-	UserRepository repositories.UserRepository
+	UserRepository         repositories.UserRepository
+	SessionRepository      repositories.SessionRepository
+	LoginAttemptRepository repositories.LoginAttemptRepository
+	RoleRepository         repositories.RoleRepository
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userRepo repositories.UserRepository) *UserHandler {
-	return &UserHandler{UserRepository: userRepo}
+func NewUserHandler(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, loginAttemptRepo repositories.LoginAttemptRepository, roleRepo repositories.RoleRepository) *UserHandler {
+	return &UserHandler{UserRepository: userRepo, SessionRepository: sessionRepo, LoginAttemptRepository: loginAttemptRepo, RoleRepository: roleRepo}
 }
 
 // CreateUser creates a new user (Admin protected)
@@ -57,12 +62,19 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	if !h.canAssignRole(c, payload.Role) {
+		return
+	}
+
 	user := models.User{
 		FullName: payload.FullName,
 		Username: payload.Username,
 		PasswordHash: payload.Password, // Will be hashed by repository
 		Role:     payload.Role,
 	}
+	if payload.Email != "" {
+		user.Email = &payload.Email
+	}
 
 	if err := h.UserRepository.CreateUser(&user); err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "Failed to create user", err)
@@ -154,11 +166,15 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	// Business logic: Only superadmin can edit superadmin accounts
-	if existingUser.Role == models.UserRoleSuperadmin && requestingRole.(models.UserRole) != models.UserRoleSuperadmin {
+	if existingUser.Role == models.UserRoleSuperadmin && models.UserRole(requestingRole.(string)) != models.UserRoleSuperadmin {
 		utils.RespondError(c, http.StatusForbidden, "Only superadmin can edit superadmin accounts", nil)
 		return
 	}
 
+	// Remember what affects existing sessions before applying the payload
+	previousRole := existingUser.Role
+	passwordChanged := payload.Password != ""
+
 	// Update fields from payload
 	if payload.FullName != "" {
 		existingUser.FullName = payload.FullName
@@ -166,10 +182,16 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	if payload.Username != "" {
 		existingUser.Username = payload.Username
 	}
+	if payload.Email != "" {
+		existingUser.Email = &payload.Email
+	}
 	if payload.Password != "" {
 		existingUser.PasswordHash = payload.Password // Will be hashed by repository
 	}
 	if payload.Role != "" {
+		if !h.canAssignRole(c, payload.Role) {
+			return
+		}
 		existingUser.Role = payload.Role
 	}
 
@@ -178,6 +200,14 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// A role change or password reset invalidates every session the user has open
+	if existingUser.Role != previousRole || passwordChanged {
+		if err := h.SessionRepository.RevokeAllUserSessions(existingUser.ID); err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "User updated but failed to revoke sessions", err)
+			return
+		}
+	}
+
 	existingUser.PasswordHash = "" // Clear password hash
 	c.JSON(http.StatusOK, existingUser)
 }
@@ -210,7 +240,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if userToDelete.Role == models.UserRoleSuperadmin && requestingRole.(models.UserRole) != models.UserRoleSuperadmin {
+	if userToDelete.Role == models.UserRoleSuperadmin && models.UserRole(requestingRole.(string)) != models.UserRoleSuperadmin {
 		utils.RespondError(c, http.StatusForbidden, "Only superadmin can delete superadmin accounts", nil)
 		return
 	}
@@ -220,9 +250,141 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	if err := h.SessionRepository.RevokeAllUserSessions(id); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "User deleted but failed to revoke sessions", err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
+// GetUserSessions lists the active sessions of a user (Admin protected)
+func (h *UserHandler) GetUserSessions(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.SessionRepository.GetActiveSessionsByUser(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve sessions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeUserSession revokes a single session of a user (Admin protected)
+func (h *UserHandler) RevokeUserSession(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	revoked, err := h.SessionRepository.RevokeUserSession(user.ID, sessionID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to revoke session", err)
+		return
+	}
+	if !revoked {
+		utils.RespondError(c, http.StatusNotFound, "Session not found", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeAllUserSessions revokes every active session of a user (Admin protected)
+func (h *UserHandler) RevokeAllUserSessions(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	if err := h.SessionRepository.RevokeAllUserSessions(user.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to revoke sessions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions revoked successfully"})
+}
+
+// UnlockUser clears the lockout and failed login counter of a user (Admin protected)
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	if err := h.UserRepository.ResetFailedLogins(user.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to unlock user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// GetUserLoginAttempts returns the recent login history of a user (Admin protected)
+func (h *UserHandler) GetUserLoginAttempts(c *gin.Context) {
+	user, ok := getManageableUser(c, h.UserRepository)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	attempts, err := h.LoginAttemptRepository.GetByUser(user.ID, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve login attempts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+// getManageableUser loads the user from the :id path parameter and checks that the
+// requesting user may manage them. It writes the error response itself and returns false on failure.
+func getManageableUser(c *gin.Context, userRepo repositories.UserRepository) (*models.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	requestingRole, exists := c.Get("userRole")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: User role not found in token"})
+		return nil, false
+	}
+
+	user, err := userRepo.GetUserByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusNotFound, "User not found", err)
+			return nil, false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to retrieve user", err)
+		return nil, false
+	}
+
+	// Only superadmin can manage superadmin accounts
+	if user.Role == models.UserRoleSuperadmin && models.UserRole(requestingRole.(string)) != models.UserRoleSuperadmin {
+		utils.RespondError(c, http.StatusForbidden, "Only superadmin can manage superadmin accounts", nil)
+		return nil, false
+	}
+
+	return user, true
+}
+
 // ChangePassword allows an authenticated admin to change their own password
 func (h *UserHandler) ChangePassword(c *gin.Context) {
 	// Extract user ID from JWT token context
@@ -261,3 +423,29 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		Message: "Password changed successfully",
 	})
 }
+
+// canAssignRole checks that a role is defined and that the requesting user may give it to an
+// account: only superadmin can assign superadmin, and no one can assign a role carrying a
+// permission they do not have themselves. It responds with an error if not.
+func (h *UserHandler) canAssignRole(c *gin.Context, role models.UserRole) bool {
+	target, err := h.RoleRepository.GetRoleByName(string(role))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(c, http.StatusBadRequest, "Role does not exist", err)
+			return false
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to verify role", err)
+		return false
+	}
+
+	requestingRole := models.UserRole(c.GetString("userRole"))
+	if requestingRole == models.UserRoleSuperadmin {
+		return true
+	}
+	if role == models.UserRoleSuperadmin {
+		utils.RespondError(c, http.StatusForbidden, "Only superadmin can assign the superadmin role", nil)
+		return false
+	}
+
+	return canGrantPermissions(c, h.RoleRepository, rolePermissionNames(target), "You cannot assign a role with permissions you do not have")
+}
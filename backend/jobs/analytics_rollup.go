@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+// AnalyticsRollup summarizes each finished day of page views into the daily rollup tables, and
+// prunes raw page views older than the retention period once their day is summarized. Days
+// missed while the server was down are caught up on the next run. It also deletes the visitor
+// ID salts of past days.
+type AnalyticsRollup struct {
+	pageViewRepo repositories.PageViewRepository
+	settingsRepo repositories.SiteSettingsRepository
+	interval     time.Duration
+	retention    time.Duration // Used while the retention setting is not filled in
+	stop         chan struct{}
+}
+
+// NewAnalyticsRollup creates a new AnalyticsRollup that runs every interval. Raw page views are
+// kept for the number of days of the analytics_raw_retention_days setting, or for retention
+// when it is not set.
+func NewAnalyticsRollup(pageViewRepo repositories.PageViewRepository, settingsRepo repositories.SiteSettingsRepository, interval, retention time.Duration) *AnalyticsRollup {
+	return &AnalyticsRollup{pageViewRepo: pageViewRepo, settingsRepo: settingsRepo, interval: interval, retention: retention, stop: make(chan struct{})}
+}
+
+// Start runs the rollup in the background, beginning with an immediate run
+func (a *AnalyticsRollup) Start() {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		a.Run(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				a.Run(now)
+			case <-a.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Analytics rollup started (every %s)", a.interval)
+}
+
+// Stop ends the background loop
+func (a *AnalyticsRollup) Stop() {
+	close(a.stop)
+}
+
+// Run summarizes every finished day that has no rollup yet, then prunes old raw page views
+func (a *AnalyticsRollup) Run(now time.Time) {
+	today := utils.StartOfDay(now)
+
+	// The salt of a past day must not outlive it, even when no visitor came today to replace it
+	if err := a.pageViewRepo.DeleteSaltsBefore(today); err != nil {
+		log.Printf("Analytics rollup: failed to delete old visitor ID salts: %v", err)
+	}
+
+	next, err := a.firstPendingDay()
+	if err != nil {
+		log.Printf("Analytics rollup: failed to find the days to summarize: %v", err)
+		return
+	}
+
+	if next != nil {
+		ownHost := config.AppHost()
+		for day := *next; day.Before(today); day = day.AddDate(0, 0, 1) {
+			if err := a.pageViewRepo.RollupDay(day, ownHost); err != nil {
+				log.Printf("Analytics rollup: failed to summarize %s: %v", day.Format("2006-01-02"), err)
+				return
+			}
+		}
+	}
+
+	// Every day before today is summarized by now, so pruning never loses data
+	cutoff := utils.StartOfDay(now.Add(-a.rawRetention()))
+	deleted, err := a.pageViewRepo.DeleteViewsBefore(cutoff)
+	if err != nil {
+		log.Printf("Analytics rollup: failed to prune raw page views: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Analytics rollup: pruned %d page views older than %s", deleted, cutoff.Format("2006-01-02"))
+	}
+}
+
+// rawRetention returns how long raw page views are kept, from the site setting when it holds
+// a positive number of days
+func (a *AnalyticsRollup) rawRetention() time.Duration {
+	setting, err := a.settingsRepo.GetByKey(config.AnalyticsRawRetentionSettingKey)
+	if err != nil || setting.SettingValue == nil {
+		return a.retention
+	}
+	days, err := strconv.Atoi(strings.TrimSpace(*setting.SettingValue))
+	if err != nil || days < 1 {
+		return a.retention
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// firstPendingDay returns the day after the last summarized one, or the day of the oldest page
+// view on the first run. It returns nil when there is nothing to summarize.
+func (a *AnalyticsRollup) firstPendingDay() (*time.Time, error) {
+	last, err := a.pageViewRepo.GetLastRollupDay()
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		next := last.AddDate(0, 0, 1)
+		return &next, nil
+	}
+
+	first, err := a.pageViewRepo.GetFirstViewTime()
+	if err != nil || first == nil {
+		return nil, err
+	}
+	// viewed_at has no time zone: its date is the local date the view was recorded on
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.Local)
+	return &day, nil
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+)
+
+// NewsScheduler periodically publishes scheduled posts and archives posts whose unpublish
+// date has passed
+type NewsScheduler struct {
+	newsRepo repositories.NewsRepository
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// NewNewsScheduler creates a new NewsScheduler that runs every interval
+func NewNewsScheduler(newsRepo repositories.NewsRepository, interval time.Duration) *NewsScheduler {
+	return &NewsScheduler{newsRepo: newsRepo, interval: interval, stop: make(chan struct{})}
+}
+
+// Start runs the scheduler in the background, beginning with an immediate run
+func (s *NewsScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.Run(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				s.Run(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("News scheduler started (every %s)", s.interval)
+}
+
+// Stop ends the background loop
+func (s *NewsScheduler) Stop() {
+	close(s.stop)
+}
+
+// Run applies every scheduled change that is due at the given time
+func (s *NewsScheduler) Run(now time.Time) {
+	published, err := s.newsRepo.PublishDueNews(now)
+	if err != nil {
+		log.Printf("News scheduler: failed to publish scheduled posts: %v", err)
+	}
+	for _, news := range published {
+		log.Printf("News scheduler: published post %d (%s)", news.ID, news.Slug)
+	}
+
+	unpublished, err := s.newsRepo.UnpublishDueNews(now)
+	if err != nil {
+		log.Printf("News scheduler: failed to unpublish expired posts: %v", err)
+	}
+	for _, news := range unpublished {
+		log.Printf("News scheduler: unpublished post %d (%s)", news.ID, news.Slug)
+	}
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+)
+
+// pageViewBatchSize is the largest number of page views written in one insert
+const pageViewBatchSize = 500
+
+// PageViewRecorder writes page views to the database in batches from a single background
+// worker. Views wait in a bounded queue: when the database cannot keep up, new views are
+// dropped instead of piling up in memory or slowing down visitors.
+type PageViewRecorder struct {
+	pageViewRepo  repositories.PageViewRepository
+	queue         chan models.PageView
+	flushInterval time.Duration
+	dropped       atomic.Int64
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// NewPageViewRecorder creates a new PageViewRecorder holding up to queueSize unwritten views
+// and writing them every flushInterval
+func NewPageViewRecorder(pageViewRepo repositories.PageViewRepository, queueSize int, flushInterval time.Duration) *PageViewRecorder {
+	return &PageViewRecorder{
+		pageViewRepo:  pageViewRepo,
+		queue:         make(chan models.PageView, queueSize),
+		flushInterval: flushInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start runs the writer in the background
+func (r *PageViewRecorder) Start() {
+	go r.loop()
+	log.Printf("Page view recorder started (queue of %d, written every %s)", cap(r.queue), r.flushInterval)
+}
+
+// Stop writes the queued views and ends the background worker
+func (r *PageViewRecorder) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// Record queues a page view without blocking. It reports false when the queue is full and the
+// view was dropped.
+func (r *PageViewRecorder) Record(pageView models.PageView) bool {
+	select {
+	case r.queue <- pageView:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+// loop collects queued views and writes them when a batch is full or the flush interval passed
+func (r *PageViewRecorder) loop() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.PageView, 0, pageViewBatchSize)
+	for {
+		select {
+		case pageView := <-r.queue:
+			batch = append(batch, pageView)
+			if len(batch) >= pageViewBatchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-r.stop:
+			for {
+				select {
+				case pageView := <-r.queue:
+					batch = append(batch, pageView)
+					if len(batch) >= pageViewBatchSize {
+						batch = r.flush(batch)
+					}
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch and returns it emptied for reuse. A batch that fails to be written is
+// discarded: page views are statistics, not worth retrying at the cost of memory.
+func (r *PageViewRecorder) flush(batch []models.PageView) []models.PageView {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		log.Printf("Page view recorder: dropped %d page views, the queue was full", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := r.pageViewRepo.CreateBatch(batch); err != nil {
+		log.Printf("Page view recorder: failed to write %d page views: %v", len(batch), err)
+	}
+	return batch[:0]
+}
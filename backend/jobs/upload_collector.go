@@ -0,0 +1,284 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
+	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
+)
+
+var (
+	// ErrCollectorBusy is returned when a collection is requested while another one is running
+	ErrCollectorBusy = errors.New("upload garbage collection is already running")
+	// ErrRestoreConflict is returned when a file cannot leave the quarantine because a file
+	// with the same name was uploaded since
+	ErrRestoreConflict = errors.New("a file with the same name exists outside the quarantine")
+)
+
+// UploadGCFile is a file handled by a garbage collection run
+type UploadGCFile struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// UploadGCReport describes what a garbage collection run did, or would do for a dry run
+type UploadGCReport struct {
+	DryRun           bool           `json:"dry_run"`
+	StartedAt        time.Time      `json:"started_at"`
+	GracePeriod      string         `json:"grace_period"`
+	QuarantinePeriod string         `json:"quarantine_period"`
+	Scanned          int            `json:"scanned"`     // Files outside the quarantine
+	Referenced       int            `json:"referenced"`  // Files used by content
+	TooRecent        int            `json:"too_recent"`  // Unused files still within the grace period
+	Quarantined      []UploadGCFile `json:"quarantined"` // Unused files moved to the quarantine
+	Restored         []UploadGCFile `json:"restored"`    // Quarantined files used again, moved back
+	Deleted          []UploadGCFile `json:"deleted"`     // Quarantined files deleted for good
+	Errors           []string       `json:"errors,omitempty"`
+}
+
+// UploadCollector finds uploads that no content links to anymore, such as the previous photo
+// of an official. An unused file older than the grace period is first moved to the quarantine
+// (where it is no longer served), and deleted once it has stayed there for the quarantine
+// period. A quarantined file that content links to again is moved back.
+type UploadCollector struct {
+	mediaRepo        repositories.MediaRepository
+	store            storage.Storage
+	interval         time.Duration
+	gracePeriod      time.Duration
+	quarantinePeriod time.Duration
+	running          sync.Mutex
+	stop             chan struct{}
+}
+
+// NewUploadCollector creates a new UploadCollector that runs every interval
+func NewUploadCollector(mediaRepo repositories.MediaRepository, store storage.Storage, interval, gracePeriod, quarantinePeriod time.Duration) *UploadCollector {
+	return &UploadCollector{
+		mediaRepo:        mediaRepo,
+		store:            store,
+		interval:         interval,
+		gracePeriod:      gracePeriod,
+		quarantinePeriod: quarantinePeriod,
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start runs the collector in the background, beginning with an immediate run
+func (c *UploadCollector) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.runAndLog(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				c.runAndLog(now)
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Upload garbage collector started (every %s, grace period %s, quarantine %s)", c.interval, c.gracePeriod, c.quarantinePeriod)
+}
+
+// Stop ends the background loop
+func (c *UploadCollector) Stop() {
+	close(c.stop)
+}
+
+// runAndLog runs a collection and logs every change it made
+func (c *UploadCollector) runAndLog(now time.Time) {
+	report, err := c.Run(now, false)
+	if err != nil {
+		log.Printf("Upload garbage collector: %v", err)
+		return
+	}
+	for _, file := range report.Quarantined {
+		log.Printf("Upload garbage collector: quarantined unused file %s", file.Key)
+	}
+	for _, file := range report.Restored {
+		log.Printf("Upload garbage collector: restored %s, it is used again", file.Key)
+	}
+	for _, file := range report.Deleted {
+		log.Printf("Upload garbage collector: deleted %s", file.Key)
+	}
+	for _, message := range report.Errors {
+		log.Printf("Upload garbage collector: %s", message)
+	}
+}
+
+// Run compares the stored files with the content at the given time. A dry run only reports
+// what would be done.
+func (c *UploadCollector) Run(now time.Time, dryRun bool) (*UploadGCReport, error) {
+	if !c.running.TryLock() {
+		return nil, ErrCollectorBusy
+	}
+	defer c.running.Unlock()
+
+	report := &UploadGCReport{
+		DryRun:           dryRun,
+		StartedAt:        now,
+		GracePeriod:      c.gracePeriod.String(),
+		QuarantinePeriod: c.quarantinePeriod.String(),
+		Quarantined:      []UploadGCFile{},
+		Restored:         []UploadGCFile{},
+		Deleted:          []UploadGCFile{},
+	}
+
+	// Files are kept together with their variants: a link to any of them keeps them all
+	referenced, err := c.referencedBases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content references: %w", err)
+	}
+	objects, err := c.store.List("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+
+	live := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		live[object.Key] = true
+	}
+
+	variants := config.ImageVariantNames()
+	for _, object := range objects {
+		file := UploadGCFile{Key: object.Key, Size: object.Size, LastModified: object.LastModified}
+
+		if key, quarantined := strings.CutPrefix(object.Key, storage.QuarantinePrefix); quarantined {
+			// A file uploaded again under the same name replaces the quarantined one
+			switch {
+			case referenced[utils.VariantBase(key, variants...)] && !live[key]:
+				file.Key = key
+				report.Restored = append(report.Restored, file)
+				if !dryRun {
+					c.restore(report, key)
+				}
+			case now.Sub(object.LastModified) >= c.quarantinePeriod:
+				report.Deleted = append(report.Deleted, file)
+				if !dryRun {
+					c.delete(report, key, !live[key])
+				}
+			}
+			continue
+		}
+
+		report.Scanned++
+		switch {
+		case referenced[utils.VariantBase(object.Key, variants...)]:
+			report.Referenced++
+		case now.Sub(object.LastModified) < c.gracePeriod:
+			report.TooRecent++
+		default:
+			report.Quarantined = append(report.Quarantined, file)
+			if !dryRun {
+				c.quarantine(report, object.Key, now)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// Restore moves a quarantined file and its variants back, for a file that turns out to be
+// needed before content links to it again
+func (c *UploadCollector) Restore(key string) error {
+	c.running.Lock()
+	defer c.running.Unlock()
+
+	if file, _, err := c.store.Get(key); err == nil {
+		file.Close()
+		return ErrRestoreConflict
+	}
+	file, _, err := c.store.Get(storage.QuarantinePrefix + key)
+	if err != nil {
+		return err
+	}
+	file.Close()
+
+	objects, err := c.store.List(storage.QuarantinePrefix)
+	if err != nil {
+		return err
+	}
+
+	variants := config.ImageVariantNames()
+	base := utils.VariantBase(key, variants...)
+	for _, object := range objects {
+		original := strings.TrimPrefix(object.Key, storage.QuarantinePrefix)
+		if original != key && utils.VariantBase(original, variants...) != base {
+			continue
+		}
+		if err := storage.Move(c.store, object.Key, original); err != nil {
+			return err
+		}
+		if err := c.mediaRepo.SetQuarantined(original, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencedBases returns the variant base name of every file linked from the content. A
+// setting holding just a file name counts too: only the bases of stored files are looked up,
+// so other values do no harm.
+func (c *UploadCollector) referencedBases() (map[string]bool, error) {
+	values, err := c.mediaRepo.GetReferenceValues()
+	if err != nil {
+		return nil, err
+	}
+
+	variants := config.ImageVariantNames()
+	bases := make(map[string]bool)
+	for _, value := range values {
+		for _, key := range storage.KeysInText(c.store, value.Value) {
+			bases[utils.VariantBase(key, variants...)] = true
+		}
+		if key := strings.TrimSpace(value.Value); value.BareKey && storage.ValidKey(key) {
+			bases[utils.VariantBase(key, variants...)] = true
+		}
+	}
+	return bases, nil
+}
+
+// quarantine moves an unused file to the quarantine, where it is no longer served
+func (c *UploadCollector) quarantine(report *UploadGCReport, key string, now time.Time) {
+	if err := storage.Move(c.store, key, storage.QuarantinePrefix+key); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to quarantine %s: %v", key, err))
+		return
+	}
+	if err := c.mediaRepo.SetQuarantined(key, &now); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to mark %s as quarantined: %v", key, err))
+	}
+}
+
+// restore moves a quarantined file back to its original key
+func (c *UploadCollector) restore(report *UploadGCReport, key string) {
+	if err := storage.Move(c.store, storage.QuarantinePrefix+key, key); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to restore %s: %v", key, err))
+		return
+	}
+	if err := c.mediaRepo.SetQuarantined(key, nil); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to mark %s as restored: %v", key, err))
+	}
+}
+
+// delete removes a quarantined file, and its media library record unless a file was uploaded again under its name
+func (c *UploadCollector) delete(report *UploadGCReport, key string, removeRecord bool) {
+	if err := c.store.Delete(storage.QuarantinePrefix + key); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to delete %s: %v", key, err))
+		return
+	}
+	if !removeRecord {
+		return
+	}
+	if err := c.mediaRepo.DeleteByKey(key); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to remove %s from the media library: %v", key, err))
+	}
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogSender prints messages to the application log instead of sending them.
+// Useful in development: reset links appear in the backend output.
+type LogSender struct {
+	from       string
+	redactBody bool
+}
+
+// NewLogSender creates a new LogSender
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+// NewRedactedLogSender creates a LogSender that leaves out the message body, so secrets such
+// as reset links never end up in the logs
+func NewRedactedLogSender(from string) *LogSender {
+	return &LogSender{from: from, redactBody: true}
+}
+
+// Send logs a message
+func (s *LogSender) Send(msg Message) error {
+	if s.redactBody {
+		log.Printf("MAIL (not sent) from=%s to=%s subject=%q (body not logged)", s.from, msg.To, msg.Subject)
+		return nil
+	}
+	log.Printf("MAIL (not sent) from=%s to=%s subject=%q\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender writes every message as an .eml file into a directory
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender creates a new FileSender
+func NewFileSender(dir string, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+// Send writes a message to disk
+func (s *FileSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	filename := filepath.Join(s.dir, fmt.Sprintf("%s.eml", time.Now().Format("20060102-150405.000000000")))
+	if err := os.WriteFile(filename, buildMIME(s.from, msg), 0644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
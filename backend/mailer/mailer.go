@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages. Implementations are selected with the MAIL_DRIVER
+// environment variable so local development does not need a real mail server.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewSenderFromEnv creates the Sender configured by MAIL_DRIVER:
+//   - "smtp": deliver through SMTP_HOST/SMTP_PORT (see NewSMTPSender)
+//   - "file": write each message as an .eml file into MAIL_FILE_DIR (default "tmp/mail")
+//   - "log" (default): print the message to the application log. With GIN_MODE=release only the
+//     recipient and subject are logged, as the body holds live reset links.
+func NewSenderFromEnv() (Sender, error) {
+	driver := strings.ToLower(os.Getenv("MAIL_DRIVER"))
+	switch driver {
+	case "smtp":
+		return NewSMTPSender(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			mailFrom(),
+		)
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return NewFileSender(dir, mailFrom()), nil
+	case "", "log":
+		if os.Getenv("GIN_MODE") == "release" {
+			log.Printf("WARNING: MAIL_DRIVER=%q in release mode: no email is delivered, so password reset links never reach users. Set MAIL_DRIVER=smtp.", driver)
+			return NewRedactedLogSender(mailFrom()), nil
+		}
+		return NewLogSender(mailFrom()), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q (expected smtp, file or log)", driver)
+	}
+}
+
+func mailFrom() string {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	return from
+}
+
+// buildMIME renders a message as an RFC 5322 text/plain email
+func buildMIME(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPSender delivers messages through an SMTP server.
+// Port 465 uses implicit TLS; other ports use STARTTLS when the server offers it.
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender creates a new SMTPSender
+func NewSMTPSender(host, port, username, password, from string) (*SMTPSender, error) {
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+	}
+	if port == "" {
+		port = "587"
+	}
+	return &SMTPSender{host: host, port: port, username: username, password: password, from: from}, nil
+}
+
+// Send delivers a message
+func (s *SMTPSender) Send(msg Message) error {
+	addr := net.JoinHostPort(s.host, s.port)
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	if s.port != "465" {
+		if err := smtp.SendMail(addr, auth, s.from, []string{msg.To}, buildMIME(s.from, msg)); err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: s.host})
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	if _, err := w.Write(buildMIME(s.from, msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return client.Quit()
+}
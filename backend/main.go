@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/ihsanularifinm/sid-seirotan/backend/config"
 	"github.com/ihsanularifinm/sid-seirotan/backend/handlers"
+	"github.com/ihsanularifinm/sid-seirotan/backend/jobs"
+	"github.com/ihsanularifinm/sid-seirotan/backend/mailer"
 	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 	"github.com/ihsanularifinm/sid-seirotan/backend/routes"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
 	"github.com/joho/godotenv"
 )
 
@@ -21,6 +29,12 @@ func main() {
 		log.Println("Warning: .env file not found, using environment variables from system")
 	}
 
+	// "main migrate ..." manages the database schema instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
 	// Initialize database connection
 	db := config.InitDB()
 
@@ -34,27 +48,84 @@ func main() {
 	heroSliderRepo := repositories.NewGormHeroSliderRepository(db)
 	siteSettingsRepo := repositories.NewGormSiteSettingsRepository(db)
 	pageViewRepo := repositories.NewPageViewRepository(db)
+	sessionRepo := repositories.NewGormSessionRepository(db)
+	loginAttemptRepo := repositories.NewGormLoginAttemptRepository(db)
+	recoveryCodeRepo := repositories.NewGormRecoveryCodeRepository(db)
+	passwordResetRepo := repositories.NewGormPasswordResetRepository(db)
+	roleRepo := repositories.NewGormRoleRepository(db)
+	categoryRepo := repositories.NewGormCategoryRepository(db)
+	tagRepo := repositories.NewGormTagRepository(db)
+	searchRepo := repositories.NewGormSearchRepository(db)
+	sitemapRepo := repositories.NewGormSitemapRepository(db)
+	mediaRepo := repositories.NewGormMediaRepository(db)
+
+	// Seed the built-in roles before the users that reference them
+	roleRepo.SeedDefaultRoles(config.GetDefaultRoles())
 
 	// Seed the database with default users if they don't exist
 	userRepo.SeedSuperadmin()
 	userRepo.SeedDefaultAdmin()
 
+	// Index content that existed before full-text search was introduced
+	if err := searchRepo.RebuildMissing(); err != nil {
+		log.Printf("WARNING: Failed to build search index: %v", err)
+	}
+
+	// Publish and unpublish scheduled posts in the background
+	newsScheduler := jobs.NewNewsScheduler(newsRepo, config.NewsSchedulerInterval())
+	newsScheduler.Start()
+
+	// Write page views from the website in batches
+	pageViewRecorder := jobs.NewPageViewRecorder(pageViewRepo, config.PageViewQueueSize(), config.PageViewFlushInterval())
+	pageViewRecorder.Start()
+
+	// Summarize page views per day and prune old raw page views
+	analyticsRollup := jobs.NewAnalyticsRollup(pageViewRepo, siteSettingsRepo, config.AnalyticsRollupInterval(), config.AnalyticsRawRetention())
+	analyticsRollup.Start()
+
 	// Get SQL database connection for health checks
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatalf("Failed to get database connection: %v", err)
 	}
 
+	// Initialize mail sender (log, file or smtp, see MAIL_DRIVER)
+	mailSender, err := mailer.NewSenderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure mail sender: %v", err)
+	}
+
+	// Initialize upload storage (local disk or S3-compatible bucket, see STORAGE_DRIVER)
+	uploadStorage, err := storage.NewStorageFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure upload storage: %v", err)
+	}
+
+	// Quarantine and later delete uploads no content uses anymore
+	uploadCollector := jobs.NewUploadCollector(mediaRepo, uploadStorage, config.UploadGCInterval(), config.UploadGCGracePeriod(), config.UploadGCQuarantinePeriod())
+	uploadCollector.Start()
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userRepo)
-	newsHandler := handlers.NewNewsHandler(newsRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, sessionRepo, loginAttemptRepo, recoveryCodeRepo, siteSettingsRepo)
+	passwordResetHandler := handlers.NewPasswordResetHandler(userRepo, passwordResetRepo, sessionRepo, mailSender)
+	newsHandler := handlers.NewNewsHandler(newsRepo, roleRepo, categoryRepo, tagRepo)
 	villageOfficialHandler := handlers.NewVillageOfficialHandler(villageOfficialRepo)
 	potentialHandler := handlers.NewPotentialHandler(potentialRepo)
-	contactHandler := handlers.NewContactHandler(contactRepo)
+	contactHandler := handlers.NewContactHandler(contactRepo, userRepo)
 	serviceHandler := handlers.NewServiceHandler(serviceRepo)
-	userHandler := handlers.NewUserHandler(userRepo)
+	userHandler := handlers.NewUserHandler(userRepo, sessionRepo, loginAttemptRepo, roleRepo)
 	heroSliderHandler := handlers.NewHeroSliderHandler(heroSliderRepo)
 	siteSettingsHandler := handlers.NewSiteSettingsHandler(siteSettingsRepo)
+	roleHandler := handlers.NewRoleHandler(roleRepo)
+	categoryHandler := handlers.NewCategoryHandler(categoryRepo)
+	tagHandler := handlers.NewTagHandler(tagRepo)
+	searchHandler := handlers.NewSearchHandler(searchRepo)
+	feedHandler := handlers.NewFeedHandler(newsRepo, categoryRepo, siteSettingsRepo, mediaRepo, uploadStorage)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapRepo, siteSettingsRepo)
+	metaHandler := handlers.NewMetaHandler(newsRepo, serviceRepo, siteSettingsRepo)
+	uploadHandler := handlers.NewUploadHandler(uploadStorage, mediaRepo)
+	mediaHandler := handlers.NewMediaHandler(mediaRepo, uploadStorage, uploadCollector)
+	analyticsHandler := handlers.NewAnalyticsHandler(pageViewRecorder, pageViewRepo)
 	dashboardHandler := handlers.NewDashboardHandler(newsRepo, villageOfficialRepo, potentialRepo, serviceRepo, contactRepo, pageViewRepo, sqlDB)
 
 	router := gin.Default()
@@ -78,9 +149,10 @@ func main() {
 	adminRoutes := publicRoutes.Group("/admin")
 
 	// Setup routes
-	routes.SetupAuthRoutes(authRoutes, authHandler)
-	routes.SetupPublicRoutes(publicRoutes, newsHandler, villageOfficialHandler, potentialHandler, contactHandler, serviceHandler, heroSliderHandler, siteSettingsHandler, pageViewRepo)
-	routes.SetupAdminRoutes(adminRoutes, userHandler, newsHandler, villageOfficialHandler, serviceHandler, potentialHandler, contactHandler, heroSliderHandler, siteSettingsHandler, dashboardHandler)
+	routes.SetupSEORoutes(router, sitemapHandler)
+	routes.SetupAuthRoutes(authRoutes, authHandler, passwordResetHandler)
+	routes.SetupPublicRoutes(publicRoutes, newsHandler, villageOfficialHandler, potentialHandler, contactHandler, serviceHandler, heroSliderHandler, siteSettingsHandler, categoryHandler, tagHandler, searchHandler, feedHandler, metaHandler, uploadHandler, analyticsHandler)
+	routes.SetupAdminRoutes(adminRoutes, userHandler, newsHandler, villageOfficialHandler, serviceHandler, potentialHandler, contactHandler, heroSliderHandler, siteSettingsHandler, dashboardHandler, authHandler, passwordResetHandler, roleHandler, categoryHandler, tagHandler, searchHandler, uploadHandler, mediaHandler, sessionRepo, roleRepo)
 
 	// Run the server
 	port := os.Getenv("PORT")
@@ -93,8 +165,35 @@ func main() {
 		log.Printf("Registered route: %s %s", route.Method, route.Path)
 	}
 
-	log.Printf("Server running on :%s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// Stop on SIGINT or SIGTERM (e.g. a deploy) without losing the work still queued
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		log.Printf("Server running on :%s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	// Let requests in flight finish first, so the page views they queue are written below.
+	// The timeout stays under the 10 seconds Docker waits before killing the process.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
 	}
-}
\ No newline at end of file
+
+	newsScheduler.Stop()
+	analyticsRollup.Stop()
+	uploadCollector.Stop()
+	pageViewRecorder.Stop()
+	log.Println("Server stopped")
+}
+
+// shutdownTimeout is how long requests in flight may take to finish once the server is asked to stop
+const shutdownTimeout = 5 * time.Second
\ No newline at end of file
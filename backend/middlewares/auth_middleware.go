@@ -8,11 +8,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 	"github.com/ihsanularifinm/sid-seirotan/backend/utils"
 )
 
-// AuthMiddleware validates JWT tokens for protected routes
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens for protected routes.
+// Besides the signature and expiry, the session the token was issued for must
+// still be active, so revoking a session locks the user out immediately.
+func AuthMiddleware(sessionRepo repositories.SessionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -45,39 +48,57 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("userID", claims.UserID)     // Set user ID
-		c.Set("username", claims.Username) // Set username
-		c.Set("userRole", claims.Role)     // Set role
+		// Tokens issued before sessions existed carry no session ID and are rejected
+		if claims.SessionID == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		active, err := sessionRepo.IsSessionActive(claims.SessionID, claims.UserID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to verify session", err)
+			c.Abort()
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked or expired"})
+			return
+		}
+
+		c.Set("userID", claims.UserID)       // Set user ID
+		c.Set("username", claims.Username)   // Set username
+		c.Set("userRole", claims.Role)       // Set role
+		c.Set("sessionID", claims.SessionID) // Set session ID
 
 		c.Next()
 	}
 }
 
-// RoleMiddleware checks if the authenticated user has one of the required roles
+// RequirePermission checks if the role of the authenticated user grants at least one of
+// the given permissions. Permissions are looked up on every request, so changes to a
+// role apply immediately without a new login.
 // NOTE: This middleware assumes AuthMiddleware has already been called
-func RoleMiddleware(requiredRoles ...string) gin.HandlerFunc {
+func RequirePermission(roleRepo repositories.RoleRepository, permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get role from context, set by AuthMiddleware
 		role, exists := c.Get("userRole")
 		if !exists {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User role not found in token claims"})
 			return
 		}
 
-		userRole := role.(string)
-		isAllowed := false
-		for _, requiredRole := range requiredRoles {
-			if userRole == requiredRole {
-				isAllowed = true
-				break
+		for _, permission := range permissions {
+			allowed, err := roleRepo.RoleHasPermission(role.(string), permission)
+			if err != nil {
+				utils.RespondError(c, http.StatusInternalServerError, "Failed to verify permissions", err)
+				c.Abort()
+				return
+			}
+			if allowed {
+				c.Next()
+				return
 			}
 		}
 
-		if !isAllowed {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
-			return
-		}
-
-		c.Next()
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/storage"
+)
+
+// UploadHeadersMiddleware sets safe headers on files served from the uploads directory:
+// browsers must not guess the content type, anything but images and videos is downloaded
+// instead of displayed, and SVG files cannot run scripts even when opened directly
+func UploadHeadersMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filename := path.Base(c.Request.URL.Path)
+		ext := strings.ToLower(path.Ext(filename))
+
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Content-Disposition", storage.ContentDisposition(filename))
+
+		if ext == ".svg" {
+			c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:; sandbox")
+		}
+
+		c.Next()
+	}
+}
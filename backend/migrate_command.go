@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
+	"github.com/ihsanularifinm/sid-seirotan/backend/db/migrations"
+)
+
+// migrationsDir is where "migrate create" writes new files, relative to the backend directory
+const migrationsDir = "db/migrations"
+
+const migrateUsage = `Usage: main migrate <command>
+
+Commands:
+  up              Apply all pending migrations
+  down [N]        Revert the last N applied migrations (default 1)
+  status          List migrations and whether they are applied
+  create <name>   Create empty up/down files in ` + migrationsDir
+
+// runMigrateCommand handles "main migrate ..." and exits
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	// Creating files needs no database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("migrate create: a migration name is required")
+		}
+		up, down, err := migrations.Create(migrationsDir, args[1])
+		if err != nil {
+			log.Fatalf("migrate create: %v", err)
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return
+	}
+
+	db := config.ConnectDB()
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database connection: %v", err)
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("Applied %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("migrate up: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("migrate down: invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("migrate down: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("migrate status: %v", err)
+		}
+		for _, status := range statuses {
+			switch {
+			case status.Missing:
+				fmt.Printf("%06d  applied %s  (not in this binary)\n", status.Version, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			case status.AppliedAt != nil:
+				fmt.Printf("%06d_%s  applied %s\n", status.Version, status.Name, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			default:
+				fmt.Printf("%06d_%s  pending\n", status.Version, status.Name)
+			}
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
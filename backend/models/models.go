@@ -17,6 +17,8 @@ const (
 type NewsStatus string
 const (
 	NewsStatusDraft     NewsStatus = "draft"
+	NewsStatusInReview  NewsStatus = "in_review"
+	NewsStatusScheduled NewsStatus = "scheduled" // Published automatically once published_at is reached
 	NewsStatusPublished NewsStatus = "published"
 	NewsStatusArchived  NewsStatus = "archived"
 )
@@ -35,17 +37,32 @@ const (
 	MediaTypeVideo MediaType = "video"
 )
 
+type ContactStatus string
+const (
+	ContactStatusNew        ContactStatus = "new"
+	ContactStatusInProgress ContactStatus = "in_progress"
+	ContactStatusResolved   ContactStatus = "resolved"
+	ContactStatusSpam       ContactStatus = "spam"
+)
+
 // --- TABLES ---
 // User represents the users table (for admin login)
 type User struct {
-	ID           uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
-	FullName     string         `gorm:"type:varchar(255);not null" json:"full_name"`
-	Username     string         `gorm:"type:varchar(100);unique;not null" json:"username"`
-	PasswordHash string         `gorm:"type:varchar(255);not null" json:"-"` // Exclude from JSON output
-	Role         UserRole       `gorm:"type:user_role;not null;default:'admin'" json:"role"`
-	CreatedAt    time.Time      `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt    time.Time      `gorm:"not null;default:now()" json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID                  uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	FullName            string         `gorm:"type:varchar(255);not null" json:"full_name"`
+	Username            string         `gorm:"type:varchar(100);unique;not null" json:"username"`
+	Email               *string        `gorm:"type:varchar(255);uniqueIndex" json:"email,omitempty"` // Used for password reset links
+	PasswordHash        string         `gorm:"type:varchar(255);not null" json:"-"`                  // Exclude from JSON output
+	Role                UserRole       `gorm:"type:varchar(50);not null;default:'admin';index" json:"role"` // Name of a row in roles
+	FailedLoginAttempts int            `gorm:"not null;default:0" json:"failed_login_attempts"` // Consecutive failures, reset on success
+	LastFailedLoginAt   *time.Time     `json:"last_failed_login_at,omitempty"`
+	LockedUntil         *time.Time     `json:"locked_until,omitempty"` // Persisted so lockouts survive a restart
+	TwoFactorEnabled    bool           `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TwoFactorSecret     *string        `gorm:"type:text" json:"-"`          // AES-GCM encrypted TOTP secret
+	TwoFactorLastStep   int64          `gorm:"not null;default:0" json:"-"` // Last accepted TOTP time step, prevents code reuse
+	CreatedAt           time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt           time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 // News represents the news table
@@ -57,8 +74,13 @@ type News struct {
 	FeaturedImageURL *string       `gorm:"type:varchar(255)" json:"featured_image_url,omitempty"`
 	Status          NewsStatus     `gorm:"type:news_status;not null;default:'draft'" json:"status"`
 	PublishedAt     *time.Time     `json:"published_at,omitempty"`
+	UnpublishAt     *time.Time     `gorm:"index" json:"unpublish_at,omitempty"` // Archived automatically once reached
 	AuthorID        uint64         `gorm:"not null" json:"author_id"`
 	Author          User           `gorm:"foreignKey:AuthorID" json:"author,omitempty"` // GORM association
+	CategoryID      *uint64        `gorm:"index" json:"category_id,omitempty"`
+	Category        *Category      `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"category,omitempty"`
+	Tags            []Tag          `gorm:"many2many:news_tags;constraint:OnDelete:CASCADE" json:"tags"`
+	SearchVector    string         `gorm:"type:tsvector;index:,type:gin;->:false;<-:false" json:"-"` // Maintained by the repository for full-text search
 	CreatedAt       time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -85,6 +107,7 @@ type Service struct {
 	ServiceName  string         `gorm:"type:varchar(255);not null" json:"service_name"`
 	Description  *string        `gorm:"type:text" json:"description,omitempty"`
 	Requirements *string        `gorm:"type:text" json:"requirements,omitempty"`
+	SearchVector string         `gorm:"type:tsvector;index:,type:gin;->:false;<-:false" json:"-"` // Maintained by the repository for full-text search
 	CreatedAt    time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -97,6 +120,7 @@ type Potential struct {
 	Description   *string        `gorm:"type:text" json:"description,omitempty"`
 	CoverImageURL *string        `gorm:"type:varchar(255)" json:"cover_image_url,omitempty"`
 	Type          PotentialType  `gorm:"type:potential_type;not null;default:'other'" json:"type"`
+	SearchVector  string         `gorm:"type:tsvector;index:,type:gin;->:false;<-:false" json:"-"` // Maintained by the repository for full-text search
 	CreatedAt     time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -123,6 +147,24 @@ type Contact struct {
 	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Inbox handling by the village staff
+	IsRead       bool          `gorm:"not null;default:false;index" json:"is_read"`
+	ReadAt       *time.Time    `json:"read_at,omitempty"`
+	Status       ContactStatus `gorm:"type:varchar(20);not null;default:'new';index" json:"status"`
+	AssignedToID *uint64       `gorm:"index" json:"assigned_to_id,omitempty"`
+	AssignedTo   *User         `gorm:"foreignKey:AssignedToID" json:"assigned_to,omitempty"`
+	Notes        []ContactNote `gorm:"foreignKey:ContactID" json:"notes,omitempty"` // Internal, never shown to the sender
+}
+
+// ContactNote represents the contact_notes table: internal notes of the staff on a contact message
+type ContactNote struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ContactID uint64    `gorm:"not null;index" json:"contact_id"`
+	AuthorID  *uint64   `json:"author_id,omitempty"` // Nil once the author is deleted
+	Author    *User     `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Note      string    `gorm:"type:text;not null" json:"note"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
 }
 
 // HeroSlider represents the hero_sliders table
@@ -151,4 +193,154 @@ type PageView struct {
 	Referer   *string   `gorm:"type:varchar(500)" json:"referer,omitempty"`
 	ViewedAt  time.Time `gorm:"not null;default:now()" json:"viewed_at"`
 	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Parsed from the referrer, user agent and utm_* parameters when the view is recorded
+	ReferrerDomain *string `gorm:"type:varchar(255)" json:"referrer_domain,omitempty"`
+	Source         *string `gorm:"type:varchar(20)" json:"source,omitempty"` // direct, search, social, whatsapp, campaign, referral or internal
+	Device         *string `gorm:"type:varchar(20)" json:"device,omitempty"` // desktop, mobile or tablet
+	Browser        *string `gorm:"type:varchar(50)" json:"browser,omitempty"`
+	OS             *string `gorm:"column:os;type:varchar(50)" json:"os,omitempty"`
+	UTMSource      *string `gorm:"column:utm_source;type:varchar(255)" json:"utm_source,omitempty"`
+	UTMMedium      *string `gorm:"column:utm_medium;type:varchar(255)" json:"utm_medium,omitempty"`
+	UTMCampaign    *string `gorm:"column:utm_campaign;type:varchar(255)" json:"utm_campaign,omitempty"`
+	UTMTerm        *string `gorm:"column:utm_term;type:varchar(255)" json:"utm_term,omitempty"`
+	UTMContent     *string `gorm:"column:utm_content;type:varchar(255)" json:"utm_content,omitempty"`
+}
+
+// UserSession represents the user_sessions table (refresh tokens for admin logins)
+type UserSession struct {
+	ID                uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID            uint64     `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"type:varchar(64);unique;not null" json:"-"` // SHA-256 of the refresh token, never the token itself
+	PreviousTokenHash *string    `gorm:"type:varchar(64);index" json:"-"`           // Hash of the token it replaced, to detect reuse
+	UserAgent         *string    `gorm:"type:text" json:"user_agent,omitempty"`
+	IPAddress         *string    `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt        time.Time  `gorm:"not null;default:now()" json:"last_used_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// LoginAttempt represents the login_attempts table (history of admin login attempts)
+type LoginAttempt struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    *uint64   `gorm:"index" json:"user_id,omitempty"` // Nil when the username does not exist
+	Username  string    `gorm:"type:varchar(100);not null;index" json:"username"`
+	IPAddress string    `gorm:"type:varchar(45);not null" json:"ip_address"`
+	UserAgent *string   `gorm:"type:text" json:"user_agent,omitempty"`
+	Success   bool      `gorm:"not null;default:false" json:"success"`
+	Reason    *string   `gorm:"type:varchar(50)" json:"reason,omitempty"` // e.g. invalid_password, locked, throttled
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+// UserRecoveryCode represents the user_recovery_codes table (one-time 2FA recovery codes)
+type UserRecoveryCode struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"` // HMAC-SHA256 of the normalized code, keyed with TWO_FACTOR_ENCRYPTION_KEY
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// PasswordResetToken represents the password_reset_tokens table (single-use reset links)
+type PasswordResetToken struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint64     `gorm:"not null;index" json:"user_id"`
+	TokenHash   string     `gorm:"type:varchar(64);unique;not null" json:"-"` // SHA-256 of the token sent in the link
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	CreatedByID *uint64    `json:"created_by_id,omitempty"` // Set when a superadmin created the link
+	CreatedAt   time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// Role represents the roles table. A role is a named set of permissions; users reference it by name.
+type Role struct {
+	ID          uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string           `gorm:"type:varchar(50);unique;not null" json:"name"`
+	Description *string          `gorm:"type:text" json:"description,omitempty"`
+	IsSystem    bool             `gorm:"not null;default:false" json:"is_system"` // Built-in roles cannot be deleted
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"-"`
+	CreatedAt   time.Time        `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// RolePermission represents the role_permissions table. Revoked permissions are soft deleted,
+// which keeps the seeder from granting them again.
+type RolePermission struct {
+	ID         uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID     uint64         `gorm:"not null;uniqueIndex:idx_role_permission" json:"role_id"`
+	Permission string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_role_permission" json:"permission"`
+	CreatedAt  time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// NewsStatusHistory represents the news_status_histories table (who moved a post to which status, and when)
+type NewsStatusHistory struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	NewsID      uint64     `gorm:"not null;index" json:"news_id"`
+	FromStatus  NewsStatus `gorm:"type:varchar(20)" json:"from_status,omitempty"` // Empty when the post was created
+	ToStatus    NewsStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	Action      string     `gorm:"type:varchar(20);not null" json:"action"` // create, update, submit, approve, reject, schedule
+	Note        *string    `gorm:"type:text" json:"note,omitempty"`          // Reviewer note
+	ChangedByID *uint64    `json:"changed_by_id,omitempty"` // Nil when the scheduler made the change
+	ChangedBy   *User      `gorm:"foreignKey:ChangedByID" json:"changed_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// NewsRevision represents the news_revisions table. Every save of a post stores a full,
+// never modified snapshot so earlier versions can be compared and restored.
+type NewsRevision struct {
+	ID               uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	NewsID           uint64     `gorm:"not null;uniqueIndex:idx_news_revision" json:"news_id"`
+	RevisionNumber   int        `gorm:"not null;uniqueIndex:idx_news_revision" json:"revision_number"`
+	Title            string     `gorm:"type:varchar(255);not null" json:"title"`
+	Slug             string     `gorm:"type:varchar(255);not null" json:"slug"`
+	Content          string     `gorm:"type:text;not null" json:"content,omitempty"`
+	FeaturedImageURL *string    `gorm:"type:varchar(255)" json:"featured_image_url,omitempty"`
+	Status           NewsStatus `gorm:"type:varchar(20);not null" json:"status"`
+	PublishedAt      *time.Time `json:"published_at,omitempty"`
+	RestoredFrom     *int       `json:"restored_from,omitempty"` // Revision number this one was restored from
+	EditorID         uint64     `gorm:"not null" json:"editor_id"`
+	Editor           User       `gorm:"foreignKey:EditorID" json:"editor,omitempty"`
+	CreatedAt        time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// Category represents the categories table (each post belongs to at most one category)
+type Category struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Slug        string    `gorm:"type:varchar(120);unique;not null" json:"slug"`
+	Description *string   `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// Tag represents the tags table (posts and tags are linked through news_tags)
+type Tag struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Slug      string    `gorm:"type:varchar(120);unique;not null" json:"slug"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// Media represents the media table (one row per uploaded file; resized variants belong to their original)
+type Media struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	StorageKey    string     `gorm:"type:varchar(255);unique;not null" json:"storage_key"` // Key of the file in the upload storage
+	URL           string     `gorm:"type:varchar(500);not null" json:"url"`
+	ThumbnailURL  *string    `gorm:"type:varchar(500)" json:"thumbnail_url,omitempty"` // Smallest variant, for images only
+	OriginalName  string     `gorm:"type:varchar(255);not null" json:"original_name"`  // File name on the uploader's device
+	UploadType    string     `gorm:"type:varchar(30);not null;default:'';index" json:"upload_type"`
+	MIMEType      string     `gorm:"type:varchar(100);not null;index" json:"mime_type"`
+	Size          int64      `gorm:"not null" json:"size"` // Bytes of the original, variants excluded
+	Width         *int       `json:"width,omitempty"`
+	Height        *int       `json:"height,omitempty"`
+	AltText       *string    `gorm:"type:varchar(255)" json:"alt_text,omitempty"`
+	QuarantinedAt *time.Time `gorm:"index" json:"quarantined_at,omitempty"` // Set while the unused file waits for deletion
+	UploadedByID  *uint64    `gorm:"index" json:"uploaded_by_id,omitempty"`
+	UploadedBy    *User      `gorm:"foreignKey:UploadedByID;constraint:OnDelete:SET NULL" json:"uploaded_by,omitempty"`
+	CreatedAt     time.Time  `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"not null;default:now()" json:"updated_at"`
 }
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// CategoryWithCount is a category together with the number of its published posts
+type CategoryWithCount struct {
+	models.Category
+	PostCount int64 `json:"post_count"`
+}
+
+// CategoryRepository defines the interface for category data operations
+type CategoryRepository interface {
+	GetAll() ([]models.Category, error)
+	GetAllWithPostCount() ([]CategoryWithCount, error)
+	GetByID(id uint64) (*models.Category, error)
+	GetBySlug(slug string) (*models.Category, error)
+	Create(category *models.Category) error
+	Update(category *models.Category) error
+	Delete(id uint64) error
+	IsSlugExist(slug string, currentID uint64) (bool, error)
+}
+
+// GormCategoryRepository implements CategoryRepository using GORM
+type GormCategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCategoryRepository creates a new GormCategoryRepository
+func NewGormCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &GormCategoryRepository{db: db}
+}
+
+// GetAll retrieves all categories ordered by name
+func (r *GormCategoryRepository) GetAll() ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Order("name ASC").Find(&categories).Error
+	return categories, err
+}
+
+// GetAllWithPostCount retrieves all categories with the number of posts currently visible to the public
+func (r *GormCategoryRepository) GetAllWithPostCount() ([]CategoryWithCount, error) {
+	var categories []CategoryWithCount
+	now := time.Now()
+	err := r.db.Model(&models.Category{}).
+		Select("categories.*, COUNT(news.id) AS post_count").
+		Joins("LEFT JOIN news ON news.category_id = categories.id AND news.deleted_at IS NULL AND news.status = ? AND news.published_at <= ? AND (news.unpublish_at IS NULL OR news.unpublish_at > ?)",
+			models.NewsStatusPublished, now, now).
+		Group("categories.id").
+		Order("categories.name ASC").
+		Scan(&categories).Error
+	return categories, err
+}
+
+// GetByID retrieves a category by its ID
+func (r *GormCategoryRepository) GetByID(id uint64) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetBySlug retrieves a category by its slug
+func (r *GormCategoryRepository) GetBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Create creates a new category
+func (r *GormCategoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+// Update updates an existing category
+func (r *GormCategoryRepository) Update(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+// Delete deletes a category. Its posts keep existing without a category.
+func (r *GormCategoryRepository) Delete(id uint64) error {
+	return r.db.Delete(&models.Category{}, id).Error
+}
+
+// IsSlugExist checks if a slug is already used by a different category
+func (r *GormCategoryRepository) IsSlugExist(slug string, currentID uint64) (bool, error) {
+	var count int64
+	query := r.db.Model(&models.Category{}).Where("slug = ?", slug)
+	if currentID != 0 {
+		query = query.Where("id != ?", currentID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
@@ -1,16 +1,36 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"gorm.io/gorm"
 )
 
+// ContactStatusAll is the status filter listing every contact message, spam included
+const ContactStatusAll = "all"
+
+// ContactFilter narrows down the contact inbox listing. Empty fields are ignored.
+type ContactFilter struct {
+	Query        string // Matches the name, email, subject and message
+	Status       string // A contact status or "all"; spam is hidden when empty
+	IsRead       *bool
+	AssignedToID *uint64
+	Unassigned   bool
+}
+
 // ContactRepository defines the interface for contact message data operations
 type ContactRepository interface {
 	CreateContact(contact *models.Contact) error
-	GetAllContacts() ([]models.Contact, error)
+	GetAll(page, limit int, filter ContactFilter) ([]models.Contact, int64, error)
+	GetByID(id uint64) (*models.Contact, error)
+	Update(contact *models.Contact) error
+	MarkRead(ids []uint64, read bool) (int64, error)
+	Delete(ids []uint64) (int64, error)
+	AddNote(note *models.ContactNote) error
 	Count() (int64, error)
 	CountUnread() (int64, error)
+	CountByStatus() (map[models.ContactStatus]int64, error)
 	GetRecent(limit int) ([]models.Contact, error)
 }
 
@@ -29,11 +49,88 @@ func (r *GormContactRepository) CreateContact(contact *models.Contact) error {
 	return r.db.Create(contact).Error
 }
 
-// GetAllContacts retrieves all contact messages
-func (r *GormContactRepository) GetAllContacts() ([]models.Contact, error) {
+// GetAll retrieves contact messages with pagination, newest first
+func (r *GormContactRepository) GetAll(page, limit int, filter ContactFilter) ([]models.Contact, int64, error) {
 	var contacts []models.Contact
-	err := r.db.Find(&contacts).Error
-	return contacts, err
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.Model(&models.Contact{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR subject ILIKE ? OR message ILIKE ?", pattern, pattern, pattern, pattern)
+	}
+	switch filter.Status {
+	case "":
+		query = query.Where("status <> ?", models.ContactStatusSpam)
+	case ContactStatusAll:
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.IsRead != nil {
+		query = query.Where("is_read = ?", *filter.IsRead)
+	}
+	if filter.Unassigned {
+		query = query.Where("assigned_to_id IS NULL")
+	} else if filter.AssignedToID != nil {
+		query = query.Where("assigned_to_id = ?", *filter.AssignedToID)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated data
+	if err := query.Preload("AssignedTo").Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&contacts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return contacts, total, nil
+}
+
+// GetByID retrieves a contact message with its assignee and notes, oldest note first
+func (r *GormContactRepository) GetByID(id uint64) (*models.Contact, error) {
+	var contact models.Contact
+	err := r.db.Preload("AssignedTo").
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		Preload("Notes.Author").
+		First(&contact, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// Update saves the read state, status and assignee of a contact message
+func (r *GormContactRepository) Update(contact *models.Contact) error {
+	return r.db.Model(contact).Select("IsRead", "ReadAt", "Status", "AssignedToID", "UpdatedAt").Updates(contact).Error
+}
+
+// MarkRead marks contact messages as read or unread and returns how many changed. Messages
+// already in that state keep their original read time.
+func (r *GormContactRepository) MarkRead(ids []uint64, read bool) (int64, error) {
+	var readAt *time.Time
+	if read {
+		now := time.Now()
+		readAt = &now
+	}
+	result := r.db.Model(&models.Contact{}).
+		Where("id IN ? AND is_read <> ?", ids, read).
+		Updates(map[string]interface{}{"is_read": read, "read_at": readAt, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// Delete soft-deletes contact messages and returns how many were deleted
+func (r *GormContactRepository) Delete(ids []uint64) (int64, error) {
+	result := r.db.Where("id IN ?", ids).Delete(&models.Contact{})
+	return result.RowsAffected, result.Error
+}
+
+// AddNote adds an internal note to a contact message
+func (r *GormContactRepository) AddNote(note *models.ContactNote) error {
+	return r.db.Omit("Author").Create(note).Error
 }
 
 // Count returns the total number of contact messages
@@ -43,16 +140,33 @@ func (r *GormContactRepository) Count() (int64, error) {
 	return count, err
 }
 
-// CountUnread returns the number of unread contact messages
-// Note: Currently returns 0 as is_read field is not implemented yet
+// CountUnread returns the number of unread contact messages, spam excluded
 func (r *GormContactRepository) CountUnread() (int64, error) {
-	// TODO: Implement when is_read field is added to Contact model
-	return 0, nil
+	var count int64
+	err := r.db.Model(&models.Contact{}).Where("is_read = ? AND status <> ?", false, models.ContactStatusSpam).Count(&count).Error
+	return count, err
+}
+
+// CountByStatus returns the number of contact messages in each status
+func (r *GormContactRepository) CountByStatus() (map[models.ContactStatus]int64, error) {
+	var rows []struct {
+		Status models.ContactStatus
+		Count  int64
+	}
+	if err := r.db.Model(&models.Contact{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.ContactStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
 }
 
-// GetRecent returns the most recent contact messages
+// GetRecent returns the most recent contact messages, spam excluded
 func (r *GormContactRepository) GetRecent(limit int) ([]models.Contact, error) {
 	var contacts []models.Contact
-	err := r.db.Order("created_at DESC").Limit(limit).Find(&contacts).Error
+	err := r.db.Where("status <> ?", models.ContactStatusSpam).Order("created_at DESC").Limit(limit).Find(&contacts).Error
 	return contacts, err
 }
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository defines the interface for login attempt history operations
+type LoginAttemptRepository interface {
+	Create(attempt *models.LoginAttempt) error
+	GetByUser(userID uint64, limit int) ([]models.LoginAttempt, error)
+}
+
+// GormLoginAttemptRepository implements LoginAttemptRepository using GORM
+type GormLoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLoginAttemptRepository creates a new GormLoginAttemptRepository
+func NewGormLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &GormLoginAttemptRepository{db: db}
+}
+
+// Create stores a login attempt
+func (r *GormLoginAttemptRepository) Create(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// GetByUser returns the most recent login attempts for a user
+func (r *GormLoginAttemptRepository) GetByUser(userID uint64, limit int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&attempts).Error
+	return attempts, err
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kinds of files the media library can be filtered on
+const (
+	MediaKindImage    = "image"
+	MediaKindVideo    = "video"
+	MediaKindDocument = "document"
+)
+
+// MediaFilter narrows down the media library listing. Empty fields are ignored.
+type MediaFilter struct {
+	Query      string // Matches the storage key, the original file name and the alt text
+	Kind       string // image, video or document
+	UploadType string
+}
+
+// MediaReference is a piece of content that uses an uploaded file
+type MediaReference struct {
+	Type    string `json:"type"` // news, news_revision, hero_slider, village_official, potential or setting
+	ID      uint64 `json:"id"`
+	Title   string `json:"title"`   // Title or name of the content, key of a setting
+	Field   string `json:"field"`   // Column holding the URL
+	Deleted bool   `json:"deleted"` // Soft-deleted content, which can still be restored
+}
+
+// MediaReferenceSource is a column that may hold the URL of an uploaded file, either as its
+// whole value or embedded in rich text
+type MediaReferenceSource struct {
+	Type        string
+	Table       string
+	TitleColumn string
+	Column      string
+	SoftDelete  bool // The table has a deleted_at column
+	BareKey     bool // The whole value may also be just the key of a file, without a URL
+}
+
+// MediaReferenceValue is the content of a column that may link to uploaded files
+type MediaReferenceValue struct {
+	Value   string
+	BareKey bool // From a column that may hold just the key of a file
+}
+
+// MediaReferenceSources lists every column where content points to uploaded files. Both the
+// media library and the garbage collector count a file as used when any row refers to it,
+// soft-deleted rows and old revisions of posts included: restoring them brings the file back.
+// Settings such as site_logo were saved as bare file names by older versions of the admin,
+// which the frontend serves from /uploads/.
+var MediaReferenceSources = []MediaReferenceSource{
+	{Type: "news", Table: "news", TitleColumn: "title", Column: "featured_image_url", SoftDelete: true},
+	{Type: "news", Table: "news", TitleColumn: "title", Column: "content", SoftDelete: true},
+	{Type: "news_revision", Table: "news_revisions", TitleColumn: "title", Column: "featured_image_url"},
+	{Type: "news_revision", Table: "news_revisions", TitleColumn: "title", Column: "content"},
+	{Type: "hero_slider", Table: "hero_sliders", TitleColumn: "title", Column: "media_url", SoftDelete: true},
+	{Type: "village_official", Table: "village_officials", TitleColumn: "name", Column: "photo_url", SoftDelete: true},
+	{Type: "potential", Table: "potentials", TitleColumn: "title", Column: "cover_image_url", SoftDelete: true},
+	{Type: "setting", Table: "site_settings", TitleColumn: "setting_key", Column: "setting_value", SoftDelete: true, BareKey: true},
+}
+
+// MediaRepository defines the interface for media library operations
+type MediaRepository interface {
+	Upsert(media *models.Media) error
+	GetAll(page, limit int, filter MediaFilter) ([]models.Media, int64, error)
+	GetByID(id uint64) (*models.Media, error)
+	GetByKeys(keys []string) ([]models.Media, error)
+	Update(media *models.Media) error
+	Delete(id uint64) error
+	DeleteByKey(key string) error
+	SetQuarantined(key string, at *time.Time) error
+	FindReferences(urls []string, keys []string) ([]MediaReference, error)
+	GetReferenceValues() ([]MediaReferenceValue, error)
+}
+
+// GormMediaRepository implements MediaRepository using GORM
+type GormMediaRepository struct {
+	db *gorm.DB
+}
+
+// NewGormMediaRepository creates a new GormMediaRepository
+func NewGormMediaRepository(db *gorm.DB) MediaRepository {
+	return &GormMediaRepository{db: db}
+}
+
+// Upsert records an uploaded file. A file uploaded again under the same key (such as the
+// logo) replaces the details of the previous one; its alt text is kept unless a new one is given.
+func (r *GormMediaRepository) Upsert(media *models.Media) error {
+	columns := []string{"url", "thumbnail_url", "original_name", "upload_type", "mime_type", "size", "width", "height", "uploaded_by_id", "quarantined_at", "updated_at"}
+	if media.AltText != nil {
+		columns = append(columns, "alt_text")
+	}
+	return r.db.Omit("UploadedBy").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "storage_key"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(media).Error
+}
+
+// GetAll retrieves media with pagination, newest first
+func (r *GormMediaRepository) GetAll(page, limit int, filter MediaFilter) ([]models.Media, int64, error) {
+	var media []models.Media
+	var total int64
+
+	offset := (page - 1) * limit
+
+	query := r.db.Model(&models.Media{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("storage_key ILIKE ? OR original_name ILIKE ? OR alt_text ILIKE ?", pattern, pattern, pattern)
+	}
+	switch filter.Kind {
+	case MediaKindImage, MediaKindVideo:
+		query = query.Where("mime_type LIKE ?", filter.Kind+"/%")
+	case MediaKindDocument:
+		query = query.Where("mime_type NOT LIKE 'image/%' AND mime_type NOT LIKE 'video/%'")
+	}
+	if filter.UploadType != "" {
+		query = query.Where("upload_type = ?", filter.UploadType)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated data
+	if err := query.Preload("UploadedBy").Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&media).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return media, total, nil
+}
+
+// GetByID retrieves a media file by its ID
+func (r *GormMediaRepository) GetByID(id uint64) (*models.Media, error) {
+	var media models.Media
+	if err := r.db.Preload("UploadedBy").First(&media, id).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// GetByKeys retrieves the media files stored under any of the given keys
+func (r *GormMediaRepository) GetByKeys(keys []string) ([]models.Media, error) {
+	media := []models.Media{}
+	if len(keys) == 0 {
+		return media, nil
+	}
+	err := r.db.Where("storage_key IN ?", keys).Find(&media).Error
+	return media, err
+}
+
+// Update updates the editable details of a media file
+func (r *GormMediaRepository) Update(media *models.Media) error {
+	return r.db.Omit("UploadedBy").Save(media).Error
+}
+
+// Delete deletes a media record. The file itself is removed by the caller.
+func (r *GormMediaRepository) Delete(id uint64) error {
+	return r.db.Delete(&models.Media{}, id).Error
+}
+
+// DeleteByKey deletes the record of a stored file, if there is one
+func (r *GormMediaRepository) DeleteByKey(key string) error {
+	return r.db.Where("storage_key = ?", key).Delete(&models.Media{}).Error
+}
+
+// SetQuarantined records that the file under key was moved to (or, with nil, out of) the quarantine
+func (r *GormMediaRepository) SetQuarantined(key string, at *time.Time) error {
+	return r.db.Model(&models.Media{}).Where("storage_key = ?", key).Update("quarantined_at", at).Error
+}
+
+// FindReferences returns the content that contains any of the given URLs, so that both
+// relative and absolute links, and links to resized variants, are found. Columns that may
+// hold bare keys also match values equal to one of the keys. Like the garbage collector, it
+// includes soft-deleted content and old revisions of posts.
+func (r *GormMediaRepository) FindReferences(urls []string, keys []string) ([]MediaReference, error) {
+	references := []MediaReference{}
+	if len(urls) == 0 {
+		return references, nil
+	}
+
+	var selects []string
+	var args []interface{}
+	for _, source := range MediaReferenceSources {
+		conditions := make([]string, len(urls))
+		for i, url := range urls {
+			conditions[i] = source.Column + ` LIKE ? ESCAPE '\'`
+			args = append(args, "%"+escapeLike(url)+"%")
+		}
+		if source.BareKey && len(keys) > 0 {
+			conditions = append(conditions, source.Column+" IN ?")
+			args = append(args, keys)
+		}
+		deleted := "FALSE"
+		if source.SoftDelete {
+			deleted = "deleted_at IS NOT NULL"
+		}
+		selects = append(selects, fmt.Sprintf(
+			"SELECT '%s' AS type, id, %s AS title, '%s' AS field, %s AS deleted FROM %s WHERE %s",
+			source.Type, source.TitleColumn, source.Column, deleted, source.Table, strings.Join(conditions, " OR "),
+		))
+	}
+
+	err := r.db.Raw(strings.Join(selects, " UNION ALL ")+" ORDER BY type, id", args...).Scan(&references).Error
+	return references, err
+}
+
+// GetReferenceValues returns every value that may link to uploaded files, including those of
+// soft-deleted rows and old revisions, so the garbage collector never removes a file that could
+// be needed again
+func (r *GormMediaRepository) GetReferenceValues() ([]MediaReferenceValue, error) {
+	var values []MediaReferenceValue
+	for _, source := range MediaReferenceSources {
+		var columnValues []string
+		err := r.db.Table(source.Table).
+			Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", source.Column, source.Column)).
+			Pluck(source.Column, &columnValues).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range columnValues {
+			values = append(values, MediaReferenceValue{Value: value, BareKey: source.BareKey})
+		}
+	}
+	return values, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so user input is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // NewsRepository defines the interface for news data operations
@@ -12,13 +13,31 @@ type NewsRepository interface {
 	CreateNews(news *models.News) error
 	GetNewsByID(id uint64) (*models.News, error)
 	GetNewsBySlug(slug string) (*models.News, error)
-	GetAllNews(page, limit int) ([]models.News, int64, error)
-	GetAllNewsForAdmin(page, limit int) ([]models.News, int64, error)
-	UpdateNews(news *models.News) error
+	GetVisibleNewsByID(id uint64) (*models.News, error)
+	GetVisibleNewsBySlug(slug string) (*models.News, error)
+	GetAllNews(page, limit int, filter NewsFilter) ([]models.News, int64, error)
+	GetAllNewsForAdmin(page, limit int, authorID uint64) ([]models.News, int64, error)
+	UpdateNews(news *models.News, editorID uint64, restoredFrom *int) error
 	DeleteNews(id uint64) error
 	IsSlugExist(slug string, currentID uint64) (bool, error)
 	Count() (int64, error)
 	GetRecent(limit int) ([]models.News, error)
+	GetNewsByStatus(status models.NewsStatus, page, limit int) ([]models.News, int64, error)
+	TransitionStatus(news *models.News, from models.NewsStatus, history *models.NewsStatusHistory) (bool, error)
+	RecordStatusChange(history *models.NewsStatusHistory) error
+	GetStatusHistory(newsID uint64) ([]models.NewsStatusHistory, error)
+	GetRevisions(newsID uint64) ([]models.NewsRevision, error)
+	GetRevision(newsID uint64, revisionNumber int) (*models.NewsRevision, error)
+	PublishDueNews(now time.Time) ([]models.News, error)
+	UnpublishDueNews(now time.Time) ([]models.News, error)
+	GetUpcomingPublications(now time.Time) ([]models.News, error)
+	GetUpcomingUnpublications(now time.Time) ([]models.News, error)
+}
+
+// NewsFilter narrows down the public news listing. Empty fields are ignored.
+type NewsFilter struct {
+	CategorySlug string
+	TagSlug      string
 }
 
 // GormNewsRepository implements NewsRepository using GORM
@@ -31,68 +50,139 @@ func NewGormNewsRepository(db *gorm.DB) *GormNewsRepository {
 	return &GormNewsRepository{db: db}
 }
 
-// CreateNews creates a new news post in the database
+// CreateNews creates a new news post in the database together with its first revision and search index entry
 func (r *GormNewsRepository) CreateNews(news *models.News) error {
-	return r.db.Create(news).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Author", "Category", "Tags.*").Create(news).Error; err != nil {
+			return err
+		}
+		if err := refreshSearchVector(tx, "news", news.ID); err != nil {
+			return err
+		}
+		return createRevision(tx, news, news.AuthorID, nil)
+	})
 }
 
 // GetNewsByID retrieves a news post by its ID
 func (r *GormNewsRepository) GetNewsByID(id uint64) (*models.News, error) {
 	var news models.News
-	err := r.db.Preload("Author").First(&news, id).Error
+	err := r.db.Scopes(withTaxonomy).Preload("Author").First(&news, id).Error
 	return &news, err
 }
 
 // GetNewsBySlug retrieves a news post by its slug
 func (r *GormNewsRepository) GetNewsBySlug(slug string) (*models.News, error) {
 	var news models.News
-	err := r.db.Preload("Author").Where("slug = ?", slug).First(&news).Error
+	err := r.db.Scopes(withTaxonomy).Preload("Author").Where("slug = ?", slug).First(&news).Error
+	return &news, err
+}
+
+// GetVisibleNewsByID retrieves a news post by its ID if the public may see it now
+func (r *GormNewsRepository) GetVisibleNewsByID(id uint64) (*models.News, error) {
+	var news models.News
+	err := r.db.Scopes(visibleNews(time.Now()), withTaxonomy, withPublicAuthor).First(&news, id).Error
+	return &news, err
+}
+
+// GetVisibleNewsBySlug retrieves a news post by its slug if the public may see it now
+func (r *GormNewsRepository) GetVisibleNewsBySlug(slug string) (*models.News, error) {
+	var news models.News
+	err := r.db.Scopes(visibleNews(time.Now()), withTaxonomy, withPublicAuthor).Where("slug = ?", slug).First(&news).Error
 	return &news, err
 }
 
 // GetAllNews retrieves all news posts with pagination
-func (r *GormNewsRepository) GetAllNews(page, limit int) ([]models.News, int64, error) {
+func (r *GormNewsRepository) GetAllNews(page, limit int, filter NewsFilter) ([]models.News, int64, error) {
 	var news []models.News
 	var total int64
 
 	offset := (page - 1) * limit
 
+	// Count and page through the same visible set, so scheduled posts don't inflate the total
+	query := r.db.Model(&models.News{}).Scopes(visibleNews(time.Now()))
+	if filter.CategorySlug != "" {
+		query = query.Where("category_id IN (SELECT id FROM categories WHERE slug = ?)", filter.CategorySlug)
+	}
+	if filter.TagSlug != "" {
+		query = query.Where("id IN (SELECT news_tags.news_id FROM news_tags JOIN tags ON tags.id = news_tags.tag_id WHERE tags.slug = ?)", filter.TagSlug)
+	}
+
 	// Get total count
-	if err := r.db.Model(&models.News{}).Where("status = ?", models.NewsStatusPublished).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated data
-	if err := r.db.Preload("Author").Where("status = ? AND published_at <= ?", models.NewsStatusPublished, time.Now()).Order("published_at DESC").Offset(offset).Limit(limit).Find(&news).Error; err != nil {
+	if err := query.Scopes(withTaxonomy, withPublicAuthor).Order("published_at DESC").Offset(offset).Limit(limit).Find(&news).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return news, total, nil
 }
 
-// GetAllNewsForAdmin retrieves all news posts with pagination for the admin panel
-func (r *GormNewsRepository) GetAllNewsForAdmin(page, limit int) ([]models.News, int64, error) {
+// withTaxonomy preloads the category and tags of posts
+func withTaxonomy(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").Preload("Tags")
+}
+
+// withPublicAuthor preloads only the public details of the author of posts: account details
+// such as the email address or the login state never leave the database for public routes
+func withPublicAuthor(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author", func(db *gorm.DB) *gorm.DB { return db.Select("id", "full_name") })
+}
+
+// visibleNews limits a query to posts the public may see at the given moment. It does not rely
+// on the scheduler alone, so a post disappears on time even if the scheduler runs late.
+func visibleNews(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND published_at <= ? AND (unpublish_at IS NULL OR unpublish_at > ?)", models.NewsStatusPublished, now, now)
+	}
+}
+
+// GetAllNewsForAdmin retrieves news posts with pagination for the admin panel.
+// A non-zero authorID limits the result to the posts of that author.
+func (r *GormNewsRepository) GetAllNewsForAdmin(page, limit int, authorID uint64) ([]models.News, int64, error) {
 	var news []models.News
 	var total int64
 
 	offset := (page - 1) * limit
 
+	query := r.db.Model(&models.News{})
+	if authorID != 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+
 	// Get total count
-	if err := r.db.Model(&models.News{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated data
-	if err := r.db.Preload("Author").Order("created_at DESC").Offset(offset).Limit(limit).Find(&news).Error; err != nil {
+	if err := query.Scopes(withTaxonomy).Preload("Author").Order("created_at DESC").Offset(offset).Limit(limit).Find(&news).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return news, total, nil
 }
 
-// UpdateNews updates an existing news post in the database
-func (r *GormNewsRepository) UpdateNews(news *models.News) error {
-	return r.db.Save(news).Error
+// UpdateNews updates an existing news post in the database, refreshes its search index entry
+// and stores the result as a new revision
+func (r *GormNewsRepository) UpdateNews(news *models.News, editorID uint64, restoredFrom *int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureBaselineRevision(tx, news.ID); err != nil {
+			return err
+		}
+		if err := tx.Omit("Author", "Category", "Tags").Save(news).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(news).Omit("Tags.*").Association("Tags").Replace(news.Tags); err != nil {
+			return err
+		}
+		if err := refreshSearchVector(tx, "news", news.ID); err != nil {
+			return err
+		}
+		return createRevision(tx, news, editorID, restoredFrom)
+	})
 }
 
 // DeleteNews deletes a news post by its ID (soft delete)
@@ -137,3 +227,199 @@ func (r *GormNewsRepository) GetRecent(limit int) ([]models.News, error) {
 		Find(&news).Error
 	return news, err
 }
+
+// GetNewsByStatus retrieves news posts with the given status, oldest change first
+func (r *GormNewsRepository) GetNewsByStatus(status models.NewsStatus, page, limit int) ([]models.News, int64, error) {
+	var news []models.News
+	var total int64
+
+	offset := (page - 1) * limit
+	query := r.db.Model(&models.News{}).Where("status = ?", status)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Preload("Author").Order("updated_at ASC").Offset(offset).Limit(limit).Find(&news).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return news, total, nil
+}
+
+// TransitionStatus saves the new status and published date of a post together with its history
+// entry. The update only applies while the post still has the status `from`, so two reviewers
+// acting at the same time cannot both succeed; false is returned for the one that lost.
+func (r *GormNewsRepository) TransitionStatus(news *models.News, from models.NewsStatus, history *models.NewsStatusHistory) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.News{}).
+			Where("id = ? AND status = ?", news.ID, from).
+			Updates(map[string]interface{}{
+				"status":       news.Status,
+				"published_at": news.PublishedAt,
+				"updated_at":   time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return tx.Create(history).Error
+	})
+	return updated, err
+}
+
+// RecordStatusChange stores a status history entry
+func (r *GormNewsRepository) RecordStatusChange(history *models.NewsStatusHistory) error {
+	return r.db.Create(history).Error
+}
+
+// GetStatusHistory returns the status history of a post, oldest first
+func (r *GormNewsRepository) GetStatusHistory(newsID uint64) ([]models.NewsStatusHistory, error) {
+	var history []models.NewsStatusHistory
+	err := r.db.Preload("ChangedBy").
+		Where("news_id = ?", newsID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// GetRevisions lists the revisions of a post, newest first. The content is left out to keep the list small.
+func (r *GormNewsRepository) GetRevisions(newsID uint64) ([]models.NewsRevision, error) {
+	var revisions []models.NewsRevision
+	err := r.db.Preload("Editor").
+		Omit("content").
+		Where("news_id = ?", newsID).
+		Order("revision_number DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision retrieves a single revision of a post
+func (r *GormNewsRepository) GetRevision(newsID uint64, revisionNumber int) (*models.NewsRevision, error) {
+	var revision models.NewsRevision
+	err := r.db.Preload("Editor").
+		Where("news_id = ? AND revision_number = ?", newsID, revisionNumber).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// createRevision stores a snapshot of a post with the next revision number.
+// The post row is locked first so concurrent saves get consecutive numbers.
+func createRevision(tx *gorm.DB, news *models.News, editorID uint64, restoredFrom *int) error {
+	if err := tx.Exec("SELECT id FROM news WHERE id = ? FOR UPDATE", news.ID).Error; err != nil {
+		return err
+	}
+
+	var next int
+	if err := tx.Model(&models.NewsRevision{}).
+		Where("news_id = ?", news.ID).
+		Select("COALESCE(MAX(revision_number), 0) + 1").
+		Scan(&next).Error; err != nil {
+		return err
+	}
+
+	revision := models.NewsRevision{
+		NewsID:           news.ID,
+		RevisionNumber:   next,
+		Title:            news.Title,
+		Slug:             news.Slug,
+		Content:          news.Content,
+		FeaturedImageURL: news.FeaturedImageURL,
+		Status:           news.Status,
+		PublishedAt:      news.PublishedAt,
+		RestoredFrom:     restoredFrom,
+		EditorID:         editorID,
+	}
+	return tx.Omit("Editor").Create(&revision).Error
+}
+
+// ensureBaselineRevision snapshots a post that predates revision history before its first
+// update, so the original version is not lost
+func ensureBaselineRevision(tx *gorm.DB, newsID uint64) error {
+	var count int64
+	if err := tx.Model(&models.NewsRevision{}).Where("news_id = ?", newsID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var original models.News
+	if err := tx.First(&original, newsID).Error; err != nil {
+		return err
+	}
+	return createRevision(tx, &original, original.AuthorID, nil)
+}
+
+// PublishDueNews publishes scheduled posts whose publication date has been reached
+func (r *GormNewsRepository) PublishDueNews(now time.Time) ([]models.News, error) {
+	return r.applyScheduledStatus(now, models.NewsStatusPublished,
+		"status = ? AND published_at <= ?", models.NewsStatusScheduled, now)
+}
+
+// UnpublishDueNews archives published or scheduled posts whose unpublish date has been reached
+func (r *GormNewsRepository) UnpublishDueNews(now time.Time) ([]models.News, error) {
+	return r.applyScheduledStatus(now, models.NewsStatusArchived,
+		"status IN ? AND unpublish_at <= ?", []models.NewsStatus{models.NewsStatusPublished, models.NewsStatusScheduled}, now)
+}
+
+// GetUpcomingPublications returns scheduled posts that are not published yet, soonest first
+func (r *GormNewsRepository) GetUpcomingPublications(now time.Time) ([]models.News, error) {
+	var news []models.News
+	err := r.db.Preload("Author").
+		Where("status = ? AND published_at > ?", models.NewsStatusScheduled, now).
+		Order("published_at ASC").
+		Find(&news).Error
+	return news, err
+}
+
+// GetUpcomingUnpublications returns published or scheduled posts with a future unpublish date, soonest first
+func (r *GormNewsRepository) GetUpcomingUnpublications(now time.Time) ([]models.News, error) {
+	var news []models.News
+	err := r.db.Preload("Author").
+		Where("status IN ? AND unpublish_at > ?", []models.NewsStatus{models.NewsStatusPublished, models.NewsStatusScheduled}, now).
+		Order("unpublish_at ASC").
+		Find(&news).Error
+	return news, err
+}
+
+// applyScheduledStatus moves the posts matching the condition to a new status and records the
+// change in their history. Rows are locked with SKIP LOCKED so several replicas can run the
+// scheduler at once without handling a post twice.
+func (r *GormNewsRepository) applyScheduledStatus(now time.Time, to models.NewsStatus, condition string, args ...interface{}) ([]models.News, error) {
+	var due []models.News
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(condition, args...).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(due))
+		history := make([]models.NewsStatusHistory, len(due))
+		for i, news := range due {
+			ids[i] = news.ID
+			history[i] = models.NewsStatusHistory{
+				NewsID:     news.ID,
+				FromStatus: news.Status,
+				ToStatus:   to,
+				Action:     "schedule",
+			}
+			due[i].Status = to
+		}
+
+		if err := tx.Model(&models.News{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": to, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&history).Error
+	})
+	return due, err
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
@@ -14,12 +15,71 @@ type PopularPage struct {
 	ViewCount int64  `json:"view_count"`
 }
 
+// DailyStat is the number of views and unique visitors of a day, or of a longer period
+// when days are added up
+type DailyStat struct {
+	Day            string `json:"day"` // YYYY-MM-DD
+	Views          int64  `json:"views"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// ReferrerCount is the number of views coming from a referring domain
+type ReferrerCount struct {
+	Referrer string `json:"referrer"`
+	Views    int64  `json:"views"`
+}
+
+// BreakdownCount is the number of views and visitors with one value of a breakdown, such as
+// the "mobile" device or the "whatsapp" source
+type BreakdownCount struct {
+	Value          string `json:"value"`
+	Views          int64  `json:"views"`
+	UniqueVisitors int64  `json:"unique_visitors"` // Counted once per day
+}
+
+// Page view breakdowns, named after their page_views column
+const (
+	BreakdownSource      = "source"
+	BreakdownDevice      = "device"
+	BreakdownBrowser     = "browser"
+	BreakdownOS          = "os"
+	BreakdownUTMSource   = "utm_source"
+	BreakdownUTMMedium   = "utm_medium"
+	BreakdownUTMCampaign = "utm_campaign"
+)
+
+// BreakdownDimensions lists the breakdowns rolled up per day
+var BreakdownDimensions = []string{
+	BreakdownSource, BreakdownDevice, BreakdownBrowser, BreakdownOS,
+	BreakdownUTMSource, BreakdownUTMMedium, BreakdownUTMCampaign,
+}
+
+// Number of pages, referrers and values of each breakdown kept per day by RollupDay
+const (
+	rollupTopPages           = 100
+	rollupTopReferrers       = 50
+	rollupTopBreakdownValues = 50
+)
+
+// dateFormat is how days are passed to and read from DATE columns, avoiding time zone conversions
+const dateFormat = "2006-01-02"
+
 // PageViewRepository defines methods for page view analytics
 type PageViewRepository interface {
 	Create(pageView *models.PageView) error
+	CreateBatch(pageViews []models.PageView) error
 	CountViews(start, end time.Time) (int64, error)
 	CountUniqueVisitors(start, end time.Time) (int64, error)
-	GetPopularPages(limit int, since time.Time) ([]PopularPage, error)
+	GetFirstViewTime() (*time.Time, error)
+	GetLastRollupDay() (*time.Time, error)
+	RollupDay(day time.Time, ownHost string) error
+	DeleteViewsBefore(before time.Time) (int64, error)
+	GetDailyStats(from, to time.Time) ([]DailyStat, error)
+	GetTopPages(from, to time.Time, limit int) ([]PopularPage, error)
+	GetTopReferrers(from, to time.Time, limit int) ([]ReferrerCount, error)
+	GetBreakdown(dimension string, from, to time.Time, limit int) ([]BreakdownCount, error)
+	GetDailySalt(day time.Time, candidate []byte) ([]byte, error)
+	DeleteSaltsBefore(day time.Time) error
 }
 
 type pageViewRepository struct {
@@ -36,6 +96,14 @@ func (r *pageViewRepository) Create(pageView *models.PageView) error {
 	return r.db.Create(pageView).Error
 }
 
+// CreateBatch inserts several page view records at once
+func (r *pageViewRepository) CreateBatch(pageViews []models.PageView) error {
+	if len(pageViews) == 0 {
+		return nil
+	}
+	return r.db.Create(&pageViews).Error
+}
+
 // CountViews returns the total number of page views within a date range
 func (r *pageViewRepository) CountViews(start, end time.Time) (int64, error) {
 	var count int64
@@ -55,15 +123,155 @@ func (r *pageViewRepository) CountUniqueVisitors(start, end time.Time) (int64, e
 	return count, err
 }
 
-// GetPopularPages returns the most viewed pages since a given date
-func (r *pageViewRepository) GetPopularPages(limit int, since time.Time) ([]PopularPage, error) {
-	var pages []PopularPage
-	err := r.db.Model(&models.PageView{}).
-		Select("page_url, COALESCE(page_title, page_url) as page_title, COUNT(*) as view_count").
-		Where("viewed_at >= ?", since).
-		Group("page_url, page_title").
-		Order("view_count DESC").
-		Limit(limit).
+// GetFirstViewTime returns when the oldest stored page view happened, or nil if there is none
+func (r *pageViewRepository) GetFirstViewTime() (*time.Time, error) {
+	var first *time.Time
+	err := r.db.Raw("SELECT MIN(viewed_at) FROM page_views").Row().Scan(&first)
+	return first, err
+}
+
+// GetLastRollupDay returns the most recent day that was rolled up, or nil if none was
+func (r *pageViewRepository) GetLastRollupDay() (*time.Time, error) {
+	var last *string
+	if err := r.db.Raw("SELECT to_char(MAX(day), 'YYYY-MM-DD') FROM analytics_daily_stats").Row().Scan(&last); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+	day, err := time.ParseInLocation(dateFormat, *last, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
+
+// RollupDay summarizes the page views of the day starting at the given local midnight: its
+// totals, its most viewed pages, its most common referring domains and the most common values
+// of each breakdown. Running it again replaces the previous summary. Referrers from ownHost
+// (navigation inside the site) are left out.
+func (r *pageViewRepository) RollupDay(day time.Time, ownHost string) error {
+	date := day.Format(dateFormat)
+	start, end := day, day.AddDate(0, 0, 1)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO analytics_daily_stats (day, views, unique_visitors)
+			SELECT CAST(? AS DATE), COUNT(*), COUNT(DISTINCT visitor_id) FROM page_views WHERE viewed_at >= ? AND viewed_at < ?
+			ON CONFLICT (day) DO UPDATE SET views = EXCLUDED.views, unique_visitors = EXCLUDED.unique_visitors, updated_at = NOW()`,
+			date, start, end).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM analytics_daily_pages WHERE day = ?", date).Error; err != nil {
+			return err
+		}
+		err = tx.Exec(`INSERT INTO analytics_daily_pages (day, page_url, page_title, views, unique_visitors)
+			SELECT CAST(? AS DATE), page_url, MAX(page_title), COUNT(*), COUNT(DISTINCT visitor_id) FROM page_views
+			WHERE viewed_at >= ? AND viewed_at < ?
+			GROUP BY page_url ORDER BY COUNT(*) DESC, page_url LIMIT ?`,
+			date, start, end, rollupTopPages).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM analytics_daily_referrers WHERE day = ?", date).Error; err != nil {
+			return err
+		}
+		err = tx.Exec(`INSERT INTO analytics_daily_referrers (day, referrer, views)
+			SELECT CAST(? AS DATE), referrer_domain, COUNT(*) FROM page_views
+			WHERE viewed_at >= ? AND viewed_at < ? AND referrer_domain IS NOT NULL AND referrer_domain <> '' AND referrer_domain <> ?
+			GROUP BY referrer_domain ORDER BY COUNT(*) DESC, referrer_domain LIMIT ?`,
+			date, start, end, ownHost, rollupTopReferrers).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM analytics_daily_breakdowns WHERE day = ?", date).Error; err != nil {
+			return err
+		}
+		for _, dimension := range BreakdownDimensions {
+			// The dimension is one of the known column names, never user input
+			err := tx.Exec(fmt.Sprintf(`INSERT INTO analytics_daily_breakdowns (day, dimension, value, views, unique_visitors)
+				SELECT CAST(? AS DATE), ?, %[1]s, COUNT(*), COUNT(DISTINCT visitor_id) FROM page_views
+				WHERE viewed_at >= ? AND viewed_at < ? AND %[1]s IS NOT NULL AND %[1]s <> ''
+				GROUP BY %[1]s ORDER BY COUNT(*) DESC, %[1]s LIMIT ?`, dimension),
+				date, dimension, start, end, rollupTopBreakdownValues).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteViewsBefore deletes the raw page views older than the given time and returns how many were deleted
+func (r *pageViewRepository) DeleteViewsBefore(before time.Time) (int64, error) {
+	result := r.db.Where("viewed_at < ?", before).Delete(&models.PageView{})
+	return result.RowsAffected, result.Error
+}
+
+// GetDailyStats returns the rolled up totals of every day from from to to (inclusive) that has one
+func (r *pageViewRepository) GetDailyStats(from, to time.Time) ([]DailyStat, error) {
+	stats := []DailyStat{}
+	err := r.db.Raw(`SELECT to_char(day, 'YYYY-MM-DD') AS day, views, unique_visitors FROM analytics_daily_stats
+		WHERE day BETWEEN ? AND ? ORDER BY day`, from.Format(dateFormat), to.Format(dateFormat)).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// GetTopPages returns the most viewed pages of the rolled up days from from to to (inclusive)
+func (r *pageViewRepository) GetTopPages(from, to time.Time, limit int) ([]PopularPage, error) {
+	pages := []PopularPage{}
+	err := r.db.Raw(`SELECT page_url, COALESCE(MAX(page_title), page_url) AS page_title, SUM(views) AS view_count
+		FROM analytics_daily_pages WHERE day BETWEEN ? AND ?
+		GROUP BY page_url ORDER BY view_count DESC, page_url LIMIT ?`,
+		from.Format(dateFormat), to.Format(dateFormat), limit).
 		Scan(&pages).Error
 	return pages, err
 }
+
+// GetTopReferrers returns the most common referring domains of the rolled up days from from to to (inclusive)
+func (r *pageViewRepository) GetTopReferrers(from, to time.Time, limit int) ([]ReferrerCount, error) {
+	referrers := []ReferrerCount{}
+	err := r.db.Raw(`SELECT referrer, SUM(views) AS views FROM analytics_daily_referrers
+		WHERE day BETWEEN ? AND ?
+		GROUP BY referrer ORDER BY views DESC, referrer LIMIT ?`,
+		from.Format(dateFormat), to.Format(dateFormat), limit).
+		Scan(&referrers).Error
+	return referrers, err
+}
+
+// GetBreakdown returns the most common values of a breakdown over the rolled up days from from
+// to to (inclusive)
+func (r *pageViewRepository) GetBreakdown(dimension string, from, to time.Time, limit int) ([]BreakdownCount, error) {
+	counts := []BreakdownCount{}
+	err := r.db.Raw(`SELECT value, SUM(views) AS views, SUM(unique_visitors) AS unique_visitors
+		FROM analytics_daily_breakdowns WHERE dimension = ? AND day BETWEEN ? AND ?
+		GROUP BY value ORDER BY views DESC, value LIMIT ?`,
+		dimension, from.Format(dateFormat), to.Format(dateFormat), limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetDailySalt returns the visitor ID salt of a day. The first caller of the day stores its
+// candidate, so every replica uses the same salt; the salts of earlier days are deleted.
+func (r *pageViewRepository) GetDailySalt(day time.Time, candidate []byte) ([]byte, error) {
+	date := day.Format(dateFormat)
+	var salt []byte
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM analytics_salts WHERE day < ?", date).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO analytics_salts (day, salt) VALUES (?, ?) ON CONFLICT (day) DO NOTHING", date, candidate).Error; err != nil {
+			return err
+		}
+		return tx.Raw("SELECT salt FROM analytics_salts WHERE day = ?", date).Row().Scan(&salt)
+	})
+	return salt, err
+}
+
+// DeleteSaltsBefore deletes the visitor ID salts of the days before the given one
+func (r *pageViewRepository) DeleteSaltsBefore(day time.Time) error {
+	return r.db.Exec("DELETE FROM analytics_salts WHERE day < ?", day.Format(dateFormat)).Error
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository defines the interface for password reset token operations
+type PasswordResetRepository interface {
+	CreateToken(token *models.PasswordResetToken) error
+	GetValidToken(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint64) (bool, error)
+}
+
+// GormPasswordResetRepository implements PasswordResetRepository using GORM
+type GormPasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPasswordResetRepository creates a new GormPasswordResetRepository
+func NewGormPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &GormPasswordResetRepository{db: db}
+}
+
+// CreateToken stores a new reset token and invalidates any earlier unused token of the same user,
+// so only the most recent link works
+func (r *GormPasswordResetRepository) CreateToken(token *models.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// GetValidToken retrieves an unused, unexpired token by its hash
+func (r *GormPasswordResetRepository) GetValidToken(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token).Error
+	return &token, err
+}
+
+// MarkUsed consumes a token. It returns false if the token was already used,
+// so two concurrent requests cannot both reset the password.
+func (r *GormPasswordResetRepository) MarkUsed(id uint64) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
@@ -27,7 +27,12 @@ func NewGormPotentialRepository(db *gorm.DB) *GormPotentialRepository {
 
 // CreatePotential creates a new potential record in the database
 func (r *GormPotentialRepository) CreatePotential(potential *models.Potential) error {
-	return r.db.Create(potential).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(potential).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, "potentials", potential.ID)
+	})
 }
 
 // GetPotentialByID retrieves a potential by its ID
@@ -46,7 +51,12 @@ func (r *GormPotentialRepository) GetAllPotentials() ([]models.Potential, error)
 
 // UpdatePotential updates an existing potential record in the database
 func (r *GormPotentialRepository) UpdatePotential(potential *models.Potential) error {
-	return r.db.Save(potential).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(potential).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, "potentials", potential.ID)
+	})
 }
 
 // DeletePotential deletes a potential record by its ID (soft delete)
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository defines the interface for two-factor recovery code operations
+type RecoveryCodeRepository interface {
+	ReplaceCodes(userID uint64, codeHashes []string) error
+	UseCode(userID uint64, codeHash string) (bool, error)
+	CountRemaining(userID uint64) (int64, error)
+}
+
+// GormRecoveryCodeRepository implements RecoveryCodeRepository using GORM
+type GormRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRecoveryCodeRepository creates a new GormRecoveryCodeRepository
+func NewGormRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &GormRecoveryCodeRepository{db: db}
+}
+
+// ReplaceCodes deletes all existing recovery codes of a user and stores the new ones
+func (r *GormRecoveryCodeRepository) ReplaceCodes(userID uint64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.UserRecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.UserRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseCode marks an unused recovery code as used. It returns false if the code does not
+// exist or has already been used.
+func (r *GormRecoveryCodeRepository) UseCode(userID uint64, codeHash string) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRemaining returns how many unused recovery codes a user has left
+func (r *GormRecoveryCodeRepository) CountRemaining(userID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
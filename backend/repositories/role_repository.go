@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"log"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository defines the interface for role and permission operations
+type RoleRepository interface {
+	GetAllRoles() ([]models.Role, error)
+	GetRoleByID(id uint64) (*models.Role, error)
+	GetRoleByName(name string) (*models.Role, error)
+	CreateRole(role *models.Role, permissions []string) error
+	UpdateRole(role *models.Role, permissions []string) error
+	DeleteRole(id uint64) error
+	CountUsersWithRole(name string) (int64, error)
+	RoleHasPermission(roleName string, permission string) (bool, error)
+	SeedDefaultRoles(roles []models.Role)
+}
+
+// GormRoleRepository implements RoleRepository using GORM
+type GormRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRoleRepository creates a new GormRoleRepository
+func NewGormRoleRepository(db *gorm.DB) RoleRepository {
+	return &GormRoleRepository{db: db}
+}
+
+// GetAllRoles retrieves all roles with their active permissions
+func (r *GormRoleRepository) GetAllRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetRoleByID retrieves a role with its active permissions by ID
+func (r *GormRoleRepository) GetRoleByID(id uint64) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoleByName retrieves a role with its active permissions by name
+func (r *GormRoleRepository) GetRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CreateRole creates a new role with the given permissions
+func (r *GormRoleRepository) CreateRole(role *models.Role, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Create(role).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role.ID, permissions)
+	})
+}
+
+// UpdateRole saves the role details and replaces its permission set
+func (r *GormRoleRepository) UpdateRole(role *models.Role, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role.ID, permissions)
+	})
+}
+
+// DeleteRole permanently deletes a role and its permissions
+func (r *GormRoleRepository) DeleteRole(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, id).Error
+	})
+}
+
+// CountUsersWithRole counts the users assigned to a role
+func (r *GormRoleRepository) CountUsersWithRole(name string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
+
+// RoleHasPermission checks whether a role currently grants a permission
+func (r *GormRoleRepository) RoleHasPermission(roleName string, permission string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ? AND role_permissions.permission = ?", roleName, permission).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SeedDefaultRoles creates the built-in roles if they don't exist and grants them any
+// default permission they never had. Permissions revoked by an administrator stay revoked.
+func (r *GormRoleRepository) SeedDefaultRoles(roles []models.Role) {
+	for _, defaultRole := range roles {
+		role := models.Role{Name: defaultRole.Name, Description: defaultRole.Description, IsSystem: defaultRole.IsSystem}
+		if err := r.db.Omit("Permissions").Where(models.Role{Name: defaultRole.Name}).FirstOrCreate(&role).Error; err != nil {
+			log.Fatalf("FATAL: Failed to seed role '%s': %v", defaultRole.Name, err)
+		}
+
+		for _, p := range defaultRole.Permissions {
+			grant := models.RolePermission{RoleID: role.ID, Permission: p.Permission}
+			// The unique index also covers soft-deleted rows, so revoked permissions are skipped
+			if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&grant).Error; err != nil {
+				log.Fatalf("FATAL: Failed to seed permission '%s' for role '%s': %v", p.Permission, defaultRole.Name, err)
+			}
+		}
+	}
+	log.Println("Default roles are up to date.")
+}
+
+// setRolePermissions makes the given permissions the active set of a role
+func setRolePermissions(tx *gorm.DB, roleID uint64, permissions []string) error {
+	revoke := tx.Where("role_id = ?", roleID)
+	if len(permissions) > 0 {
+		revoke = revoke.Where("permission NOT IN ?", permissions)
+	}
+	if err := revoke.Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	// Restore permissions that were revoked earlier, then add the ones that never existed
+	if err := tx.Unscoped().Model(&models.RolePermission{}).
+		Where("role_id = ? AND permission IN ? AND deleted_at IS NOT NULL", roleID, permissions).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	grants := make([]models.RolePermission, len(permissions))
+	for i, permission := range permissions {
+		grants[i] = models.RolePermission{RoleID: roleID, Permission: permission}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// searchConfig is the PostgreSQL text search configuration created by the migrations.
+// It removes accents and stems Indonesian words when the server supports it.
+const searchConfig = "sid_search"
+
+// Content types that can be searched
+const (
+	SearchTypeNews      = "news"
+	SearchTypeService   = "service"
+	SearchTypePotential = "potential"
+)
+
+// searchVectors holds, per table, the expression that builds its weighted search vector:
+// titles weigh more than descriptions. HTML tags are removed from rich text first.
+var searchVectors = map[string]string{
+	"news": "setweight(to_tsvector('" + searchConfig + "', coalesce(title, '')), 'A') || " +
+		"setweight(to_tsvector('" + searchConfig + "', regexp_replace(coalesce(content, ''), '<[^>]*>', ' ', 'g')), 'B')",
+	"services": "setweight(to_tsvector('" + searchConfig + "', coalesce(service_name, '')), 'A') || " +
+		"setweight(to_tsvector('" + searchConfig + "', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g')), 'B') || " +
+		"setweight(to_tsvector('" + searchConfig + "', regexp_replace(coalesce(requirements, ''), '<[^>]*>', ' ', 'g')), 'C')",
+	"potentials": "setweight(to_tsvector('" + searchConfig + "', coalesce(title, '')), 'A') || " +
+		"setweight(to_tsvector('" + searchConfig + "', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g')), 'B')",
+}
+
+// refreshSearchVector recomputes the search vector of one row. Repositories call it in their
+// create and update paths so the index never lags behind the content.
+func refreshSearchVector(db *gorm.DB, table string, id uint64) error {
+	return db.Exec(fmt.Sprintf("UPDATE %s SET search_vector = %s WHERE id = ?", table, searchVectors[table]), id).Error
+}
+
+// SearchResult is a single search hit
+type SearchResult struct {
+	Type    string             `json:"type"`
+	ID      uint64             `json:"id"`
+	Title   string             `json:"title"`
+	Slug    *string            `json:"slug,omitempty"`   // Only for news
+	Status  *models.NewsStatus `json:"status,omitempty"` // Only for news
+	Snippet string             `json:"snippet"`          // Matching words are wrapped in <mark>
+	Rank    float64            `json:"rank"`
+	Date    *time.Time         `json:"date,omitempty"`
+}
+
+// SearchOptions narrows down a search
+type SearchOptions struct {
+	Types              []string // Empty searches every type
+	IncludeUnpublished bool     // Also return news that is not visible to the public
+	Page               int
+	Limit              int
+}
+
+// SearchRepository defines the interface for full-text search
+type SearchRepository interface {
+	Search(query string, options SearchOptions) ([]SearchResult, int64, error)
+	RebuildMissing() error
+}
+
+// GormSearchRepository implements SearchRepository using PostgreSQL full-text search
+type GormSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSearchRepository creates a new GormSearchRepository
+func NewGormSearchRepository(db *gorm.DB) SearchRepository {
+	return &GormSearchRepository{db: db}
+}
+
+// Search finds news, services and potentials matching the query, best match first.
+// The query uses web search syntax: quoted phrases, OR and -excluded words are supported.
+func (r *GormSearchRepository) Search(query string, options SearchOptions) ([]SearchResult, int64, error) {
+	headline := "'MaxFragments=2, MaxWords=25, MinWords=8, StartSel=<mark>, StopSel=</mark>'"
+	tsQuery := "websearch_to_tsquery('" + searchConfig + "', ?)"
+
+	var parts []string
+	var args []interface{}
+	if searchesType(options.Types, SearchTypeNews) {
+		visibility := ""
+		if !options.IncludeUnpublished {
+			visibility = " AND status = 'published' AND published_at <= now() AND (unpublish_at IS NULL OR unpublish_at > now())"
+		}
+		parts = append(parts, `SELECT 'news' AS type, id, title, slug, status::text AS status,
+			ts_headline('`+searchConfig+`', regexp_replace(content, '<[^>]*>', ' ', 'g'), q, `+headline+`) AS snippet,
+			ts_rank(search_vector, q) AS rank, published_at AS date
+			FROM news, `+tsQuery+` q
+			WHERE deleted_at IS NULL AND search_vector @@ q`+visibility)
+		args = append(args, query)
+	}
+	if searchesType(options.Types, SearchTypeService) {
+		parts = append(parts, `SELECT 'service' AS type, id, service_name AS title, NULL AS slug, NULL AS status,
+			ts_headline('`+searchConfig+`', regexp_replace(coalesce(description, '') || ' ' || coalesce(requirements, ''), '<[^>]*>', ' ', 'g'), q, `+headline+`) AS snippet,
+			ts_rank(search_vector, q) AS rank, updated_at AS date
+			FROM services, `+tsQuery+` q
+			WHERE deleted_at IS NULL AND search_vector @@ q`)
+		args = append(args, query)
+	}
+	if searchesType(options.Types, SearchTypePotential) {
+		parts = append(parts, `SELECT 'potential' AS type, id, title, NULL AS slug, NULL AS status,
+			ts_headline('`+searchConfig+`', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g'), q, `+headline+`) AS snippet,
+			ts_rank(search_vector, q) AS rank, updated_at AS date
+			FROM potentials, `+tsQuery+` q
+			WHERE deleted_at IS NULL AND search_vector @@ q`)
+		args = append(args, query)
+	}
+	if len(parts) == 0 {
+		return []SearchResult{}, 0, nil
+	}
+
+	union := strings.Join(parts, " UNION ALL ")
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) FROM ("+union+") results", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results := []SearchResult{}
+	offset := (options.Page - 1) * options.Limit
+	pageArgs := append(append([]interface{}{}, args...), options.Limit, offset)
+	err := r.db.Raw("SELECT * FROM ("+union+") results ORDER BY rank DESC, date DESC NULLS LAST LIMIT ? OFFSET ?", pageArgs...).
+		Scan(&results).Error
+	return results, total, err
+}
+
+// RebuildMissing computes the search vector of every row that does not have one yet,
+// e.g. rows that existed before search was introduced
+func (r *GormSearchRepository) RebuildMissing() error {
+	for table, vector := range searchVectors {
+		if err := r.db.Exec(fmt.Sprintf("UPDATE %s SET search_vector = %s WHERE search_vector IS NULL", table, vector)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func searchesType(types []string, searchType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == searchType {
+			return true
+		}
+	}
+	return false
+}
@@ -27,7 +27,12 @@ func NewGormServiceRepository(db *gorm.DB) *GormServiceRepository {
 
 // CreateService creates a new service record in the database
 func (r *GormServiceRepository) CreateService(service *models.Service) error {
-	return r.db.Create(service).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(service).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, "services", service.ID)
+	})
 }
 
 // GetServiceByID retrieves a service by its ID
@@ -46,7 +51,12 @@ func (r *GormServiceRepository) GetAllServices() ([]models.Service, error) {
 
 // UpdateService updates an existing service record in the database
 func (r *GormServiceRepository) UpdateService(service *models.Service) error {
-	return r.db.Save(service).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(service).Error; err != nil {
+			return err
+		}
+		return refreshSearchVector(tx, "services", service.ID)
+	})
 }
 
 // DeleteService deletes a service record by its ID (soft delete)
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository defines the interface for user session (refresh token) operations
+type SessionRepository interface {
+	CreateSession(session *models.UserSession) error
+	GetSessionByTokenHash(tokenHash string) (*models.UserSession, error)
+	GetSessionByPreviousTokenHash(tokenHash string) (*models.UserSession, error)
+	GetActiveSessionsByUser(userID uint64) ([]models.UserSession, error)
+	IsSessionActive(sessionID uint64, userID uint64) (bool, error)
+	RotateToken(sessionID uint64, oldHash string, newHash string, expiresAt time.Time) (bool, error)
+	RevokeSession(sessionID uint64) error
+	RevokeUserSession(userID uint64, sessionID uint64) (bool, error)
+	RevokeAllUserSessions(userID uint64) error
+}
+
+// GormSessionRepository implements SessionRepository using GORM
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSessionRepository creates a new GormSessionRepository
+func NewGormSessionRepository(db *gorm.DB) SessionRepository {
+	return &GormSessionRepository{db: db}
+}
+
+// CreateSession stores a new session record
+func (r *GormSessionRepository) CreateSession(session *models.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetSessionByTokenHash retrieves a session by the hash of its current refresh token
+func (r *GormSessionRepository) GetSessionByTokenHash(tokenHash string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.Where("refresh_token_hash = ?", tokenHash).First(&session).Error
+	return &session, err
+}
+
+// GetSessionByPreviousTokenHash retrieves a session by the hash of the refresh token it had
+// before the last rotation
+func (r *GormSessionRepository) GetSessionByPreviousTokenHash(tokenHash string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.Where("previous_token_hash = ?", tokenHash).First(&session).Error
+	return &session, err
+}
+
+// GetActiveSessionsByUser returns all sessions of a user that are neither revoked nor expired
+func (r *GormSessionRepository) GetActiveSessionsByUser(userID uint64) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// IsSessionActive reports whether the session exists, belongs to the user and has not been revoked or expired
+func (r *GormSessionRepository) IsSessionActive(sessionID uint64, userID uint64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RotateToken replaces the refresh token hash of a session, remembering the old one.
+// The update only succeeds if the old hash still matches, so two concurrent
+// refreshes with the same token cannot both win.
+func (r *GormSessionRepository) RotateToken(sessionID uint64, oldHash string, newHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", sessionID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  newHash,
+			"previous_token_hash": oldHash,
+			"expires_at":          expiresAt,
+			"last_used_at":        time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeSession marks a single session as revoked
+func (r *GormSessionRepository) RevokeSession(sessionID uint64) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserSession revokes a session only if it belongs to the given user.
+// It returns false if no matching active session was found.
+func (r *GormSessionRepository) RevokeUserSession(userID uint64, sessionID uint64) (bool, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeAllUserSessions revokes every active session of a user
+func (r *GormSessionRepository) RevokeAllUserSessions(userID uint64) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Content types listed in the sitemap
+const (
+	SitemapTypeNews      = "news"
+	SitemapTypeService   = "service"
+	SitemapTypePotential = "potential"
+)
+
+// sitemapEntries lists every public page backed by a single content row, which are the visible
+// news posts. Services and potentials have no page of their own; they are shown on /layanan
+// and /potensi.
+const sitemapEntries = `SELECT 'news' AS type, id, slug, updated_at FROM news
+		WHERE deleted_at IS NULL AND status = 'published' AND published_at <= now() AND (unpublish_at IS NULL OR unpublish_at > now())`
+
+// sitemapContent adds services and potentials to sitemapEntries, for the last change of the
+// pages that list them
+const sitemapContent = sitemapEntries + `
+	UNION ALL
+	SELECT 'service' AS type, id, NULL AS slug, updated_at FROM services WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT 'potential' AS type, id, NULL AS slug, updated_at FROM potentials WHERE deleted_at IS NULL`
+
+// SitemapEntry is a single content page for the sitemap
+type SitemapEntry struct {
+	Type      string
+	ID        uint64
+	Slug      string
+	UpdatedAt time.Time
+}
+
+// SitemapRepository defines the interface for the data listed in the sitemap
+type SitemapRepository interface {
+	CountEntries() (int64, error)
+	GetEntries(offset, limit int) ([]SitemapEntry, error)
+	GetLastModified() (map[string]time.Time, error)
+}
+
+// GormSitemapRepository implements SitemapRepository using GORM
+type GormSitemapRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSitemapRepository creates a new GormSitemapRepository
+func NewGormSitemapRepository(db *gorm.DB) SitemapRepository {
+	return &GormSitemapRepository{db: db}
+}
+
+// CountEntries returns the number of content pages in the sitemap
+func (r *GormSitemapRepository) CountEntries() (int64, error) {
+	var total int64
+	err := r.db.Raw("SELECT COUNT(*) FROM (" + sitemapEntries + ") entries").Scan(&total).Error
+	return total, err
+}
+
+// GetEntries returns a page of content entries, newest first. The ORDER BY makes paging through
+// them stable between requests.
+func (r *GormSitemapRepository) GetEntries(offset, limit int) ([]SitemapEntry, error) {
+	entries := []SitemapEntry{}
+	err := r.db.Raw(`SELECT * FROM (`+sitemapEntries+`) entries
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?`, limit, offset).Scan(&entries).Error
+	return entries, err
+}
+
+// GetLastModified returns, per content type, when its most recently changed entry was updated.
+// Types without entries are missing from the map.
+func (r *GormSitemapRepository) GetLastModified() (map[string]time.Time, error) {
+	var rows []struct {
+		Type      string
+		UpdatedAt time.Time
+	}
+	err := r.db.Raw("SELECT type, MAX(updated_at) AS updated_at FROM (" + sitemapContent + ") entries GROUP BY type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		lastModified[row.Type] = row.UpdatedAt
+	}
+	return lastModified, nil
+}
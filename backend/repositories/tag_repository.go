@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/ihsanularifinm/sid-seirotan/backend/models"
+	"gorm.io/gorm"
+)
+
+// TagRepository defines the interface for tag data operations
+type TagRepository interface {
+	GetAll() ([]models.Tag, error)
+	GetByID(id uint64) (*models.Tag, error)
+	GetByIDs(ids []uint64) ([]models.Tag, error)
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	Delete(id uint64) error
+	IsSlugExist(slug string, currentID uint64) (bool, error)
+}
+
+// GormTagRepository implements TagRepository using GORM
+type GormTagRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTagRepository creates a new GormTagRepository
+func NewGormTagRepository(db *gorm.DB) TagRepository {
+	return &GormTagRepository{db: db}
+}
+
+// GetAll retrieves all tags ordered by name
+func (r *GormTagRepository) GetAll() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// GetByID retrieves a tag by its ID
+func (r *GormTagRepository) GetByID(id uint64) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByIDs retrieves the tags with the given IDs
+func (r *GormTagRepository) GetByIDs(ids []uint64) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&tags).Error
+	return tags, err
+}
+
+// Create creates a new tag
+func (r *GormTagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+// Update updates an existing tag
+func (r *GormTagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete deletes a tag and removes it from all posts
+func (r *GormTagRepository) Delete(id uint64) error {
+	return r.db.Delete(&models.Tag{}, id).Error
+}
+
+// IsSlugExist checks if a slug is already used by a different tag
+func (r *GormTagRepository) IsSlugExist(slug string, currentID uint64) (bool, error) {
+	var count int64
+	query := r.db.Model(&models.Tag{}).Where("slug = ?", slug)
+	if currentID != 0 {
+		query = query.Where("id != ?", currentID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
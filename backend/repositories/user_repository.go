@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ihsanularifinm/sid-seirotan/backend/models"
 	"golang.org/x/crypto/bcrypt"
@@ -14,11 +15,19 @@ import (
 type UserRepository interface {
 	CreateUser(user *models.User) error
 	GetUserByUsername(username string) (*models.User, error)
+	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id uint64) (*models.User, error)
 	GetAllUsers(requestingRole models.UserRole) ([]models.User, error)
 	UpdateUser(user *models.User) error
 	DeleteUser(id uint64) error
 	ChangePassword(userID uint64, currentPassword string, newPassword string) error
+	RecordFailedLogin(userID uint64, maxAttempts int, lockout time.Duration) (*models.User, error)
+	ResetFailedLogins(userID uint64) error
+	SetPassword(userID uint64, newPassword string) error
+	SetTwoFactorSecret(userID uint64, encryptedSecret string) error
+	EnableTwoFactor(userID uint64, step int64) error
+	DisableTwoFactor(userID uint64) error
+	MarkTOTPStepUsed(userID uint64, step int64) (bool, error)
 	SeedSuperadmin()
 	SeedDefaultAdmin()
 }
@@ -52,6 +61,13 @@ func (r *GormUserRepository) GetUserByUsername(username string) (*models.User, e
 	return &user, err
 }
 
+// GetUserByEmail retrieves a user by their email address (case-insensitive)
+func (r *GormUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	return &user, err
+}
+
 // GetUserByID retrieves a user by their ID
 func (r *GormUserRepository) GetUserByID(id uint64) (*models.User, error) {
 	var user models.User
@@ -192,3 +208,88 @@ func (r *GormUserRepository) SeedDefaultAdmin() {
 		log.Println("User 'admin' already exists.")
 	}
 }
+
+// RecordFailedLogin increments the consecutive failed login counter of a user and
+// locks the account for the lockout duration once maxAttempts is reached. Once a lockout
+// has expired, the counter starts again from this failure instead of locking at once.
+// The counter is updated in a single statement so concurrent attempts are all counted.
+func (r *GormUserRepository) RecordFailedLogin(userID uint64, maxAttempts int, lockout time.Duration) (*models.User, error) {
+	now := time.Now()
+	attempts := gorm.Expr("CASE WHEN locked_until <= ?::timestamptz THEN 1 ELSE failed_login_attempts + 1 END", now)
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_attempts": attempts,
+		"last_failed_login_at":  now,
+		"locked_until": gorm.Expr("CASE WHEN ? >= ? THEN ?::timestamptz WHEN locked_until <= ?::timestamptz THEN NULL ELSE locked_until END",
+			attempts, maxAttempts, now.Add(lockout), now),
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+	return r.GetUserByID(userID)
+}
+
+// ResetFailedLogins clears the failed login counter and any lockout of a user
+func (r *GormUserRepository) ResetFailedLogins(userID uint64) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+	}).Error
+}
+
+// SetTwoFactorSecret stores a pending (not yet enabled) encrypted TOTP secret for a user
+func (r *GormUserRepository) SetTwoFactorSecret(userID uint64, encryptedSecret string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"two_factor_secret":    encryptedSecret,
+		"two_factor_enabled":   false,
+		"two_factor_last_step": 0,
+	}).Error
+}
+
+// EnableTwoFactor turns on 2FA for a user after the pending secret has been confirmed.
+// step is the TOTP time step of the confirmation code, so it cannot be reused to log in.
+func (r *GormUserRepository) EnableTwoFactor(userID uint64, step int64) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"two_factor_enabled":   true,
+		"two_factor_last_step": step,
+	}).Error
+}
+
+// DisableTwoFactor turns off 2FA for a user and removes the secret and recovery codes
+func (r *GormUserRepository) DisableTwoFactor(userID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_enabled":   false,
+			"two_factor_secret":    nil,
+			"two_factor_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+	})
+}
+
+// MarkTOTPStepUsed records a TOTP time step as used. It returns false if the same or a
+// later step was already accepted, which means the code is being replayed.
+func (r *GormUserRepository) MarkTOTPStepUsed(userID uint64, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", userID, step).
+		Update("two_factor_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetPassword replaces a user's password without checking the current one (used by password reset).
+// It also clears any lockout, since the user has just proven access through the reset link.
+func (r *GormUserRepository) SetPassword(userID uint64, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash new password: %w", err)
+	}
+
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":         string(hashedPassword),
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+	}).Error
+}
@@ -2,25 +2,34 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ihsanularifinm/sid-seirotan/backend/config"
 	"github.com/ihsanularifinm/sid-seirotan/backend/handlers"
 	"github.com/ihsanularifinm/sid-seirotan/backend/middlewares"
+	"github.com/ihsanularifinm/sid-seirotan/backend/repositories"
 )
 
 // SetupPublicRoutes configures all public-facing API routes
-func SetupPublicRoutes(public *gin.RouterGroup, newsHandler *handlers.NewsHandler, villageOfficialHandler *handlers.VillageOfficialHandler, potentialHandler *handlers.PotentialHandler, contactHandler *handlers.ContactHandler, serviceHandler *handlers.ServiceHandler, heroSliderHandler *handlers.HeroSliderHandler, siteSettingsHandler *handlers.SiteSettingsHandler, pageViewRepo interface{}) {
+func SetupPublicRoutes(public *gin.RouterGroup, newsHandler *handlers.NewsHandler, villageOfficialHandler *handlers.VillageOfficialHandler, potentialHandler *handlers.PotentialHandler, contactHandler *handlers.ContactHandler, serviceHandler *handlers.ServiceHandler, heroSliderHandler *handlers.HeroSliderHandler, siteSettingsHandler *handlers.SiteSettingsHandler, categoryHandler *handlers.CategoryHandler, tagHandler *handlers.TagHandler, searchHandler *handlers.SearchHandler, feedHandler *handlers.FeedHandler, metaHandler *handlers.MetaHandler, uploadHandler *handlers.UploadHandler, analyticsHandler *handlers.AnalyticsHandler) {
 	// Apply rate limiting: 5 requests per second, with a burst of 10
 	public.Use(middlewares.RateLimitMiddleware(5, 10))
-	
-	// Apply analytics tracking middleware
-	// public.Use(middlewares.AnalyticsMiddleware(pageViewRepo))
 
-	public.Static("/uploads", "uploads")
+	public.GET("/uploads/*filepath", middlewares.UploadHeadersMiddleware(), uploadHandler.ServeUpload)
+	public.HEAD("/uploads/*filepath", middlewares.UploadHeadersMiddleware(), uploadHandler.ServeUpload)
 	public.GET("/posts", newsHandler.GetPublishedNews)
 	public.GET("/posts/:id", newsHandler.GetNewsByID)
 	public.GET("/posts/slug/:slug", newsHandler.GetNewsBySlug)
+	public.GET("/categories", categoryHandler.GetPublicCategories)
+	public.GET("/tags", tagHandler.GetAllTags)
+	public.GET("/search", searchHandler.Search)
+	public.GET("/feed.rss", feedHandler.GetRSS)
+	public.GET("/feed.atom", feedHandler.GetAtom)
+	public.GET("/meta", metaHandler.GetHomeMetadata)
+	public.GET("/meta/posts/:slug", metaHandler.GetNewsMetadata)
+	public.GET("/meta/services/:id", metaHandler.GetServiceMetadata)
 	public.GET("/officials", villageOfficialHandler.GetAllVillageOfficials)
 	public.GET("/potentials", potentialHandler.GetAllPotentials)
 	public.POST("/contacts", contactHandler.CreateContact)
+	public.POST("/track", analyticsHandler.TrackPageView)
 
 	// Service Routes
 	serviceRoutes := public.Group("/services")
@@ -37,41 +46,120 @@ func SetupPublicRoutes(public *gin.RouterGroup, newsHandler *handlers.NewsHandle
 	public.GET("/settings/:group", siteSettingsHandler.GetByGroup)
 }
 
+// SetupSEORoutes configures the files search engines expect at the root of the site
+func SetupSEORoutes(router *gin.Engine, sitemapHandler *handlers.SitemapHandler) {
+	router.GET("/robots.txt", sitemapHandler.GetRobots)
+	router.GET("/sitemap.xml", sitemapHandler.GetSitemap)
+	router.GET("/sitemaps/:file", sitemapHandler.GetSitemapPage)
+}
+
 // SetupAuthRoutes configures all authentication-related API routes
-func SetupAuthRoutes(auth *gin.RouterGroup, authHandler *handlers.AuthHandler) {
+func SetupAuthRoutes(auth *gin.RouterGroup, authHandler *handlers.AuthHandler, passwordResetHandler *handlers.PasswordResetHandler) {
 	auth.POST("/login", authHandler.Login)
+	auth.POST("/refresh", authHandler.Refresh)
+	auth.POST("/logout", authHandler.Logout)
+
+	// Second step of the login for accounts with two-factor authentication
+	auth.POST("/2fa/verify", authHandler.VerifyTwoFactor)
+	auth.POST("/2fa/setup", authHandler.SetupTwoFactorChallenge)
+	auth.POST("/2fa/enable", authHandler.EnableTwoFactorChallenge)
+
+	// Self-service password reset (rate limited, it sends email)
+	auth.POST("/password/forgot", middlewares.RateLimitMiddleware(0.2, 3), passwordResetHandler.ForgotPassword)
+	auth.POST("/password/reset", middlewares.RateLimitMiddleware(1, 5), passwordResetHandler.ResetPassword)
 }
 
 // SetupAdminRoutes configures all admin-facing API routes
-func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler, newsHandler *handlers.NewsHandler, villageOfficialHandler *handlers.VillageOfficialHandler, serviceHandler *handlers.ServiceHandler, potentialHandler *handlers.PotentialHandler, contactHandler *handlers.ContactHandler, heroSliderHandler *handlers.HeroSliderHandler, siteSettingsHandler *handlers.SiteSettingsHandler, dashboardHandler *handlers.DashboardHandler) {
+func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler, newsHandler *handlers.NewsHandler, villageOfficialHandler *handlers.VillageOfficialHandler, serviceHandler *handlers.ServiceHandler, potentialHandler *handlers.PotentialHandler, contactHandler *handlers.ContactHandler, heroSliderHandler *handlers.HeroSliderHandler, siteSettingsHandler *handlers.SiteSettingsHandler, dashboardHandler *handlers.DashboardHandler, authHandler *handlers.AuthHandler, passwordResetHandler *handlers.PasswordResetHandler, roleHandler *handlers.RoleHandler, categoryHandler *handlers.CategoryHandler, tagHandler *handlers.TagHandler, searchHandler *handlers.SearchHandler, uploadHandler *handlers.UploadHandler, mediaHandler *handlers.MediaHandler, sessionRepo repositories.SessionRepository, roleRepo repositories.RoleRepository) {
+	authMiddleware := middlewares.AuthMiddleware(sessionRepo)
+	require := func(permission string) gin.HandlerFunc {
+		return middlewares.RequirePermission(roleRepo, permission)
+	}
+
 	// Upload endpoints
-	admin.POST("/upload", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"), handlers.UploadFile)
-	admin.POST("/upload-with-naming", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"), handlers.UploadWithNaming)
+	admin.POST("/upload", authMiddleware, require(config.PermissionMediaUpload), uploadHandler.UploadFile)
+	admin.POST("/upload-with-naming", authMiddleware, require(config.PermissionMediaUpload), uploadHandler.UploadWithNaming)
+
+	// Media Library Routes
+	mediaRoutes := admin.Group("/media")
+	mediaRoutes.Use(authMiddleware, require(config.PermissionMediaUpload))
+	{
+		mediaRoutes.GET("", mediaHandler.GetAllMedia)
+		mediaRoutes.GET("/:id", mediaHandler.GetMediaByID)
+		mediaRoutes.PUT("/:id", require(config.PermissionMediaManage), mediaHandler.UpdateMedia)
+		mediaRoutes.DELETE("/:id", require(config.PermissionMediaManage), mediaHandler.DeleteMedia)
+
+		// Unused upload garbage collection (dry run by default)
+		mediaRoutes.POST("/gc", require(config.PermissionMediaManage), mediaHandler.CollectUnusedUploads)
+		mediaRoutes.POST("/gc/restore", require(config.PermissionMediaManage), mediaHandler.RestoreQuarantinedUpload)
+	}
 
 	// User Management Routes
 	userRoutes := admin.Group("/users")
-	userRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	userRoutes.Use(authMiddleware, require(config.PermissionUsersManage))
 	{
 		userRoutes.POST("", userHandler.CreateUser)
 		userRoutes.GET("", userHandler.GetAllUsers)
 		userRoutes.GET("/:id", userHandler.GetUserByID)
 		userRoutes.PUT("/:id", userHandler.UpdateUser)
 		userRoutes.DELETE("/:id", userHandler.DeleteUser)
+		userRoutes.GET("/:id/sessions", userHandler.GetUserSessions)
+		userRoutes.DELETE("/:id/sessions", userHandler.RevokeAllUserSessions)
+		userRoutes.DELETE("/:id/sessions/:sessionId", userHandler.RevokeUserSession)
+		userRoutes.POST("/:id/unlock", userHandler.UnlockUser)
+		userRoutes.GET("/:id/login-attempts", userHandler.GetUserLoginAttempts)
+		userRoutes.DELETE("/:id/2fa", require(config.PermissionUsersSecurity), authHandler.ResetUserTwoFactor)
+		userRoutes.POST("/:id/password-reset", require(config.PermissionUsersSecurity), passwordResetHandler.CreateResetLinkForUser)
 	}
 
 	// News Management Routes
 	newsRoutes := admin.Group("/posts")
-	newsRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin", "author"))
+	newsRoutes.Use(authMiddleware, require(config.PermissionNewsWrite))
 	{
 		newsRoutes.GET("", newsHandler.GetAllNewsForAdmin)
 		newsRoutes.POST("", newsHandler.CreateNews)
+		newsRoutes.GET("/:id", newsHandler.GetNewsForAdmin)
 		newsRoutes.PUT("/:id", newsHandler.UpdateNews)
 		newsRoutes.DELETE("/:id", newsHandler.DeleteNews)
+		newsRoutes.GET("/:id/history", newsHandler.GetNewsHistory)
+
+		// Revision history
+		newsRoutes.GET("/:id/revisions", newsHandler.GetNewsRevisions)
+		newsRoutes.GET("/:id/revisions/diff", newsHandler.DiffNewsRevisions)
+		newsRoutes.GET("/:id/revisions/:revision", newsHandler.GetNewsRevision)
+		newsRoutes.POST("/:id/revisions/:revision/restore", newsHandler.RestoreNewsRevision)
+
+		// Editorial review workflow
+		newsRoutes.POST("/:id/submit", newsHandler.SubmitNewsForReview)
+		newsRoutes.GET("/review-queue", require(config.PermissionNewsPublish), newsHandler.GetReviewQueue)
+		newsRoutes.POST("/:id/approve", require(config.PermissionNewsPublish), newsHandler.ApproveNews)
+		newsRoutes.POST("/:id/reject", require(config.PermissionNewsPublish), newsHandler.RejectNews)
+
+		// Upcoming scheduled publications and unpublications
+		newsRoutes.GET("/scheduled", require(config.PermissionNewsPublish), newsHandler.GetScheduledChanges)
+	}
+
+	// Category & Tag Management Routes
+	categoryAdminRoutes := admin.Group("/categories")
+	categoryAdminRoutes.Use(authMiddleware, require(config.PermissionCategoriesWrite))
+	{
+		categoryAdminRoutes.GET("", categoryHandler.GetAllCategories)
+		categoryAdminRoutes.POST("", categoryHandler.CreateCategory)
+		categoryAdminRoutes.PUT("/:id", categoryHandler.UpdateCategory)
+		categoryAdminRoutes.DELETE("/:id", categoryHandler.DeleteCategory)
+	}
+	tagAdminRoutes := admin.Group("/tags")
+	tagAdminRoutes.Use(authMiddleware, require(config.PermissionCategoriesWrite))
+	{
+		tagAdminRoutes.GET("", tagHandler.GetAllTags)
+		tagAdminRoutes.POST("", tagHandler.CreateTag)
+		tagAdminRoutes.PUT("/:id", tagHandler.UpdateTag)
+		tagAdminRoutes.DELETE("/:id", tagHandler.DeleteTag)
 	}
 
 	// Add other admin routes here as they are implemented
 	villageOfficialRoutes := admin.Group("/officials")
-	villageOfficialRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	villageOfficialRoutes.Use(authMiddleware, require(config.PermissionOfficialsWrite))
 	{
 		villageOfficialRoutes.POST("", villageOfficialHandler.CreateVillageOfficial)
 		villageOfficialRoutes.GET("/:id", villageOfficialHandler.GetVillageOfficialByID)
@@ -81,7 +169,7 @@ func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler,
 			
 	// Service Management Routes
 	serviceAdminRoutes := admin.Group("/services")
-	serviceAdminRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	serviceAdminRoutes.Use(authMiddleware, require(config.PermissionServicesWrite))
 	{
 		serviceAdminRoutes.GET("", serviceHandler.GetAllServices)
 		serviceAdminRoutes.POST("", serviceHandler.CreateService)
@@ -91,7 +179,7 @@ func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler,
 
 	// Potential Management Routes
 	potentialAdminRoutes := admin.Group("/potentials")
-	potentialAdminRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	potentialAdminRoutes.Use(authMiddleware, require(config.PermissionPotentialsWrite))
 	{
 		potentialAdminRoutes.POST("", potentialHandler.CreatePotential)
 		potentialAdminRoutes.PUT("/:id", potentialHandler.UpdatePotential)
@@ -100,14 +188,21 @@ func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler,
 
 	// Contact Management Routes
 	contactAdminRoutes := admin.Group("/contacts")
-	contactAdminRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	contactAdminRoutes.Use(authMiddleware, require(config.PermissionContactsRead))
 	{
 		contactAdminRoutes.GET("", contactHandler.GetAllContacts)
+		contactAdminRoutes.GET("/:id", contactHandler.GetContactByID)
+		contactAdminRoutes.PUT("/:id", require(config.PermissionContactsManage), contactHandler.UpdateContact)
+		contactAdminRoutes.POST("/:id/read", require(config.PermissionContactsManage), contactHandler.MarkContactRead)
+		contactAdminRoutes.POST("/:id/notes", require(config.PermissionContactsManage), contactHandler.AddContactNote)
+		contactAdminRoutes.POST("/mark-read", require(config.PermissionContactsManage), contactHandler.MarkContactsRead)
+		contactAdminRoutes.DELETE("/:id", require(config.PermissionContactsManage), contactHandler.DeleteContact)
+		contactAdminRoutes.POST("/bulk-delete", require(config.PermissionContactsManage), contactHandler.BulkDeleteContacts)
 	}
 
 	// Hero Slider Management Routes
 	heroSliderAdminRoutes := admin.Group("/hero-sliders")
-	heroSliderAdminRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	heroSliderAdminRoutes.Use(authMiddleware, require(config.PermissionHeroSlidersWrite))
 	{
 		heroSliderAdminRoutes.GET("", heroSliderHandler.GetAll)
 		heroSliderAdminRoutes.GET("/:id", heroSliderHandler.GetByID)
@@ -118,7 +213,7 @@ func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler,
 
 	// Site Settings Management Routes
 	settingsAdminRoutes := admin.Group("/settings")
-	settingsAdminRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	settingsAdminRoutes.Use(authMiddleware, require(config.PermissionSettingsWrite))
 	{
 		settingsAdminRoutes.GET("", siteSettingsHandler.GetAllAdmin)
 		settingsAdminRoutes.PUT("", siteSettingsHandler.BulkUpdate)
@@ -127,16 +222,40 @@ func SetupAdminRoutes(admin *gin.RouterGroup, userHandler *handlers.UserHandler,
 
 	// Profile Management Routes
 	profileRoutes := admin.Group("/profile")
-	profileRoutes.Use(middlewares.AuthMiddleware())
+	profileRoutes.Use(authMiddleware)
 	{
 		profileRoutes.PUT("/change-password", userHandler.ChangePassword)
+		profileRoutes.GET("/2fa", authHandler.GetTwoFactorStatus)
+		profileRoutes.POST("/2fa/setup", authHandler.SetupTwoFactor)
+		profileRoutes.POST("/2fa/enable", authHandler.EnableTwoFactor)
+		profileRoutes.POST("/2fa/disable", authHandler.DisableTwoFactor)
+		profileRoutes.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
 	}
 
+	// Role & Permission Management Routes
+	roleRoutes := admin.Group("/roles")
+	roleRoutes.Use(authMiddleware, require(config.PermissionRolesManage))
+	{
+		roleRoutes.GET("", roleHandler.GetAllRoles)
+		roleRoutes.GET("/permissions", roleHandler.GetPermissions)
+		roleRoutes.GET("/:id", roleHandler.GetRoleByID)
+		roleRoutes.POST("", roleHandler.CreateRole)
+		roleRoutes.PUT("/:id", roleHandler.UpdateRole)
+		roleRoutes.DELETE("/:id", roleHandler.DeleteRole)
+	}
+
+	// Search across all content, including unpublished news
+	admin.GET("/search", authMiddleware, require(config.PermissionDashboardRead), searchHandler.SearchAdmin)
+
 	// Dashboard Routes
 	dashboardRoutes := admin.Group("/dashboard")
-	dashboardRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin", "superadmin"))
+	dashboardRoutes.Use(authMiddleware, require(config.PermissionDashboardRead))
 	{
 		dashboardRoutes.GET("/stats", dashboardHandler.GetStats)
+		dashboardRoutes.GET("/analytics", dashboardHandler.GetAnalytics)
+		dashboardRoutes.GET("/analytics/sources", dashboardHandler.GetAnalyticsSources)
+		dashboardRoutes.GET("/analytics/devices", dashboardHandler.GetAnalyticsDevices)
+		dashboardRoutes.GET("/analytics/campaigns", dashboardHandler.GetAnalyticsCampaigns)
 	}
 
 }
\ No newline at end of file
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps uploads in a directory on the local disk, served by the backend
+// under /uploads/
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a LocalStorage rooted at dir
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// Put writes the file through a temporary file, so readers never see a partial upload
+func (s *LocalStorage) Put(key string, data []byte, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Get opens a file for reading
+func (s *LocalStorage) Get(key string) (io.ReadSeekCloser, *Object, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+
+	f, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, localObject(key, info), nil
+}
+
+// Delete removes a file
+func (s *LocalStorage) Delete(key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL returns the path under which the backend serves the file
+func (s *LocalStorage) URL(key string) string {
+	return UploadsURLPrefix + key
+}
+
+// List walks the directory for files whose key starts with prefix. Hidden files, such as
+// uploads still being written, are skipped.
+func (s *LocalStorage) List(prefix string) ([]Object, error) {
+	objects := []Object{}
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == s.dir {
+				return filepath.SkipDir // Nothing uploaded yet
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != s.dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *localObject(key, info))
+		return nil
+	})
+	return objects, err
+}
+
+// path maps a key to a file inside the storage directory
+func (s *LocalStorage) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func localObject(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(strings.ToLower(path.Ext(key))),
+		LastModified: info.ModTime(),
+	}
+}
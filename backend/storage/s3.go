@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Timeout bounds a single request to the object store
+const s3Timeout = 30 * time.Second
+
+// s3CacheControl lets browsers and a CDN in front of a public bucket keep files for a day
+const s3CacheControl = "public, max-age=86400"
+
+// S3Storage keeps uploads in a bucket of an S3-compatible object store (AWS S3, MinIO, ...)
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Storage connects to an S3-compatible endpoint (host[:port], without scheme) and creates
+// the bucket if it does not exist yet. publicURL is the base URL under which the bucket is
+// readable by browsers, e.g. a CDN; when empty, files are served by the backend under /uploads/.
+// Files linked from publicURL skip the backend: the headers that make them safe to open are
+// stored with each object instead, and the bucket must not expose the quarantine/ prefix.
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string, useSSL bool, publicURL string) (*S3Storage, error) {
+	if endpoint == "" || bucket == "" {
+		return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for the s3 storage driver")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking bucket %q: %w", bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+			return nil, fmt.Errorf("creating bucket %q: %w", bucket, err)
+		}
+	}
+
+	return &S3Storage{client: client, bucket: bucket, publicURL: strings.TrimRight(publicURL, "/")}, nil
+}
+
+// NewS3StorageFromEnv creates an S3Storage from S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY,
+// S3_SECRET_KEY, S3_USE_SSL (default true) and S3_PUBLIC_URL (optional)
+func NewS3StorageFromEnv() (*S3Storage, error) {
+	return NewS3Storage(
+		os.Getenv("S3_ENDPOINT"),
+		os.Getenv("S3_REGION"),
+		os.Getenv("S3_BUCKET"),
+		os.Getenv("S3_ACCESS_KEY"),
+		os.Getenv("S3_SECRET_KEY"),
+		!strings.EqualFold(os.Getenv("S3_USE_SSL"), "false"),
+		os.Getenv("S3_PUBLIC_URL"),
+	)
+}
+
+// Put uploads an object
+func (s *S3Storage) Put(key string, data []byte, contentType string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	// Stored with the object, so a public bucket sends them like the backend does
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:        contentType,
+		ContentDisposition: ContentDisposition(key),
+		CacheControl:       s3CacheControl,
+	})
+	return err
+}
+
+// Get opens an object for reading. Reads and seeks are fetched from the bucket on demand.
+func (s *S3Storage) Get(key string) (io.ReadSeekCloser, *Object, error) {
+	if !ValidKey(key) {
+		return nil, nil, ErrNotFound
+	}
+
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s3Error(err)
+	}
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, s3Error(err)
+	}
+	return object, s3Object(info), nil
+}
+
+// Delete removes an object
+func (s *S3Storage) Delete(key string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// URL returns the public bucket URL of an object, or its /uploads/ path on the backend. SVG
+// files are always served by the backend: a bucket cannot send the Content-Security-Policy
+// that keeps their scripts from running.
+func (s *S3Storage) URL(key string) string {
+	if s.publicURL != "" && !strings.EqualFold(path.Ext(key), ".svg") {
+		return s.publicURL + "/" + key
+	}
+	return UploadsURLPrefix + key
+}
+
+// List returns every object whose key starts with prefix
+func (s *S3Storage) List(prefix string) ([]Object, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*s3Timeout)
+	defer cancel()
+
+	objects := []Object{}
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, *s3Object(info))
+	}
+	return objects, nil
+}
+
+// s3Error maps a missing object to ErrNotFound
+func s3Error(err error) error {
+	response := minio.ToErrorResponse(err)
+	if response.StatusCode == http.StatusNotFound || response.Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
+
+func s3Object(info minio.ObjectInfo) *Object {
+	return &Object{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
+}
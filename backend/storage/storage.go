@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key
+var ErrNotFound = errors.New("storage: object not found")
+
+// Object describes a stored file
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage keeps uploaded files. Keys are slash-separated relative paths such as
+// "judul-berita-20241121-143022.jpg". Implementations are selected with the STORAGE_DRIVER
+// environment variable.
+type Storage interface {
+	// Put stores data under key, replacing any existing object
+	Put(key string, data []byte, contentType string) error
+	// Get opens an object for reading. The caller must close it.
+	Get(key string) (io.ReadSeekCloser, *Object, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(key string) error
+	// URL returns the URL the website uses to load an object
+	URL(key string) string
+	// List returns every object whose key starts with prefix
+	List(prefix string) ([]Object, error)
+}
+
+// UploadsURLPrefix is the path under which uploads are served by the backend
+const UploadsURLPrefix = "/uploads/"
+
+// QuarantinePrefix holds unused uploads waiting to be deleted. Files under it are not served.
+const QuarantinePrefix = "quarantine/"
+
+// NewStorageFromEnv creates the Storage configured by STORAGE_DRIVER:
+//   - "s3": an S3-compatible bucket such as AWS S3 or MinIO (see NewS3StorageFromEnv)
+//   - "local" (default): the UPLOAD_DIR directory on the local disk (default "uploads")
+func NewStorageFromEnv() (Storage, error) {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	switch driver {
+	case "s3":
+		return NewS3StorageFromEnv()
+	case "", "local":
+		dir := os.Getenv("UPLOAD_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStorage(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q (expected local or s3)", driver)
+	}
+}
+
+// KeyFromURL returns the key of an object from a URL returned by store.URL, or from a
+// "/uploads/..." URL saved before the storage was configured. The second result is false
+// for URLs that do not point to the storage, such as external images.
+func KeyFromURL(store Storage, url string) (string, bool) {
+	for _, prefix := range []string{store.URL(""), UploadsURLPrefix} {
+		if prefix != "" && strings.HasPrefix(url, prefix) {
+			key := strings.TrimPrefix(url, prefix)
+			if ValidKey(key) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// KeysInText returns the keys of every stored object linked from a text, such as a URL column
+// or the HTML of a post
+func KeysInText(store Storage, text string) []string {
+	var keys []string
+	for _, prefix := range []string{store.URL(""), UploadsURLPrefix} {
+		if prefix == "" || !strings.Contains(text, prefix) {
+			continue
+		}
+		pattern := regexp.MustCompile(regexp.QuoteMeta(prefix) + `[^\s"'<>()?#\\]+`)
+		for _, match := range pattern.FindAllString(text, -1) {
+			if key := strings.TrimPrefix(match, prefix); ValidKey(key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// Move copies an object to a new key and removes the original
+func Move(store Storage, from, to string) error {
+	file, object, err := store.Get(from)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	if err := store.Put(to, data, object.ContentType); err != nil {
+		return err
+	}
+	return store.Delete(from)
+}
+
+// inlineExtensions are the uploaded file types a browser may display inside the page
+var inlineExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+	".svg":  true,
+	".mp4":  true,
+	".webm": true,
+}
+
+// ContentDisposition returns the Content-Disposition header of a stored file: images and
+// videos are displayed, anything else is downloaded instead
+func ContentDisposition(key string) string {
+	filename := path.Base(key)
+	disposition := "attachment"
+	if inlineExtensions[strings.ToLower(path.Ext(filename))] {
+		disposition = "inline"
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+}
+
+// ValidKey reports whether a key is a clean relative path that cannot escape the storage root
+func ValidKey(key string) bool {
+	return key != "" &&
+		!strings.HasPrefix(key, "/") &&
+		!strings.Contains(key, "\\") &&
+		path.Clean(key) == key &&
+		key != ".." && !strings.HasPrefix(key, "../")
+}
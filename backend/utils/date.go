@@ -0,0 +1,9 @@
+package utils
+
+import "time"
+
+// StartOfDay returns the local midnight starting the day of t
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartOfDay(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	oldLocal := time.Local
+	time.Local = jakarta
+	defer func() { time.Local = oldLocal }()
+
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"Local afternoon", time.Date(2024, 5, 10, 15, 4, 5, 6, jakarta), time.Date(2024, 5, 10, 0, 0, 0, 0, jakarta)},
+		{"Local midnight", time.Date(2024, 5, 10, 0, 0, 0, 0, jakarta), time.Date(2024, 5, 10, 0, 0, 0, 0, jakarta)},
+		{"UTC evening is the next local day", time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC), time.Date(2024, 5, 11, 0, 0, 0, 0, jakarta)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StartOfDay(tt.in); !got.Equal(tt.want) {
+				t.Errorf("StartOfDay(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
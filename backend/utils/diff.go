@@ -0,0 +1,107 @@
+package utils
+
+import "strings"
+
+// Line operations returned by DiffLines
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffCells bounds the size of the LCS table built by DiffLines (about 8 MB)
+const maxDiffCells = 1 << 20
+
+// DiffLines compares two texts line by line and returns the lines that were kept,
+// removed from a and inserted from b, in order. It uses the longest common subsequence
+// of the lines between the common prefix and suffix; when that part is too large to
+// compare, it is reported as replaced in full.
+func DiffLines(a, b string) []DiffLine {
+	oldLines := splitLines(a)
+	newLines := splitLines(b)
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, max(len(oldLines), len(newLines)))
+	for _, line := range oldLines[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	diff = appendChangedLines(diff, oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix])
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// appendChangedLines appends the diff of two blocks of lines to diff
+func appendChangedLines(diff []DiffLine, oldLines, newLines []string) []DiffLine {
+	n, m := len(oldLines), len(newLines)
+	if n > 0 && m > 0 && n > maxDiffCells/m {
+		for _, line := range oldLines {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range newLines {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: oldLines[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: newLines[j]})
+	}
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []DiffLine
+	}{
+		{
+			name: "Identical texts",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+		{
+			name: "Line changed",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffDelete, "two"}, {DiffInsert, "2"}, {DiffEqual, "three"}},
+		},
+		{
+			name: "Lines appended",
+			a:    "one",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffInsert, "two"}},
+		},
+		{
+			name: "From empty text",
+			a:    "",
+			b:    "one",
+			want: []DiffLine{{DiffInsert, "one"}},
+		},
+		{
+			name: "Windows line endings",
+			a:    "one\r\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeChange(t *testing.T) {
+	var oldText, newText strings.Builder
+	oldText.WriteString("same")
+	newText.WriteString("same")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&oldText, "\nold %d", i)
+		fmt.Fprintf(&newText, "\nnew %d", i)
+	}
+
+	diff := DiffLines(oldText.String(), newText.String())
+	if len(diff) != 4001 {
+		t.Fatalf("DiffLines() returned %d lines, want 4001", len(diff))
+	}
+	if diff[0] != (DiffLine{DiffEqual, "same"}) {
+		t.Errorf("DiffLines() first line = %v, want the common prefix", diff[0])
+	}
+	if diff[1] != (DiffLine{DiffDelete, "old 0"}) || diff[2000] != (DiffLine{DiffDelete, "old 1999"}) {
+		t.Errorf("DiffLines() did not delete the old lines first")
+	}
+	if diff[2001] != (DiffLine{DiffInsert, "new 0"}) || diff[4000] != (DiffLine{DiffInsert, "new 1999"}) {
+		t.Errorf("DiffLines() did not insert the new lines last")
+	}
+}
@@ -105,3 +105,24 @@ func GenerateStrukturFilename(originalFilename string) string {
 	timestamp := time.Now().Format("20060102-150405")
 	return fmt.Sprintf("struktur-organisasi-%s%s", timestamp, ext)
 }
+
+// GenerateVariantFilename names a resized copy of an uploaded image by adding the variant name
+// before the extension, e.g. "judul-berita-20241121-143022.jpg" becomes
+// "judul-berita-20241121-143022-thumb.webp"
+func GenerateVariantFilename(filename string, variant string, extension string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	return fmt.Sprintf("%s-%s%s", base, variant, extension)
+}
+
+// VariantBase returns the name shared by an uploaded file and its variants: the filename
+// without extension and without a trailing "-<variant>" for one of the given variant names.
+// Both "judul-20241121-143022.jpg" and "judul-20241121-143022-thumb.webp" give "judul-20241121-143022".
+func VariantBase(filename string, variants ...string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for _, variant := range variants {
+		if trimmed := strings.TrimSuffix(base, "-"+variant); trimmed != base && trimmed != "" {
+			return trimmed
+		}
+	}
+	return base
+}
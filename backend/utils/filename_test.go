@@ -303,3 +303,102 @@ func TestFilenameUniqueness(t *testing.T) {
 		t.Errorf("Second filename invalid: %v", filename2)
 	}
 }
+
+func TestGenerateVariantFilename(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		variant   string
+		extension string
+		want      string
+	}{
+		{
+			name:      "Same extension",
+			filename:  "judul-berita-20241121-143022.jpg",
+			variant:   "thumb",
+			extension: ".jpg",
+			want:      "judul-berita-20241121-143022-thumb.jpg",
+		},
+		{
+			name:      "Different extension",
+			filename:  "judul-berita-20241121-143022.jpeg",
+			variant:   "large",
+			extension: ".webp",
+			want:      "judul-berita-20241121-143022-large.webp",
+		},
+		{
+			name:      "Logo",
+			filename:  "logo.png",
+			variant:   "thumb",
+			extension: ".png",
+			want:      "logo-thumb.png",
+		},
+		{
+			name:      "No extension",
+			filename:  "file",
+			variant:   "medium",
+			extension: ".jpg",
+			want:      "file-medium.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GenerateVariantFilename(tt.filename, tt.variant, tt.extension)
+
+			if result != tt.want {
+				t.Errorf("GenerateVariantFilename() = %v, want %v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantBase(t *testing.T) {
+	variants := []string{"thumb", "medium", "large"}
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{
+			name:     "Original",
+			filename: "judul-berita-20241121-143022.jpg",
+			want:     "judul-berita-20241121-143022",
+		},
+		{
+			name:     "Variant",
+			filename: "judul-berita-20241121-143022-thumb.webp",
+			want:     "judul-berita-20241121-143022",
+		},
+		{
+			name:     "Variant with other extension",
+			filename: "logo-medium.png",
+			want:     "logo",
+		},
+		{
+			name:     "Unknown suffix is kept",
+			filename: "foto-kecil.jpg",
+			want:     "foto-kecil",
+		},
+		{
+			name:     "Name equal to a variant",
+			filename: "-thumb.jpg",
+			want:     "-thumb",
+		},
+		{
+			name:     "Without extension",
+			filename: "struktur-organisasi-large",
+			want:     "struktur-organisasi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VariantBase(tt.filename, variants...)
+
+			if result != tt.want {
+				t.Errorf("VariantBase() = %v, want %v", result, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	// Decoders for the formats accepted by image.Decode
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxImagePixels is the largest image (width x height) that will be decoded. It protects the
+// server from tiny files that expand into huge bitmaps.
+const MaxImagePixels = 50_000_000
+
+// DecodeImage decodes a JPEG, PNG, GIF or WebP image and turns it upright according to its
+// EXIF orientation. The returned format is the name reported by the image package, e.g. "jpeg".
+func DecodeImage(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, "", fmt.Errorf("image of %dx%d pixels is too large", cfg.Width, cfg.Height)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return ApplyOrientation(img, ExifOrientation(data)), format, nil
+}
+
+// ExifOrientation reads the EXIF orientation (1-8) of a JPEG, PNG or WebP file. It returns 1,
+// meaning "already upright", when the file has no readable orientation.
+func ExifOrientation(data []byte) int {
+	var tiff []byte
+	jpegSegments(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			tiff = segment[6:]
+			return false
+		}
+		return true
+	})
+	pngChunks(data, func(chunkType string, chunk []byte) bool {
+		if chunkType == "eXIf" {
+			tiff = chunk
+			return false
+		}
+		return true
+	})
+	webpChunks(data, func(chunkType string, chunk []byte) bool {
+		if chunkType == "EXIF" {
+			// Some writers keep the "Exif" header of the JPEG segment
+			tiff = bytes.TrimPrefix(chunk, []byte("Exif\x00\x00"))
+			return false
+		}
+		return true
+	})
+	if tiff == nil {
+		return 1
+	}
+	return tiffOrientation(tiff)
+}
+
+// HasJPEGMetadata reports whether a JPEG file carries EXIF, XMP or IPTC metadata, which can
+// include the GPS position of the camera and the name of its owner
+func HasJPEGMetadata(data []byte) bool {
+	found := false
+	jpegSegments(data, func(marker byte, segment []byte) bool {
+		found = marker == 0xE1 || marker == 0xED // APP1 (EXIF, XMP) or APP13 (IPTC)
+		return !found
+	})
+	return found
+}
+
+// HasPNGMetadata reports whether a PNG file carries EXIF or text chunks. Text chunks hold
+// XMP and free-form fields such as the author or the place a picture was taken.
+func HasPNGMetadata(data []byte) bool {
+	found := false
+	pngChunks(data, func(chunkType string, chunk []byte) bool {
+		found = chunkType == "eXIf" || chunkType == "tEXt" || chunkType == "iTXt" || chunkType == "zTXt"
+		return !found
+	})
+	return found
+}
+
+// HasWebPMetadata reports whether a WebP file carries EXIF or XMP metadata
+func HasWebPMetadata(data []byte) bool {
+	found := false
+	webpChunks(data, func(chunkType string, chunk []byte) bool {
+		found = chunkType == "EXIF" || chunkType == "XMP "
+		return !found
+	})
+	return found
+}
+
+// jpegSegments calls visit for every marker segment before the image data of a JPEG file,
+// until visit returns false. Malformed files simply end the walk.
+func jpegSegments(data []byte, visit func(marker byte, segment []byte) bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return
+		}
+		marker := data[pos+1]
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2 // Markers without a length
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			return // Start of the image data
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return
+		}
+		if !visit(marker, data[pos+4:pos+2+length]) {
+			return
+		}
+		pos += 2 + length
+	}
+}
+
+// pngChunks calls visit for every chunk of a PNG file, until visit returns false. Malformed
+// files simply end the walk.
+func pngChunks(data []byte, visit func(chunkType string, chunk []byte) bool) {
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return
+	}
+
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length > len(data)-pos-12 {
+			return
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if !visit(chunkType, data[pos+8:pos+8+length]) || chunkType == "IEND" {
+			return
+		}
+		pos += 12 + length // Length, type, data and CRC
+	}
+}
+
+// webpChunks calls visit for every chunk inside the RIFF container of a WebP file, until visit
+// returns false. Malformed files simply end the walk.
+func webpChunks(data []byte, visit func(chunkType string, chunk []byte) bool) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return
+	}
+
+	pos := 12
+	for pos+8 <= len(data) {
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if length > len(data)-pos-8 {
+			return
+		}
+		if !visit(string(data[pos:pos+4]), data[pos+8:pos+8+length]) {
+			return
+		}
+		pos += 8 + length + length%2 // Chunks are padded to an even length
+	}
+}
+
+// tiffOrientation finds the orientation tag in the first IFD of an EXIF TIFF structure
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		const orientationTag, shortType = 0x0112, 3
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == shortType {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// ApplyOrientation rotates and flips an image so that an EXIF orientation of 2-8 is displayed
+// upright. Orientation 1 and unknown values return the image unchanged.
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w // 90 degree rotations swap width and height
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // Mirrored along the top-left diagonal
+				sx, sy = y, x
+			case 6: // Needs a 90 degree clockwise rotation
+				sx, sy = y, h-1-x
+			case 7: // Mirrored along the top-right diagonal
+				sx, sy = w-1-y, h-1-x
+			case 8: // Needs a 90 degree counter-clockwise rotation
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// ResizeToFit scales an image down so it fits within maxWidth x maxHeight, keeping its aspect
+// ratio. Images that already fit are returned unchanged; images are never enlarged.
+func ResizeToFit(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return img
+	}
+
+	scale := min(float64(maxWidth)/float64(w), float64(maxHeight)/float64(h))
+	nw := max(int(float64(w)*scale+0.5), 1)
+	nh := max(int(float64(h)*scale+0.5), 1)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, nw, nh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// IsOpaque reports whether an image has no transparent pixels
+func IsOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xFFFF {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EncodeJPEG encodes an image as JPEG. Transparent areas become white. The encoder writes no
+// EXIF or other metadata.
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	if !IsOpaque(img) {
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		img = flat
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodePNG encodes an image as PNG with the best compression
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
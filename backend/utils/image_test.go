@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// exifTIFF builds an EXIF TIFF structure holding the given orientation
+func exifTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8) // IFD0 right after the header
+	order.PutUint16(tiff[8:], 1) // One entry
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return tiff
+}
+
+// exifJPEG builds the start of a JPEG file with an EXIF segment holding the given orientation
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	segment := append([]byte("Exif\x00\x00"), exifTIFF(order, orientation)...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2)
+}
+
+// pngWithChunk builds a 1x1 PNG file with an extra chunk before the image data
+func pngWithChunk(chunkType string, chunk []byte) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+
+	extra := binary.BigEndian.AppendUint32(nil, uint32(len(chunk)))
+	extra = append(extra, chunkType...)
+	extra = append(extra, chunk...)
+	extra = binary.BigEndian.AppendUint32(extra, crc32.ChecksumIEEE(extra[4:]))
+
+	ihdrEnd := 8 + 25 // Signature, then the IHDR chunk with its 13 bytes of data
+	return append(append(append([]byte{}, data[:ihdrEnd]...), extra...), data[ihdrEnd:]...)
+}
+
+// webpWithChunk builds the RIFF container of a WebP file with an extended header and one
+// extra chunk
+func webpWithChunk(chunkType string, chunk []byte) []byte {
+	var body []byte
+	for _, c := range []struct {
+		chunkType string
+		data      []byte
+	}{{"VP8X", make([]byte, 10)}, {chunkType, chunk}} {
+		body = append(body, c.chunkType...)
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(c.data)))
+		body = append(body, c.data...)
+		if len(c.data)%2 == 1 {
+			body = append(body, 0)
+		}
+	}
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(body)))...)
+	return append(append(data, "WEBP"...), body...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{
+			name: "Little endian",
+			data: exifJPEG(binary.LittleEndian, 6),
+			want: 6,
+		},
+		{
+			name: "Big endian",
+			data: exifJPEG(binary.BigEndian, 3),
+			want: 3,
+		},
+		{
+			name: "Invalid orientation",
+			data: exifJPEG(binary.LittleEndian, 42),
+			want: 1,
+		},
+		{
+			name: "No EXIF segment",
+			data: []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2},
+			want: 1,
+		},
+		{
+			name: "Not a JPEG",
+			data: []byte("\x89PNG\r\n\x1a\n"),
+			want: 1,
+		},
+		{
+			name: "PNG eXIf chunk",
+			data: pngWithChunk("eXIf", exifTIFF(binary.BigEndian, 8)),
+			want: 8,
+		},
+		{
+			name: "WebP EXIF chunk",
+			data: webpWithChunk("EXIF", exifTIFF(binary.LittleEndian, 6)),
+			want: 6,
+		},
+		{
+			name: "WebP EXIF chunk with a JPEG header",
+			data: webpWithChunk("EXIF", append([]byte("Exif\x00\x00"), exifTIFF(binary.LittleEndian, 3)...)),
+			want: 3,
+		},
+		{
+			name: "Truncated segment",
+			data: exifJPEG(binary.LittleEndian, 6)[:12],
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExifOrientation(tt.data); got != tt.want {
+				t.Errorf("ExifOrientation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasJPEGMetadata(t *testing.T) {
+	if !HasJPEGMetadata(exifJPEG(binary.LittleEndian, 1)) {
+		t.Error("HasJPEGMetadata() = false for a JPEG with EXIF, want true")
+	}
+	if HasJPEGMetadata([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 4, 'J', 'F', 0xFF, 0xDA, 0, 2}) {
+		t.Error("HasJPEGMetadata() = true for a JPEG with only a JFIF header, want false")
+	}
+}
+
+func TestHasPNGMetadata(t *testing.T) {
+	if !HasPNGMetadata(pngWithChunk("eXIf", exifTIFF(binary.BigEndian, 1))) {
+		t.Error("HasPNGMetadata() = false for a PNG with EXIF, want true")
+	}
+	if !HasPNGMetadata(pngWithChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>"))) {
+		t.Error("HasPNGMetadata() = false for a PNG with XMP, want true")
+	}
+	if HasPNGMetadata(pngWithChunk("gAMA", []byte{0, 0, 0xB1, 0x8F})) {
+		t.Error("HasPNGMetadata() = true for a PNG with only a gamma chunk, want false")
+	}
+}
+
+func TestHasWebPMetadata(t *testing.T) {
+	if !HasWebPMetadata(webpWithChunk("XMP ", []byte("<x:xmpmeta/>"))) {
+		t.Error("HasWebPMetadata() = false for a WebP with XMP, want true")
+	}
+	if HasWebPMetadata(webpWithChunk("ICCP", make([]byte, 7))) {
+		t.Error("HasWebPMetadata() = true for a WebP with only a color profile, want false")
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// A 2x1 image: red on the left, blue on the right
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		name        string
+		orientation int
+		wantSize    image.Point
+		wantRedAt   image.Point
+	}{
+		{name: "Upright", orientation: 1, wantSize: image.Pt(2, 1), wantRedAt: image.Pt(0, 0)},
+		{name: "Mirrored", orientation: 2, wantSize: image.Pt(2, 1), wantRedAt: image.Pt(1, 0)},
+		{name: "Rotated 180", orientation: 3, wantSize: image.Pt(2, 1), wantRedAt: image.Pt(1, 0)},
+		{name: "Rotate clockwise", orientation: 6, wantSize: image.Pt(1, 2), wantRedAt: image.Pt(0, 0)},
+		{name: "Rotate counter-clockwise", orientation: 8, wantSize: image.Pt(1, 2), wantRedAt: image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyOrientation(src, tt.orientation)
+			if size := got.Bounds().Size(); size != tt.wantSize {
+				t.Fatalf("ApplyOrientation() size = %v, want %v", size, tt.wantSize)
+			}
+			if c := color.NRGBAModel.Convert(got.At(tt.wantRedAt.X, tt.wantRedAt.Y)); c != red {
+				t.Errorf("ApplyOrientation() pixel at %v = %v, want red", tt.wantRedAt, c)
+			}
+		})
+	}
+}
+
+func TestResizeToFit(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      image.Point
+		maxWidth  int
+		maxHeight int
+		want      image.Point
+	}{
+		{name: "Landscape", size: image.Pt(4000, 3000), maxWidth: 800, maxHeight: 800, want: image.Pt(800, 600)},
+		{name: "Portrait", size: image.Pt(3000, 4000), maxWidth: 800, maxHeight: 800, want: image.Pt(600, 800)},
+		{name: "Already fits", size: image.Pt(300, 200), maxWidth: 800, maxHeight: 800, want: image.Pt(300, 200)},
+		{name: "Very thin", size: image.Pt(5000, 2), maxWidth: 100, maxHeight: 100, want: image.Pt(100, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rectangle{Max: tt.size})
+			if got := ResizeToFit(img, tt.maxWidth, tt.maxHeight).Bounds().Size(); got != tt.want {
+				t.Errorf("ResizeToFit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"time"
@@ -10,26 +13,83 @@ import (
 
 // Claims defines the custom claims for the JWT token
 type Claims struct {
-	UserID   uint64 `json:"user_id"`
-	Username string `json:"username"`
-	FullName string `json:"full_name"`
-	Role     string `json:"role"`
+	UserID    uint64 `json:"user_id"`
+	Username  string `json:"username"`
+	FullName  string `json:"full_name"`
+	Role      string `json:"role"`
+	SessionID uint64 `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT token for a given user
-func GenerateToken(userID uint64, username string, fullName string, role string) (string, error) {
+const (
+	defaultAccessTokenTTL   = 15 * time.Minute
+	defaultRefreshTokenTTL  = 7 * 24 * time.Hour
+	defaultSessionLifetime  = 30 * 24 * time.Hour
+	defaultPasswordResetTTL = time.Hour
+)
+
+// AccessTokenTTL returns how long an access token is valid.
+// It can be overridden with the JWT_ACCESS_TTL environment variable (e.g. "15m").
+func AccessTokenTTL() time.Duration {
+	return DurationFromEnv("JWT_ACCESS_TTL", defaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL returns how long a refresh token (and its session) is valid.
+// It can be overridden with the JWT_REFRESH_TTL environment variable (e.g. "168h").
+func RefreshTokenTTL() time.Duration {
+	return DurationFromEnv("JWT_REFRESH_TTL", defaultRefreshTokenTTL)
+}
+
+// SessionLifetime returns how long a session may last in total, however often it is refreshed.
+// It can be overridden with the JWT_SESSION_LIFETIME environment variable (e.g. "720h").
+func SessionLifetime() time.Duration {
+	return DurationFromEnv("JWT_SESSION_LIFETIME", defaultSessionLifetime)
+}
+
+// SessionExpiry returns when a session started at createdAt and refreshed now expires: one
+// refresh token lifetime from now, but never after the session's total lifetime
+func SessionExpiry(createdAt time.Time) time.Time {
+	expiresAt := time.Now().Add(RefreshTokenTTL())
+	if limit := createdAt.Add(SessionLifetime()); expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
+
+// PasswordResetTTL returns how long a password reset link is valid.
+// It can be overridden with the PASSWORD_RESET_TTL environment variable (e.g. "1h").
+func PasswordResetTTL() time.Duration {
+	return DurationFromEnv("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+}
+
+// DurationFromEnv parses a duration from an environment variable, falling back to a default
+// if it is missing or invalid
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// GenerateToken generates a new short-lived JWT access token for a given user session
+func GenerateToken(userID uint64, username string, fullName string, role string, sessionID uint64) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		return "", fmt.Errorf("JWT_SECRET environment variable not set")
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour) // Token valid for 24 hours
+	expirationTime := time.Now().Add(AccessTokenTTL())
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		FullName: fullName,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		FullName:  fullName,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -71,3 +131,79 @@ func ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateSecureToken creates a random, URL-safe token (used for refresh tokens and reset links)
+func GenerateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a token.
+// Only this hash is stored, so a database leak does not expose usable tokens.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// twoFactorChallengePurpose marks tokens that only prove the password step of a two-step login
+const twoFactorChallengePurpose = "2fa_challenge"
+
+// ChallengeClaims defines the claims of a two-factor login challenge token
+type ChallengeClaims struct {
+	UserID  uint64 `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerateChallengeToken issues a short-lived token proving that a user passed the password step.
+// It carries no session ID, so AuthMiddleware never accepts it as an access token.
+func GenerateChallengeToken(userID uint64) (string, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return "", fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	claims := &ChallengeClaims{
+		UserID:  userID,
+		Purpose: twoFactorChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign challenge token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ValidateChallengeToken validates a two-factor challenge token and returns the user ID it was issued for
+func ValidateChallengeToken(tokenString string) (uint64, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return 0, fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	claims := &ChallengeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse challenge token: %w", err)
+	}
+	if !token.Valid || claims.Purpose != twoFactorChallengePurpose {
+		return 0, fmt.Errorf("invalid challenge token")
+	}
+
+	return claims.UserID, nil
+}
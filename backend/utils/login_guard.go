@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxLoginAttempts = 5
+	defaultLockoutDuration  = 15 * time.Minute
+	maxLoginDelay           = 30 * time.Second
+)
+
+// MaxLoginAttempts returns how many consecutive failed logins lock an account.
+// It can be overridden with the LOGIN_MAX_ATTEMPTS environment variable.
+func MaxLoginAttempts() int {
+	value, err := strconv.Atoi(os.Getenv("LOGIN_MAX_ATTEMPTS"))
+	if err != nil || value < 1 {
+		return defaultMaxLoginAttempts
+	}
+	return value
+}
+
+// LoginLockoutDuration returns how long an account stays locked after too many failures.
+// It can be overridden with the LOGIN_LOCKOUT_DURATION environment variable (e.g. "15m").
+func LoginLockoutDuration() time.Duration {
+	return DurationFromEnv("LOGIN_LOCKOUT_DURATION", defaultLockoutDuration)
+}
+
+// LoginDelay returns how long a user has to wait after their n-th consecutive failed login
+// before the next attempt is evaluated. The delay doubles with every failure
+// (1s, 2s, 4s, ...) and is capped at 30 seconds.
+func LoginDelay(failedAttempts int) time.Duration {
+	if failedAttempts <= 0 {
+		return 0
+	}
+	delay := time.Second
+	for i := 1; i < failedAttempts; i++ {
+		delay *= 2
+		if delay >= maxLoginDelay {
+			return maxLoginDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		name           string
+		failedAttempts int
+		want           time.Duration
+	}{
+		{name: "No failures", failedAttempts: 0, want: 0},
+		{name: "First failure", failedAttempts: 1, want: time.Second},
+		{name: "Second failure doubles", failedAttempts: 2, want: 2 * time.Second},
+		{name: "Fourth failure", failedAttempts: 4, want: 8 * time.Second},
+		{name: "Capped at maximum", failedAttempts: 10, want: 30 * time.Second},
+		{name: "Large count stays capped", failedAttempts: 1000, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoginDelay(tt.failedAttempts); got != tt.want {
+				t.Errorf("LoginDelay(%d) = %v, want %v", tt.failedAttempts, got, tt.want)
+			}
+		})
+	}
+}
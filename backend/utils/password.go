@@ -15,3 +15,12 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// dummyPasswordHash is the bcrypt hash, at the default cost, of a random password nobody knows
+const dummyPasswordHash = "$2a$10$pDE9wo5g9bSWMCfw9sICGOcpqlW8jIlsMQMDDbhiwLv209f7LwNp."
+
+// CheckDummyPassword does the work of checking a password for a login without an account, so
+// that it takes as long as one with a wrong password and does not reveal which usernames exist
+func CheckDummyPassword(password string) {
+	bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+}
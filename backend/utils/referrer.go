@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Traffic sources of ReferrerSource
+const (
+	SourceDirect   = "direct"   // No referrer: typed, bookmarked or opened from an app
+	SourceSearch   = "search"   // Search engines
+	SourceSocial   = "social"   // Social networks
+	SourceWhatsApp = "whatsapp" // Links shared on WhatsApp
+	SourceCampaign = "campaign" // Tagged links (utm_source) from an unknown source
+	SourceReferral = "referral" // Other websites
+	SourceInternal = "internal" // Pages of the website itself
+)
+
+// sourceBrands maps the name of a site, found as one of the labels of a domain
+// (google.co.id, m.facebook.com) or as a utm_source, to its traffic source. Names of two
+// letters are too ambiguous in domains and only matched as utm_source.
+var sourceBrands = map[string]string{
+	"google": SourceSearch, "bing": SourceSearch, "yahoo": SourceSearch, "duckduckgo": SourceSearch,
+	"yandex": SourceSearch, "baidu": SourceSearch, "ecosia": SourceSearch,
+	"facebook": SourceSocial, "fb": SourceSocial, "instagram": SourceSocial, "ig": SourceSocial,
+	"twitter": SourceSocial, "tiktok": SourceSocial, "youtube": SourceSocial, "linkedin": SourceSocial,
+	"pinterest": SourceSocial, "reddit": SourceSocial, "threads": SourceSocial, "telegram": SourceSocial,
+	"whatsapp": SourceWhatsApp, "wa": SourceWhatsApp,
+}
+
+// sourceDomains maps short domains that carry no brand label to their traffic source
+var sourceDomains = map[string]string{
+	"t.co": SourceSocial, "x.com": SourceSocial, "fb.me": SourceSocial, "lnkd.in": SourceSocial,
+	"t.me": SourceSocial, "youtu.be": SourceSocial, "wa.me": SourceWhatsApp,
+}
+
+// ReferrerDomain returns the host of a referrer URL in lower case without "www.", or "" if the
+// referrer is empty or not a web URL
+func ReferrerDomain(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// ReferrerSource classifies where a visit comes from, by its referrer domain or, without a
+// referrer, by its utm_source. Visits referred by ownHost are internal navigation.
+func ReferrerSource(domain, utmSource, ownHost string) string {
+	if domain == "" {
+		if utmSource = strings.ToLower(strings.TrimSpace(utmSource)); utmSource == "" {
+			return SourceDirect
+		}
+		if source, ok := sourceBrands[utmSource]; ok {
+			return source
+		}
+		return SourceCampaign
+	}
+	if domain == ownHost {
+		return SourceInternal
+	}
+	if source, ok := sourceDomains[domain]; ok {
+		return source
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if source, ok := sourceBrands[label]; ok && len(label) > 2 {
+			return source
+		}
+	}
+	return SourceReferral
+}
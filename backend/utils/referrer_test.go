@@ -0,0 +1,54 @@
+package utils
+
+import "testing"
+
+func TestReferrerDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		referrer string
+		want     string
+	}{
+		{name: "Search engine", referrer: "https://www.google.co.id/", want: "google.co.id"},
+		{name: "Port and path", referrer: "http://Example.com:8080/berita?id=1", want: "example.com"},
+		{name: "Empty", referrer: "", want: ""},
+		{name: "Not a web URL", referrer: "android-app://com.whatsapp/", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReferrerDomain(tt.referrer); got != tt.want {
+				t.Errorf("ReferrerDomain(%q) = %q, want %q", tt.referrer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferrerSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		domain    string
+		utmSource string
+		want      string
+	}{
+		{name: "No referrer", want: SourceDirect},
+		{name: "Google", domain: "google.co.id", want: SourceSearch},
+		{name: "Bing", domain: "bing.com", want: SourceSearch},
+		{name: "Facebook mobile", domain: "m.facebook.com", want: SourceSocial},
+		{name: "Twitter short link", domain: "t.co", want: SourceSocial},
+		{name: "WhatsApp web", domain: "web.whatsapp.com", want: SourceWhatsApp},
+		{name: "WhatsApp link without referrer", utmSource: "WhatsApp", want: SourceWhatsApp},
+		{name: "Instagram link without referrer", utmSource: "ig", want: SourceSocial},
+		{name: "Unknown tagged link", utmSource: "brosur", want: SourceCampaign},
+		{name: "Own site", domain: "desa.example.id", want: SourceInternal},
+		{name: "Other website", domain: "kemendesa.go.id", want: SourceReferral},
+		{name: "Two-letter label", domain: "wa.gov.example", want: SourceReferral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReferrerSource(tt.domain, tt.utmSource, "desa.example.id"); got != tt.want {
+				t.Errorf("ReferrerSource(%q, %q) = %q, want %q", tt.domain, tt.utmSource, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// secretKey derives the AES-256 key used to encrypt secrets at rest (such as TOTP secrets)
+// from the TWO_FACTOR_ENCRYPTION_KEY environment variable
+func secretKey() ([]byte, error) {
+	raw := os.Getenv("TWO_FACTOR_ENCRYPTION_KEY")
+	if raw == "" {
+		return nil, fmt.Errorf("TWO_FACTOR_ENCRYPTION_KEY environment variable not set")
+	}
+	key := sha256.Sum256([]byte(raw))
+	return key[:], nil
+}
+
+// EncryptSecret encrypts a value with AES-GCM and returns base64(nonce || ciphertext)
+func EncryptSecret(plaintext string) (string, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret
+func DecryptSecret(encoded string) (string, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted secret is too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// svgRemovedElements are dropped from uploaded SVG files together with everything inside them:
+// they can run scripts, embed other documents or change attributes after loading
+var svgRemovedElements = map[string]bool{
+	"script":           true,
+	"foreignobject":    true,
+	"iframe":           true,
+	"embed":            true,
+	"object":           true,
+	"handler":          true,
+	"listener":         true,
+	"set":              true,
+	"animate":          true,
+	"animatemotion":    true,
+	"animatetransform": true,
+}
+
+// svgDataImagePattern matches embedded raster images, the only non-local reference kept
+var svgDataImagePattern = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp);base64,`)
+
+// svgCSSURLPattern finds url(...) references in style sheets and style attributes
+var svgCSSURLPattern = regexp.MustCompile(`(?i)url\(\s*['"]?\s*([^'")\s]*)`)
+
+// svgCSSCommentPattern matches CSS comments, which are removed before looking for references
+var svgCSSCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// SanitizeSVG removes scripts, event handlers and references to external resources from an
+// SVG file, so it is safe to serve from the site's own domain. Comments, processing
+// instructions other than the XML declaration and DOCTYPE declarations are removed as well.
+func SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	var stack []string
+	var style strings.Builder // Text of the open <style> element, checked as a whole when it closes
+	skipDepth := 0
+	sawSVG := false
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			// Style sheets only hold text; elements inside one are dropped
+			if svgRemovedElements[strings.ToLower(t.Name.Local)] || inStyle(stack) {
+				skipDepth = 1
+				continue
+			}
+			if len(stack) == 0 {
+				if t.Name.Local != "svg" {
+					return nil, errors.New("the root element is not <svg>")
+				}
+				sawSVG = true
+			}
+			stack = append(stack, t.Name.Local)
+
+			out.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				if !isSafeSVGAttribute(attr) {
+					continue
+				}
+				out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			// RawToken does not check nesting, so do it here
+			if len(stack) == 0 || stack[len(stack)-1] != t.Name.Local {
+				return nil, errors.New("mismatched closing tag </" + qualifiedName(t.Name) + ">")
+			}
+			if inStyle(stack) {
+				// The text may come in several chunks (CDATA sections), so a reference split
+				// across them is only seen once they are joined
+				if isSafeCSS(style.String()) {
+					xml.EscapeText(&out, []byte(style.String()))
+				}
+				style.Reset()
+			}
+			stack = stack[:len(stack)-1]
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			if inStyle(stack) {
+				style.Write(t)
+				continue
+			}
+			xml.EscapeText(&out, t)
+
+		case xml.ProcInst:
+			if t.Target == "xml" && out.Len() == 0 {
+				out.WriteString("<?xml " + string(t.Inst) + "?>")
+			}
+
+		case xml.Comment, xml.Directive:
+			// Dropped: a DOCTYPE can declare entities, comments are of no use
+		}
+	}
+
+	if !sawSVG {
+		return nil, errors.New("no <svg> element found")
+	}
+	if len(stack) > 0 || skipDepth > 0 {
+		return nil, errors.New("unexpected end of file")
+	}
+	return out.Bytes(), nil
+}
+
+// isSafeSVGAttribute rejects event handlers, links to other documents and styles that load
+// external resources
+func isSafeSVGAttribute(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	value := strings.TrimSpace(attr.Value)
+
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return false
+	case name == "href" || name == "src":
+		return strings.HasPrefix(value, "#") || svgDataImagePattern.MatchString(value)
+	case name == "style":
+		return isSafeCSS(value)
+	}
+	return !strings.Contains(strings.ToLower(value), "javascript:")
+}
+
+// isSafeCSS allows style sheets whose url(...) references only point inside the document.
+// Styles with escapes are rejected, as "u\72l(" is read as "url(" by the browser.
+func isSafeCSS(css string) bool {
+	if strings.Contains(css, "\\") {
+		return false
+	}
+	css = svgCSSCommentPattern.ReplaceAllString(css, "")
+	lower := strings.ToLower(css)
+	for _, unsafe := range []string{"@import", "javascript:", "expression(", "image-set("} {
+		if strings.Contains(lower, unsafe) {
+			return false
+		}
+	}
+	for _, match := range svgCSSURLPattern.FindAllStringSubmatch(css, -1) {
+		if !strings.HasPrefix(match[1], "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// inStyle reports whether the innermost open element is a <style> element
+func inStyle(stack []string) bool {
+	return len(stack) > 0 && strings.EqualFold(stack[len(stack)-1], "style")
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
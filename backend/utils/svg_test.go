@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name        string
+		svg         string
+		wantErr     bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "Plain logo is kept",
+			svg:      `<?xml version="1.0" encoding="UTF-8"?><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><circle cx="5" cy="5" r="4" fill="#2563eb"/></svg>`,
+			contains: []string{`<?xml version="1.0" encoding="UTF-8"?>`, `viewBox="0 0 10 10"`, `<circle cx="5" cy="5" r="4" fill="#2563eb">`},
+		},
+		{
+			name:        "Script element removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><rect width="1" height="1"/></svg>`,
+			contains:    []string{"<rect"},
+			notContains: []string{"script", "alert"},
+		},
+		{
+			name:        "Event handlers removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><rect onclick="alert(2)" width="1"/></svg>`,
+			contains:    []string{`<rect width="1">`},
+			notContains: []string{"onload", "onclick", "alert"},
+		},
+		{
+			name:        "External references removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="#shape"/><image href="https://evil.example/x.png"/><a xlink:href="javascript:alert(1)">x</a></svg>`,
+			contains:    []string{`xlink:href="#shape"`, `xmlns:xlink="http://www.w3.org/1999/xlink"`},
+			notContains: []string{"evil.example", "javascript"},
+		},
+		{
+			name:     "Embedded raster image kept",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg"><image href="data:image/png;base64,iVBORw0KGgo="/></svg>`,
+			contains: []string{`href="data:image/png;base64,iVBORw0KGgo="`},
+		},
+		{
+			name:        "Unsafe styles removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg"><style>@import url(https://evil.example/a.css);</style><rect style="fill: url(https://evil.example/p)"/><g style="fill: url(#grad)"/></svg>`,
+			contains:    []string{`style="fill: url(#grad)"`},
+			notContains: []string{"evil.example"},
+		},
+		{
+			name:        "Reference split across CDATA sections removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg"><style>a{background:ur<![CDATA[l(http://evil.example/x.png)]]>}</style><rect width="1"/></svg>`,
+			contains:    []string{"<style></style>", `<rect width="1">`},
+			notContains: []string{"evil.example"},
+		},
+		{
+			name:        "Escaped reference removed",
+			svg:         `<svg xmlns="http://www.w3.org/2000/svg"><style>a{background:u\72l(http://evil.example/x.png)}</style><rect style="fill: u\72l(http://evil.example/p)"/></svg>`,
+			contains:    []string{"<style></style>", "<rect>"},
+			notContains: []string{"evil.example"},
+		},
+		{
+			name:     "Local style sheet kept",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg"><style><![CDATA[.a{fill:url(#grad)}]]></style></svg>`,
+			contains: []string{"<style>.a{fill:url(#grad)}</style>"},
+		},
+		{
+			name:        "Foreign object and DOCTYPE removed",
+			svg:         `<!DOCTYPE svg [<!ENTITY x "y">]><svg xmlns="http://www.w3.org/2000/svg"><foreignObject><body>hi</body></foreignObject></svg>`,
+			notContains: []string{"DOCTYPE", "ENTITY", "foreignObject", "body"},
+		},
+		{
+			name:    "Not an SVG",
+			svg:     `<html><body>hi</body></html>`,
+			wantErr: true,
+		},
+		{
+			name:    "Malformed XML",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><rect></svg>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizeSVG([]byte(tt.svg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SanitizeSVG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(got), s) {
+					t.Errorf("SanitizeSVG() = %s, want it to contain %s", got, s)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(string(got), s) {
+					t.Errorf("SanitizeSVG() = %s, want it not to contain %s", got, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// StripHTML removes HTML tags and entities from rich text and collapses whitespace
+func StripHTML(text string) string {
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Excerpt returns the plain text of rich text, cut at a word boundary to at most maxRunes
+// characters. An ellipsis is appended when the text was shortened.
+func Excerpt(text string, maxRunes int) string {
+	plain := StripHTML(text)
+	if utf8.RuneCountInString(plain) <= maxRunes {
+		return plain
+	}
+
+	runes := []rune(plain)
+	cut := string(runes[:maxRunes])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
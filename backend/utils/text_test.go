@@ -0,0 +1,45 @@
+package utils
+
+import "testing"
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxRunes int
+		want     string
+	}{
+		{
+			name:     "Short plain text",
+			text:     "Gotong royong",
+			maxRunes: 50,
+			want:     "Gotong royong",
+		},
+		{
+			name:     "HTML tags and entities",
+			text:     "<p>Rapat <strong>desa</strong> &amp; warga</p>\n<p>hari ini</p>",
+			maxRunes: 50,
+			want:     "Rapat desa & warga hari ini",
+		},
+		{
+			name:     "Cut at word boundary",
+			text:     "Pembangunan jalan desa dimulai minggu depan",
+			maxRunes: 25,
+			want:     "Pembangunan jalan desa…",
+		},
+		{
+			name:     "Trailing punctuation removed",
+			text:     "Panen raya, hasil melimpah",
+			maxRunes: 14,
+			want:     "Panen raya…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Excerpt(tt.text, tt.maxRunes); got != tt.want {
+				t.Errorf("Excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod = 30 // seconds per time step (RFC 6238 default)
+	totpDigits = 6
+	totpSkew   = 1 // accept codes from one step before and after the current one
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret creates a random 160-bit TOTP secret encoded as base32
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI that authenticator apps read from a QR code
+//
+// Example: otpauth://totp/Desa%20Seirotan:admin?secret=...&issuer=Desa%20Seirotan&digits=6&period=30
+func TOTPProvisioningURI(issuer string, accountName string, secret string) string {
+	label := url.PathEscape(issuer + ":" + accountName)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// TOTPCode computes the RFC 6238 code of a base32 secret for the given time step
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 section 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, code%mod), nil
+}
+
+// TOTPStep returns the time step a moment in time falls into
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// ValidateTOTP checks a code against the secret, allowing one step of clock drift.
+// It returns the matched time step so callers can reject a code that was already used.
+func ValidateTOTP(secret string, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(now)
+	for delta := int64(-totpSkew); delta <= totpSkew; delta++ {
+		expected, err := TOTPCode(secret, current+delta)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return current + delta, true
+		}
+	}
+	return 0, false
+}
+
+// recoveryCodeBytes is the entropy of a recovery code: 80 bits, or 16 base32 characters
+const recoveryCodeBytes = 10
+
+// GenerateRecoveryCodes creates n one-time recovery codes in the form "xxxx-xxxx-xxxx-xxxx"
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes[i] = strings.Join([]string{encoded[0:4], encoded[4:8], encoded[8:12], encoded[12:16]}, "-")
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode lowercases a recovery code and strips spaces and dashes so
+// "ABCD-EFGH-..." and "abcdefgh... " hash to the same value
+func NormalizeRecoveryCode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
+
+// HashRecoveryCode returns the hex HMAC-SHA256 of a normalized recovery code, keyed with
+// TWO_FACTOR_ENCRYPTION_KEY so a leaked table cannot be brute-forced without the key
+func HashRecoveryCode(code string) (string, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(NormalizeRecoveryCode(code)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
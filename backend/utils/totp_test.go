@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B test vectors (SHA-1, secret "12345678901234567890"), truncated to 6 digits
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "T=59", unix: 59, want: "287082"},
+		{name: "T=1111111109", unix: 1111111109, want: "081804"},
+		{name: "T=1111111111", unix: 1111111111, want: "050471"},
+		{name: "T=1234567890", unix: 1234567890, want: "005924"},
+		{name: "T=2000000000", unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("TOTPCode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TOTPCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	current, _ := TOTPCode(secret, TOTPStep(now))
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	next, _ := TOTPCode(secret, TOTPStep(now)+1)
+	tooOld, _ := TOTPCode(secret, TOTPStep(now)-2)
+
+	if step, ok := ValidateTOTP(secret, current, now); !ok || step != TOTPStep(now) {
+		t.Errorf("ValidateTOTP() rejected the current code")
+	}
+	if _, ok := ValidateTOTP(secret, previous, now); !ok {
+		t.Errorf("ValidateTOTP() rejected a code within the allowed drift")
+	}
+	// Codes are only 6 digits, so skip the check in the unlikely case of a collision
+	if tooOld != current && tooOld != previous && tooOld != next {
+		if _, ok := ValidateTOTP(secret, tooOld, now); ok {
+			t.Errorf("ValidateTOTP() accepted a code outside the allowed drift")
+		}
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now); ok {
+		t.Errorf("ValidateTOTP() accepted a code with the wrong length")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes, want 10", len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 19 || code[4] != '-' || code[9] != '-' || code[14] != '-' {
+			t.Errorf("recovery code %q has unexpected format", code)
+		}
+		if NormalizeRecoveryCode(strings.ToUpper(code)+" ") != strings.ReplaceAll(code, "-", "") {
+			t.Errorf("NormalizeRecoveryCode() did not normalize %q", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != len(codes) {
+		t.Errorf("GenerateRecoveryCodes() returned duplicate codes")
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	t.Setenv("TWO_FACTOR_ENCRYPTION_KEY", "test-key")
+
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"same code", "abcd-efgh-ijkl-mnop", "abcd-efgh-ijkl-mnop", true},
+		{"case, spaces and dashes are ignored", "abcd-efgh-ijkl-mnop", " ABCDEFGH IJKLMNOP", true},
+		{"different codes", "abcd-efgh-ijkl-mnop", "abcd-efgh-ijkl-mnoq", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := HashRecoveryCode(tt.a)
+			if err != nil {
+				t.Fatalf("HashRecoveryCode() error = %v", err)
+			}
+			b, err := HashRecoveryCode(tt.b)
+			if err != nil {
+				t.Fatalf("HashRecoveryCode() error = %v", err)
+			}
+			if (a == b) != tt.equal {
+				t.Errorf("HashRecoveryCode(%q) == HashRecoveryCode(%q) is %v, want %v", tt.a, tt.b, a == b, tt.equal)
+			}
+		})
+	}
+
+	plain, err := HashRecoveryCode("abcd-efgh-ijkl-mnop")
+	if err != nil {
+		t.Fatalf("HashRecoveryCode() error = %v", err)
+	}
+	if plain == HashToken(NormalizeRecoveryCode("abcd-efgh-ijkl-mnop")) {
+		t.Errorf("HashRecoveryCode() is not keyed")
+	}
+	t.Setenv("TWO_FACTOR_ENCRYPTION_KEY", "other-key")
+	other, err := HashRecoveryCode("abcd-efgh-ijkl-mnop")
+	if err != nil {
+		t.Fatalf("HashRecoveryCode() error = %v", err)
+	}
+	if plain == other {
+		t.Errorf("HashRecoveryCode() did not change with the key")
+	}
+}
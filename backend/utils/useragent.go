@@ -0,0 +1,106 @@
+package utils
+
+import "strings"
+
+// botMarkers are fragments found in the user agent of crawlers, link previewers, monitoring
+// services and HTTP libraries, compared in lower case
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "mediapartners", "facebookexternalhit", "embedly",
+	"preview", "headlesschrome", "phantomjs", "lighthouse", "pingdom", "uptime", "monitor",
+	"curl/", "wget/", "python-", "go-http-client", "java/", "okhttp", "axios/", "node-fetch",
+}
+
+// IsBot reports whether a user agent belongs to an automated client rather than a visitor's
+// browser. A missing user agent counts as a bot.
+func IsBot(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+	for _, marker := range botMarkers {
+		if strings.Contains(userAgent, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Device classes of ParseUserAgent
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+)
+
+// UserAgentInfo is what analytics keeps of a user agent
+type UserAgentInfo struct {
+	Device  string // desktop, mobile or tablet
+	Browser string
+	OS      string
+}
+
+// userAgentRule maps a user agent fragment to a name. Rules are tried in order, so more
+// specific fragments come first: Edge and Samsung Internet also claim to be Chrome, Chrome
+// claims to be Safari, iOS claims to be like Mac OS X and Android runs on Linux.
+type userAgentRule struct {
+	marker string
+	name   string
+}
+
+var browserRules = []userAgentRule{
+	{"edg", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"ucbrowser", "UC Browser"},
+	{"fban", "Facebook"},
+	{"fbav", "Facebook"},
+	{"instagram", "Instagram"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"chromium/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+var osRules = []userAgentRule{
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"cros", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+// ParseUserAgent derives the device class, browser and operating system of a user agent.
+// Unknown browsers and systems are reported as "Other".
+func ParseUserAgent(userAgent string) UserAgentInfo {
+	ua := strings.ToLower(userAgent)
+	info := UserAgentInfo{Device: DeviceDesktop, Browser: "Other", OS: "Other"}
+
+	for _, rule := range browserRules {
+		if strings.Contains(ua, rule.marker) {
+			info.Browser = rule.name
+			break
+		}
+	}
+	for _, rule := range osRules {
+		if strings.Contains(ua, rule.marker) {
+			info.OS = rule.name
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		info.Device = DeviceTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "ipod"):
+		info.Device = DeviceMobile
+	}
+	return info
+}
@@ -0,0 +1,122 @@
+package utils
+
+import "testing"
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      bool
+	}{
+		{
+			name:      "Desktop Chrome",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+			want:      false,
+		},
+		{
+			name:      "Android phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; SM-A155F) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36",
+			want:      false,
+		},
+		{
+			name:      "iPhone Safari",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1",
+			want:      false,
+		},
+		{
+			name:      "Googlebot",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      true,
+		},
+		{
+			name:      "Facebook link preview",
+			userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			want:      true,
+		},
+		{
+			name:      "Headless browser",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/129.0.0.0 Safari/537.36",
+			want:      true,
+		},
+		{
+			name:      "HTTP library",
+			userAgent: "curl/8.5.0",
+			want:      true,
+		},
+		{
+			name:      "Empty",
+			userAgent: "  ",
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBot(tt.userAgent); got != tt.want {
+				t.Errorf("IsBot(%q) = %v, want %v", tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      UserAgentInfo
+	}{
+		{
+			name:      "Chrome on Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+			want:      UserAgentInfo{Device: DeviceDesktop, Browser: "Chrome", OS: "Windows"},
+		},
+		{
+			name:      "Edge on Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0",
+			want:      UserAgentInfo{Device: DeviceDesktop, Browser: "Edge", OS: "Windows"},
+		},
+		{
+			name:      "Chrome on Android phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; SM-A155F) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36",
+			want:      UserAgentInfo{Device: DeviceMobile, Browser: "Chrome", OS: "Android"},
+		},
+		{
+			name:      "Samsung Internet on Android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X200) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/25.0 Chrome/121.0.0.0 Safari/537.36",
+			want:      UserAgentInfo{Device: DeviceTablet, Browser: "Samsung Internet", OS: "Android"},
+		},
+		{
+			name:      "Safari on iPhone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1",
+			want:      UserAgentInfo{Device: DeviceMobile, Browser: "Safari", OS: "iOS"},
+		},
+		{
+			name:      "Facebook in-app browser on iPhone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/480.0.0.38.108]",
+			want:      UserAgentInfo{Device: DeviceMobile, Browser: "Facebook", OS: "iOS"},
+		},
+		{
+			name:      "Firefox on Linux",
+			userAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0",
+			want:      UserAgentInfo{Device: DeviceDesktop, Browser: "Firefox", OS: "Linux"},
+		},
+		{
+			name:      "Safari on Mac",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15",
+			want:      UserAgentInfo{Device: DeviceDesktop, Browser: "Safari", OS: "macOS"},
+		},
+		{
+			name:      "Unknown",
+			userAgent: "SomeApp/1.0",
+			want:      UserAgentInfo{Device: DeviceDesktop, Browser: "Other", OS: "Other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseUserAgent(tt.userAgent); got != tt.want {
+				t.Errorf("ParseUserAgent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// VisitorID identifies a visitor for analytics without storing personal data: an HMAC-SHA256
+// of the IP address and user agent keyed with a secret salt. With a salt that is replaced
+// every day and then thrown away, the ID cannot be traced back to the IP address and the
+// same visitor gets unrelated IDs on different days.
+func VisitorID(salt []byte, ip, userAgent string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userAgent))
+	return hex.EncodeToString(mac.Sum(nil))
+}
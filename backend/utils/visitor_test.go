@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestVisitorID(t *testing.T) {
+	salt := []byte("salt-of-the-day")
+	id := VisitorID(salt, "203.0.113.7", "Mozilla/5.0")
+
+	tests := []struct {
+		name      string
+		salt      []byte
+		ip        string
+		userAgent string
+		wantSame  bool
+	}{
+		{
+			name:      "Same visitor, same day",
+			salt:      salt,
+			ip:        "203.0.113.7",
+			userAgent: "Mozilla/5.0",
+			wantSame:  true,
+		},
+		{
+			name:      "Same visitor, next day",
+			salt:      []byte("salt-of-the-next-day"),
+			ip:        "203.0.113.7",
+			userAgent: "Mozilla/5.0",
+			wantSame:  false,
+		},
+		{
+			name:      "Other device behind the same IP",
+			salt:      salt,
+			ip:        "203.0.113.7",
+			userAgent: "Mozilla/5.0 (iPhone)",
+			wantSame:  false,
+		},
+		{
+			name:      "Fields are not simply concatenated",
+			salt:      salt,
+			ip:        "203.0.113.",
+			userAgent: "7Mozilla/5.0",
+			wantSame:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VisitorID(tt.salt, tt.ip, tt.userAgent)
+			if (got == id) != tt.wantSame {
+				t.Errorf("VisitorID() = %s, same as %s: %v, want %v", got, id, got == id, tt.wantSame)
+			}
+			if len(got) != 64 {
+				t.Errorf("VisitorID() has length %d, want 64", len(got))
+			}
+		})
+	}
+}
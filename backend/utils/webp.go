@@ -0,0 +1,881 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+// This file implements a lossy WebP encoder: a single VP8 key frame (RFC 6386) in a RIFF
+// container. It keeps to the simple parts of the format: every macroblock is predicted as a
+// whole (16x16 luma, 8x8 chroma), one quantizer is used for the whole frame and the token
+// probabilities are adapted to the image once. That is enough to beat JPEG on photos.
+
+// vp8MaxDimension is the largest width or height a VP8 frame can have
+const vp8MaxDimension = 16383
+
+// VP8 prediction modes of whole macroblocks, in the order of their codes
+const (
+	vp8PredDC = iota
+	vp8PredVE
+	vp8PredHE
+	vp8PredTM
+	vp8PredModes
+)
+
+// EncodeWebP encodes an image as WebP. Opaque images, such as photos, are encoded lossy at the
+// given quality (1-100, comparable to JPEG quality). Images with transparency are encoded
+// lossless, as the lossy format has no alpha channel of its own.
+func EncodeWebP(img image.Image, quality int) ([]byte, error) {
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, errors.New("cannot encode an empty image")
+	}
+	if !IsOpaque(img) {
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if b.Dx() > vp8MaxDimension || b.Dy() > vp8MaxDimension {
+		return nil, errors.New("image is too large for WebP")
+	}
+
+	frame, err := newVP8Encoder(img, webpQuantizer(quality)).encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// RIFF container with a single "VP8 " chunk, padded to an even length
+	size := len(frame) + len(frame)%2
+	out := make([]byte, 0, 20+size)
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(12+size))
+	out = append(out, "WEBPVP8 "...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(frame)))
+	out = append(out, frame...)
+	if len(frame)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out, nil
+}
+
+// webpQuantizer maps a quality of 1-100 to a VP8 quantizer index of 127-0
+func webpQuantizer(quality int) int {
+	quality = min(max(quality, 1), 100)
+	// 90 gives index 10, 80 gives 20, 50 gives 50: about the fidelity of JPEG at the same quality
+	return min((100-quality)*100/99, 127)
+}
+
+// vp8Quant holds the DC and AC quantizer steps of the three kinds of coefficient blocks
+type vp8Quant struct {
+	y1, y2, uv [2]int32
+}
+
+// newVP8Quant derives the quantizer steps from the frame's quantizer index, the way the
+// decoder does (RFC 6386 section 9.6)
+func newVP8Quant(q int) vp8Quant {
+	var quant vp8Quant
+	quant.y1 = [2]int32{vp8DCTable[q], vp8ACTable[q]}
+	quant.y2 = [2]int32{vp8DCTable[q] * 2, max(vp8ACTable[q]*155/100, 8)}
+	quant.uv = [2]int32{vp8DCTable[min(q, 117)], vp8ACTable[q]}
+	return quant
+}
+
+// vp8Macroblock holds the coded form of one macroblock
+type vp8Macroblock struct {
+	yMode, uvMode uint8
+	// Quantized coefficients in natural order: 16 luma blocks, 4 U blocks, 4 V blocks and the
+	// Y2 block with the luma DC coefficients
+	coeffs [25][16]int16
+	skip   bool // All coefficients are zero
+}
+
+// vp8Encoder encodes one image as a VP8 key frame
+type vp8Encoder struct {
+	width, height int
+	mbw, mbh      int
+	q             int
+	quant         vp8Quant
+
+	// Source and reconstructed planes, padded to whole macroblocks. The reconstruction is
+	// what the decoder will see, and later macroblocks are predicted from it.
+	srcY, srcU, srcV []uint8
+	recY, recU, recV []uint8
+	yStride, cStride int
+
+	mbs   []vp8Macroblock
+	probs [vp8Planes][vp8Bands][vp8Contexts][vp8Probs]uint8
+}
+
+func newVP8Encoder(img image.Image, q int) *vp8Encoder {
+	b := img.Bounds()
+	e := &vp8Encoder{
+		width:  b.Dx(),
+		height: b.Dy(),
+		mbw:    (b.Dx() + 15) / 16,
+		mbh:    (b.Dy() + 15) / 16,
+		q:      q,
+		quant:  newVP8Quant(q),
+		probs:  vp8DefaultTokenProbs,
+	}
+	e.yStride, e.cStride = e.mbw*16, e.mbw*8
+	e.srcY = make([]uint8, e.yStride*e.mbh*16)
+	e.srcU = make([]uint8, e.cStride*e.mbh*8)
+	e.srcV = make([]uint8, e.cStride*e.mbh*8)
+	e.recY = make([]uint8, len(e.srcY))
+	e.recU = make([]uint8, len(e.srcU))
+	e.recV = make([]uint8, len(e.srcV))
+	e.mbs = make([]vp8Macroblock, e.mbw*e.mbh)
+	e.importImage(img)
+	return e
+}
+
+// importImage converts the image to BT.601 YCbCr 4:2:0 with the studio range WebP decoders
+// expect, repeating the last row and column into the padding
+func (e *vp8Encoder) importImage(img image.Image) {
+	b := img.Bounds()
+	rgbAt := func(x, y int) (int32, int32, int32) {
+		x, y = min(x, e.width-1), min(y, e.height-1)
+		if m, ok := img.(*image.NRGBA); ok {
+			i := m.PixOffset(b.Min.X+x, b.Min.Y+y)
+			return int32(m.Pix[i]), int32(m.Pix[i+1]), int32(m.Pix[i+2])
+		}
+		c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+		return int32(c.R), int32(c.G), int32(c.B)
+	}
+
+	for y := 0; y < e.mbh*16; y++ {
+		for x := 0; x < e.mbw*16; x++ {
+			r, g, bl := rgbAt(x, y)
+			e.srcY[y*e.yStride+x] = uint8((16839*r + 33059*g + 6420*bl + 16<<16 + 1<<15) >> 16)
+		}
+	}
+	for y := 0; y < e.mbh*8; y++ {
+		for x := 0; x < e.mbw*8; x++ {
+			var r, g, bl int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgbAt(2*x+d[0], 2*y+d[1])
+				r, g, bl = r+pr, g+pg, bl+pb
+			}
+			// The sums are four pixels, hence the extra 2 bits of shift
+			e.srcU[y*e.cStride+x] = uint8(clampInt32((-9719*r-19081*g+28800*bl+128<<18+1<<17)>>18, 0, 255))
+			e.srcV[y*e.cStride+x] = uint8(clampInt32((28800*r-24116*g-4684*bl+128<<18+1<<17)>>18, 0, 255))
+		}
+	}
+}
+
+// encode predicts, transforms and quantizes every macroblock, then writes the frame
+func (e *vp8Encoder) encode() ([]byte, error) {
+	for mby := 0; mby < e.mbh; mby++ {
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+	e.adaptTokenProbs()
+
+	modes, tokens := &boolEncoder{}, &boolEncoder{}
+	e.writeHeader(modes)
+	e.writeMacroblocks(modes, tokens)
+	first, second := modes.finish(), tokens.finish()
+	if len(first) >= 1<<19 {
+		return nil, errors.New("image is too large for WebP")
+	}
+
+	frame := make([]byte, 0, 10+len(first)+len(second))
+	tag := uint32(len(first))<<5 | 1<<4 // Key frame, version 0, shown
+	frame = append(frame, byte(tag), byte(tag>>8), byte(tag>>16))
+	frame = append(frame, 0x9d, 0x01, 0x2a)
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(e.width))
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(e.height))
+	frame = append(frame, first...)
+	frame = append(frame, second...)
+	return frame, nil
+}
+
+// vp8Edges are the reconstructed samples above and left of a block, as the decoder sees them:
+// 127 above the first row, 129 left of the first column
+type vp8Edges struct {
+	top, left []int32
+	topLeft   int32
+}
+
+func (e *vp8Encoder) edges(plane []uint8, stride, size, mbx, mby int) vp8Edges {
+	edges := vp8Edges{top: make([]int32, size), left: make([]int32, size)}
+	x0, y0 := mbx*size, mby*size
+	for i := 0; i < size; i++ {
+		if mby == 0 {
+			edges.top[i] = 127
+		} else {
+			edges.top[i] = int32(plane[(y0-1)*stride+x0+i])
+		}
+		if mbx == 0 {
+			edges.left[i] = 129
+		} else {
+			edges.left[i] = int32(plane[(y0+i)*stride+x0-1])
+		}
+	}
+	switch {
+	case mby == 0:
+		edges.topLeft = 127
+	case mbx == 0:
+		edges.topLeft = 129
+	default:
+		edges.topLeft = int32(plane[(y0-1)*stride+x0-1])
+	}
+	return edges
+}
+
+// predict fills a size x size block with a prediction mode. DC prediction only averages the
+// edges that lie inside the image.
+func predict(pred []int32, edges vp8Edges, size, mode, mbx, mby int) {
+	switch mode {
+	case vp8PredDC:
+		sum, n := int32(0), 0
+		if mby > 0 {
+			for _, v := range edges.top {
+				sum += v
+			}
+			n += size
+		}
+		if mbx > 0 {
+			for _, v := range edges.left {
+				sum += v
+			}
+			n += size
+		}
+		dc := int32(128)
+		if n > 0 {
+			dc = (sum + int32(n/2)) / int32(n)
+		}
+		for i := range pred {
+			pred[i] = dc
+		}
+	case vp8PredVE:
+		for y := 0; y < size; y++ {
+			copy(pred[y*size:(y+1)*size], edges.top)
+		}
+	case vp8PredHE:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				pred[y*size+x] = edges.left[y]
+			}
+		}
+	case vp8PredTM:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				pred[y*size+x] = clampInt32(edges.left[y]+edges.top[x]-edges.topLeft, 0, 255)
+			}
+		}
+	}
+}
+
+// bestPrediction picks the prediction mode with the smallest absolute difference to the
+// source blocks (luma, or U and V together)
+func (e *vp8Encoder) bestPrediction(planes [][]uint8, recs [][]uint8, stride, size, mbx, mby int) (int, [][]int32) {
+	bestMode, bestCost := 0, int64(-1)
+	var bestPreds [][]int32
+	for mode := 0; mode < vp8PredModes; mode++ {
+		var cost int64
+		preds := make([][]int32, len(planes))
+		for p := range planes {
+			preds[p] = make([]int32, size*size)
+			predict(preds[p], e.edges(recs[p], stride, size, mbx, mby), size, mode, mbx, mby)
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					d := int64(planes[p][(mby*size+y)*stride+mbx*size+x]) - int64(preds[p][y*size+x])
+					cost += max(d, -d)
+				}
+			}
+		}
+		if bestCost < 0 || cost < bestCost {
+			bestMode, bestCost, bestPreds = mode, cost, preds
+		}
+	}
+	return bestMode, bestPreds
+}
+
+// encodeMacroblock codes one macroblock and writes its reconstruction
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	mb := &e.mbs[mby*e.mbw+mbx]
+
+	// Luma: 16 4x4 blocks whose DC coefficients go through the Y2 block
+	mode, preds := e.bestPrediction([][]uint8{e.srcY}, [][]uint8{e.recY}, e.yStride, 16, mbx, mby)
+	mb.yMode = uint8(mode)
+	pred := preds[0]
+
+	var coeffs [16][16]int32
+	var dcs [16]int32
+	for n := 0; n < 16; n++ {
+		bx, by := n%4*4, n/4*4
+		var residual [16]int32
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				src := int32(e.srcY[(mby*16+by+y)*e.yStride+mbx*16+bx+x])
+				residual[y*4+x] = src - pred[(by+y)*16+bx+x]
+			}
+		}
+		coeffs[n] = forwardDCT(residual)
+		dcs[n] = coeffs[n][0]
+	}
+	y2 := forwardWHT(dcs)
+	quantizeBlock(&mb.coeffs[24], y2, e.quant.y2, 0)
+	for n := 0; n < 16; n++ {
+		quantizeBlock(&mb.coeffs[n], coeffs[n], e.quant.y1, 1)
+	}
+
+	// Reconstruct the luma the way the decoder will
+	var dequantY2 [16]int32
+	for i, level := range mb.coeffs[24] {
+		dequantY2[i] = int32(level) * e.quant.y2[min(i, 1)]
+	}
+	dc := inverseWHT(dequantY2)
+	for n := 0; n < 16; n++ {
+		bx, by := n%4*4, n/4*4
+		var dequant [16]int32
+		dequant[0] = dc[n]
+		for i := 1; i < 16; i++ {
+			dequant[i] = int32(mb.coeffs[n][i]) * e.quant.y1[1]
+		}
+		residual := inverseDCT(dequant)
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				v := pred[(by+y)*16+bx+x] + residual[y*4+x]
+				e.recY[(mby*16+by+y)*e.yStride+mbx*16+bx+x] = uint8(clampInt32(v, 0, 255))
+			}
+		}
+	}
+
+	// Chroma: one prediction mode for U and V, four 4x4 blocks each
+	mode, preds = e.bestPrediction([][]uint8{e.srcU, e.srcV}, [][]uint8{e.recU, e.recV}, e.cStride, 8, mbx, mby)
+	mb.uvMode = uint8(mode)
+	for p, plane := range [][]uint8{e.srcU, e.srcV} {
+		rec := [][]uint8{e.recU, e.recV}[p]
+		for n := 0; n < 4; n++ {
+			bx, by := n%2*4, n/2*4
+			var residual [16]int32
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					src := int32(plane[(mby*8+by+y)*e.cStride+mbx*8+bx+x])
+					residual[y*4+x] = src - preds[p][(by+y)*8+bx+x]
+				}
+			}
+			block := &mb.coeffs[16+p*4+n]
+			quantizeBlock(block, forwardDCT(residual), e.quant.uv, 0)
+
+			var dequant [16]int32
+			for i, level := range block {
+				dequant[i] = int32(level) * e.quant.uv[min(i, 1)]
+			}
+			residual = inverseDCT(dequant)
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					v := preds[p][(by+y)*8+bx+x] + residual[y*4+x]
+					rec[(mby*8+by+y)*e.cStride+mbx*8+bx+x] = uint8(clampInt32(v, 0, 255))
+				}
+			}
+		}
+	}
+
+	mb.skip = true
+	for i := range mb.coeffs {
+		for _, level := range mb.coeffs[i] {
+			if level != 0 {
+				mb.skip = false
+			}
+		}
+	}
+}
+
+// quantizeBlock quantizes coefficients from position first on. Small AC coefficients are
+// rounded towards zero a little more than DC ones, as they cost more bits than they are worth.
+func quantizeBlock(levels *[16]int16, coeffs [16]int32, steps [2]int32, first int) {
+	for i := first; i < 16; i++ {
+		step := steps[min(i, 1)]
+		bias := step / 2
+		if i > 0 {
+			bias = step * 3 / 8
+		}
+		v := coeffs[i]
+		level := (max(v, -v) + bias) / step
+		level = min(level, 2048+66) // The largest value a token can hold
+		if v < 0 {
+			level = -level
+		}
+		levels[i] = int16(level)
+	}
+}
+
+// forwardDCT transforms a 4x4 block of residuals (RFC 6386 section 14.3, as in libvpx)
+func forwardDCT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		a := (in[i*4+0] + in[i*4+3]) * 8
+		b := (in[i*4+1] + in[i*4+2]) * 8
+		c := (in[i*4+1] - in[i*4+2]) * 8
+		d := (in[i*4+0] - in[i*4+3]) * 8
+		tmp[i*4+0] = a + b
+		tmp[i*4+2] = a - b
+		tmp[i*4+1] = (c*2217 + d*5352 + 14500) >> 12
+		tmp[i*4+3] = (d*2217 - c*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[12+i]
+		b := tmp[4+i] + tmp[8+i]
+		c := tmp[4+i] - tmp[8+i]
+		d := tmp[i] - tmp[12+i]
+		out[i] = (a + b + 7) >> 4
+		out[8+i] = (a - b + 7) >> 4
+		out[4+i] = (c*2217 + d*5352 + 12000) >> 16
+		if d != 0 {
+			out[4+i]++
+		}
+		out[12+i] = (d*2217 - c*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// inverseDCT is the decoder's inverse transform, bit for bit (RFC 6386 section 14.3)
+func inverseDCT(in [16]int32) [16]int32 {
+	const c1, c2 = 85627, 35468
+	var m [4][4]int32
+	var out [16]int32
+	for i := 0; i < 4; i++ {
+		a := in[i] + in[8+i]
+		b := in[i] - in[8+i]
+		c := (in[4+i]*c2)>>16 - (in[12+i]*c1)>>16
+		d := (in[4+i]*c1)>>16 + (in[12+i]*c2)>>16
+		m[i] = [4]int32{a + d, b + c, b - c, a - d}
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		out[j*4+0] = (a + d) >> 3
+		out[j*4+1] = (b + c) >> 3
+		out[j*4+2] = (b - c) >> 3
+		out[j*4+3] = (a - d) >> 3
+	}
+	return out
+}
+
+// forwardWHT transforms the DC coefficients of the 16 luma blocks (as in libvpx)
+func forwardWHT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		a := (in[i*4+0] + in[i*4+2]) * 4
+		d := (in[i*4+1] + in[i*4+3]) * 4
+		c := (in[i*4+1] - in[i*4+3]) * 4
+		b := (in[i*4+0] - in[i*4+2]) * 4
+		tmp[i*4+0] = a + d
+		if a != 0 {
+			tmp[i*4+0]++
+		}
+		tmp[i*4+1] = b + c
+		tmp[i*4+2] = b - c
+		tmp[i*4+3] = a - d
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[8+i]
+		d := tmp[4+i] + tmp[12+i]
+		c := tmp[4+i] - tmp[12+i]
+		b := tmp[i] - tmp[8+i]
+		for k, v := range [4]int32{a + d, b + c, b - c, a - d} {
+			if v < 0 {
+				v++
+			}
+			out[k*4+i] = (v + 3) >> 3
+		}
+	}
+	return out
+}
+
+// inverseWHT is the decoder's inverse Walsh-Hadamard transform, bit for bit (section 14.3).
+// It returns the DC coefficient of each luma block.
+func inverseWHT(in [16]int32) [16]int32 {
+	var m, out [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[i*4] + 3
+		a0 := dc + m[i*4+3]
+		a1 := m[i*4+1] + m[i*4+2]
+		a2 := m[i*4+1] - m[i*4+2]
+		a3 := dc - m[i*4+3]
+		out[i*4+0] = (a0 + a1) >> 3
+		out[i*4+1] = (a3 + a2) >> 3
+		out[i*4+2] = (a0 - a1) >> 3
+		out[i*4+3] = (a3 - a2) >> 3
+	}
+	return out
+}
+
+// vp8TokenStats counts, for every token probability, how often its branch was 0 and 1
+type vp8TokenStats [vp8Planes][vp8Bands][vp8Contexts][vp8Probs][2]int
+
+// adaptTokenProbs replaces default token probabilities by ones measured on this image where
+// that saves more bits than sending the new value costs
+func (e *vp8Encoder) adaptTokenProbs() {
+	var stats vp8TokenStats
+	e.visitTokens(func(plane, band, ctx, i int, bit bool) {
+		stats[plane][band][ctx][i][btoi(bit)]++
+	}, func(bool, uint8) {})
+
+	for plane := range stats {
+		for band := range stats[plane] {
+			for ctx := range stats[plane][band] {
+				for i, counts := range stats[plane][band][ctx] {
+					total := counts[0] + counts[1]
+					if total == 0 {
+						continue
+					}
+					newProb := uint8(min(max((counts[0]*256+total/2)/total, 1), 255))
+					oldProb := e.probs[plane][band][ctx][i]
+					update := vp8TokenUpdateProbs[plane][band][ctx][i]
+					savings := branchCost(counts, oldProb) - branchCost(counts, newProb) -
+						8*256 - bitCost(true, update) + bitCost(false, update)
+					if savings > 0 {
+						e.probs[plane][band][ctx][i] = newProb
+					}
+				}
+			}
+		}
+	}
+}
+
+// branchCost estimates the bits (in 1/256 bit) to code a branch seen counts times
+func branchCost(counts [2]int, prob uint8) int {
+	return counts[0]*bitCost(false, prob) + counts[1]*bitCost(true, prob)
+}
+
+// writeHeader writes the frame header into the first partition
+func (e *vp8Encoder) writeHeader(w *boolEncoder) {
+	w.putBit(false, 128) // Color space: BT.601
+	w.putBit(false, 128) // Clamping required
+	w.putBit(false, 128) // No segmentation
+
+	// Normal loop filter, stronger for coarser quantizers
+	w.putBit(false, 128)
+	w.putUint(uint32(min(e.q*2/3, 63)), 6)
+	w.putUint(0, 3)      // Sharpness
+	w.putBit(false, 128) // No filter level deltas
+
+	w.putUint(0, 2) // One token partition
+	w.putUint(uint32(e.q), 7)
+	for i := 0; i < 5; i++ {
+		w.putBit(false, 128) // No quantizer deltas
+	}
+	w.putBit(false, 128) // Do not keep the probabilities for later frames
+
+	for plane := range e.probs {
+		for band := range e.probs[plane] {
+			for ctx := range e.probs[plane][band] {
+				for i, prob := range e.probs[plane][band][ctx] {
+					update := prob != vp8DefaultTokenProbs[plane][band][ctx][i]
+					w.putBit(update, vp8TokenUpdateProbs[plane][band][ctx][i])
+					if update {
+						w.putUint(uint32(prob), 8)
+					}
+				}
+			}
+		}
+	}
+}
+
+// writeMacroblocks writes the prediction modes into the first partition and the coefficient
+// tokens into the second
+func (e *vp8Encoder) writeMacroblocks(modes, tokens *boolEncoder) {
+	skipped := 0
+	for _, mb := range e.mbs {
+		if mb.skip {
+			skipped++
+		}
+	}
+	skipProb := uint8(min(max((len(e.mbs)-skipped)*256/len(e.mbs), 1), 255))
+	modes.putBit(true, 128) // Macroblocks without coefficients are flagged
+	modes.putUint(uint32(skipProb), 8)
+
+	for _, mb := range e.mbs {
+		modes.putBit(mb.skip, skipProb)
+		modes.putBit(true, 145) // Whole-block luma prediction
+		switch mb.yMode {
+		case vp8PredDC:
+			modes.putBit(false, 156)
+			modes.putBit(false, 163)
+		case vp8PredVE:
+			modes.putBit(false, 156)
+			modes.putBit(true, 163)
+		case vp8PredHE:
+			modes.putBit(true, 156)
+			modes.putBit(false, 128)
+		case vp8PredTM:
+			modes.putBit(true, 156)
+			modes.putBit(true, 128)
+		}
+		switch mb.uvMode {
+		case vp8PredDC:
+			modes.putBit(false, 142)
+		case vp8PredVE:
+			modes.putBit(true, 142)
+			modes.putBit(false, 114)
+		case vp8PredHE:
+			modes.putBit(true, 142)
+			modes.putBit(true, 114)
+			modes.putBit(false, 183)
+		case vp8PredTM:
+			modes.putBit(true, 142)
+			modes.putBit(true, 114)
+			modes.putBit(true, 183)
+		}
+	}
+
+	e.visitTokens(func(plane, band, ctx, i int, bit bool) {
+		tokens.putBit(bit, e.probs[plane][band][ctx][i])
+	}, tokens.putBit)
+}
+
+// visitTokens walks the coefficient tokens of all macroblocks in coding order. Bits coded with
+// a token probability go to token, bits with a fixed probability to fixed.
+func (e *vp8Encoder) visitTokens(token func(plane, band, ctx, i int, bit bool), fixed func(bit bool, prob uint8)) {
+	// Whether the blocks above and left had any non-zero coefficient, as token contexts:
+	// 4 luma and 2+2 chroma blocks per edge, plus the Y2 block
+	type edgeFlags struct {
+		y  [4]int
+		uv [4]int
+		y2 int
+	}
+	above := make([]edgeFlags, e.mbw)
+
+	for mby := 0; mby < e.mbh; mby++ {
+		var left edgeFlags
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			mb := &e.mbs[mby*e.mbw+mbx]
+			up := &above[mbx]
+			if mb.skip {
+				*up, left = edgeFlags{}, edgeFlags{}
+				continue
+			}
+
+			nz := putBlockTokens(token, fixed, &mb.coeffs[24], vp8PlaneY2, left.y2+up.y2, 0)
+			left.y2, up.y2 = nz, nz
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					nz := putBlockTokens(token, fixed, &mb.coeffs[y*4+x], vp8PlaneYAfterY2, left.y[y]+up.y[x], 1)
+					left.y[y], up.y[x] = nz, nz
+				}
+			}
+			for c := 0; c < 4; c += 2 {
+				for y := 0; y < 2; y++ {
+					for x := 0; x < 2; x++ {
+						nz := putBlockTokens(token, fixed, &mb.coeffs[16+c*2+y*2+x], vp8PlaneUV, left.uv[c+y]+up.uv[c+x], 0)
+						left.uv[c+y], up.uv[c+x] = nz, nz
+					}
+				}
+			}
+		}
+	}
+}
+
+// putBlockTokens codes the quantized coefficients of a 4x4 block from position first on
+// (RFC 6386 section 13) and returns 1 if any of them was non-zero
+func putBlockTokens(token func(plane, band, ctx, i int, bit bool), fixed func(bit bool, prob uint8), levels *[16]int16, plane, ctx, first int) int {
+	last := -1
+	for i := 15; i >= first; i-- {
+		if levels[vp8Zigzag[i]] != 0 {
+			last = i
+			break
+		}
+	}
+
+	band := int(vp8CoeffBands[first])
+	put := func(i int, bit bool) { token(plane, band, ctx, i, bit) }
+	if last < 0 {
+		put(0, false) // End of block straight away
+		return 0
+	}
+	put(0, true)
+
+	for i := first; i < 16; i++ {
+		level := int(levels[vp8Zigzag[i]])
+		v := max(level, -level)
+		if v == 0 {
+			put(1, false)
+			band, ctx = int(vp8CoeffBands[i+1]), 0
+			continue
+		}
+		put(1, true)
+
+		nextCtx := 2
+		switch {
+		case v == 1:
+			put(2, false)
+			nextCtx = 1
+		case v <= 4:
+			put(2, true)
+			put(3, false)
+			put(4, v > 2)
+			if v > 2 {
+				put(5, v == 4)
+			}
+		case v <= 10:
+			put(2, true)
+			put(3, true)
+			put(6, false)
+			put(7, v > 6)
+			if v <= 6 {
+				fixed(v == 6, 159)
+			} else {
+				fixed((v-7)&2 != 0, 165)
+				fixed((v-7)&1 != 0, 145)
+			}
+		default:
+			put(2, true)
+			put(3, true)
+			put(6, true)
+			cat := 3
+			for cat > 0 && v < 3+(8<<cat) {
+				cat--
+			}
+			put(8, cat >= 2)
+			put(9+cat/2, cat%2 == 1)
+			extra := v - (3 + (8 << cat))
+			probs := vp8CatExtraProbs[cat]
+			for j, prob := range probs {
+				fixed(extra>>(len(probs)-1-j)&1 == 1, prob)
+			}
+		}
+		fixed(level < 0, 128)
+
+		if i == 15 {
+			return 1
+		}
+		band, ctx = int(vp8CoeffBands[i+1]), nextCtx
+		put(0, i != last)
+		if i == last {
+			return 1
+		}
+	}
+	return 1
+}
+
+// boolEncoder is the arithmetic coder of VP8 (RFC 6386 section 7.3)
+type boolEncoder struct {
+	out      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+	started  bool
+}
+
+// putBit codes a bit that is 0 with a probability of prob/256
+func (w *boolEncoder) putBit(bit bool, prob uint8) {
+	if !w.started {
+		w.rng, w.bitCount, w.started = 255, 24, true
+	}
+	split := 1 + ((w.rng-1)*uint32(prob))>>8
+	if bit {
+		w.bottom += split
+		w.rng -= split
+	} else {
+		w.rng = split
+	}
+	for w.rng < 128 {
+		w.rng <<= 1
+		if w.bottom&(1<<31) != 0 {
+			w.carry()
+		}
+		w.bottom <<= 1
+		w.bitCount--
+		if w.bitCount == 0 {
+			w.out = append(w.out, byte(w.bottom>>24))
+			w.bottom &= 1<<24 - 1
+			w.bitCount = 8
+		}
+	}
+}
+
+// putUint codes an n-bit unsigned value, most significant bit first, with even odds
+func (w *boolEncoder) putUint(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.putBit(v>>i&1 == 1, 128)
+	}
+}
+
+// carry propagates a carry into the bytes already written
+func (w *boolEncoder) carry() {
+	i := len(w.out) - 1
+	for i >= 0 && w.out[i] == 0xff {
+		w.out[i] = 0
+		i--
+	}
+	if i >= 0 {
+		w.out[i]++
+	}
+}
+
+// finish flushes the coder and returns the coded bytes
+func (w *boolEncoder) finish() []byte {
+	if !w.started {
+		w.rng, w.bitCount, w.started = 255, 24, true
+	}
+	c := w.bitCount
+	v := w.bottom
+	if v&(1<<(32-c)) != 0 {
+		w.carry()
+	}
+	v <<= c & 7
+	c >>= 3
+	for c--; c >= 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		w.out = append(w.out, byte(v>>24))
+		v <<= 8
+	}
+	return w.out
+}
+
+// bitCost returns the cost in 1/256 bit of coding bit with a probability of prob/256 for 0
+func bitCost(bit bool, prob uint8) int {
+	p := int(prob)
+	if bit {
+		p = 256 - p
+	}
+	return vp8Log2Cost[p]
+}
+
+// vp8Log2Cost[p] is -log2(p/256) in 1/256 bit
+var vp8Log2Cost = func() [257]int {
+	var costs [257]int
+	for p := 1; p <= 256; p++ {
+		costs[p] = int(math.Round(-256 * math.Log2(float64(p)/256)))
+	}
+	return costs
+}()
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func clampInt32(v, lo, hi int32) int32 {
+	return min(max(v, lo), hi)
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testPhoto builds an opaque image with smooth gradients and sharp edges, at a size that is
+// not a whole number of macroblocks
+func testPhoto(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 60, A: 255}
+			if (x/10+y/10)%2 == 0 {
+				c.B = 200
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncodeWebPLossy(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int
+		height  int
+		quality int
+		minPSNR float64
+	}{
+		{name: "High quality", width: 75, height: 43, quality: 90, minPSNR: 38},
+		{name: "Default quality", width: 75, height: 43, quality: 80, minPSNR: 34},
+		{name: "Lowest quality", width: 75, height: 43, quality: 1, minPSNR: 18},
+		{name: "Single pixel", width: 1, height: 1, quality: 80, minPSNR: 34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := testPhoto(tt.width, tt.height)
+			data, err := EncodeWebP(src, tt.quality)
+			if err != nil {
+				t.Fatalf("EncodeWebP() error = %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("webp.Decode() error = %v", err)
+			}
+			got, ok := decoded.(*image.YCbCr)
+			if !ok {
+				t.Fatalf("webp.Decode() = %T, want a lossy *image.YCbCr", decoded)
+			}
+			if size := got.Bounds().Size(); size != src.Bounds().Size() {
+				t.Fatalf("decoded size = %v, want %v", size, src.Bounds().Size())
+			}
+
+			// Compare the luma with the encoder's own conversion of the source
+			want := newVP8Encoder(src, 0)
+			var squaredError float64
+			for y := 0; y < tt.height; y++ {
+				for x := 0; x < tt.width; x++ {
+					d := float64(want.srcY[y*want.yStride+x]) - float64(got.Y[got.YOffset(x, y)])
+					squaredError += d * d
+				}
+			}
+			psnr := math.Inf(1)
+			if squaredError > 0 {
+				psnr = 10 * math.Log10(255*255*float64(tt.width*tt.height)/squaredError)
+			}
+			if psnr < tt.minPSNR {
+				t.Errorf("luma PSNR = %.1f dB, want at least %.1f dB", psnr, tt.minPSNR)
+			}
+		})
+	}
+}
+
+func TestEncodeWebPQualityAffectsSize(t *testing.T) {
+	src := testPhoto(200, 150)
+	low, err := EncodeWebP(src, 30)
+	if err != nil {
+		t.Fatalf("EncodeWebP() error = %v", err)
+	}
+	high, err := EncodeWebP(src, 95)
+	if err != nil {
+		t.Fatalf("EncodeWebP() error = %v", err)
+	}
+	if len(low) >= len(high) {
+		t.Errorf("quality 30 gave %d bytes, quality 95 gave %d bytes, want fewer at the lower quality", len(low), len(high))
+	}
+}
+
+func TestEncodeWebPTransparent(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 9, 5))
+	src.SetNRGBA(3, 2, color.NRGBA{R: 200, G: 10, B: 30, A: 128})
+
+	data, err := EncodeWebP(src, 80)
+	if err != nil {
+		t.Fatalf("EncodeWebP() error = %v", err)
+	}
+	decoded, err := webp.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("webp.Decode() error = %v", err)
+	}
+	for _, p := range []image.Point{{0, 0}, {3, 2}} {
+		want := src.NRGBAAt(p.X, p.Y)
+		if got := color.NRGBAModel.Convert(decoded.At(p.X, p.Y)); got != want {
+			t.Errorf("pixel at %v = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -12,9 +12,27 @@ type Contact = {
   email: string;
   subject: string;
   message: string;
+  is_read: boolean;
+  status: string;
   created_at: string;
 };
 
+type ContactPage = {
+  data: Contact[];
+  currentPage: number;
+  totalPages: number;
+  totalItems: number;
+};
+
+const PAGE_SIZE = 20;
+
+const statusLabels: Record<string, string> = {
+  new: 'Baru',
+  in_progress: 'Diproses',
+  resolved: 'Selesai',
+  spam: 'Spam',
+};
+
 export default function AdminContactsPage() {
   // Protect this page - only admin and superadmin can access
   const { loading: roleLoading } = useRoleProtection(['admin', 'superadmin']);
@@ -22,6 +40,13 @@ export default function AdminContactsPage() {
   const [contacts, setContacts] = useState<Contact[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [page, setPage] = useState(1);
+  const [totalPages, setTotalPages] = useState(0);
+  const [totalItems, setTotalItems] = useState(0);
+  const [status, setStatus] = useState('');
+  const [search, setSearch] = useState('');
+  const [query, setQuery] = useState('');
+  const [openId, setOpenId] = useState<number | null>(null);
 
   useEffect(() => {
     const fetchContacts = async () => {
@@ -33,7 +58,10 @@ export default function AdminContactsPage() {
         }
 
                 const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-        const res = await fetch(`${apiUrl}/api/v1/admin/contacts`, {
+        const params = new URLSearchParams({ page: page.toString(), limit: PAGE_SIZE.toString() });
+        if (status) params.set('status', status);
+        if (query) params.set('q', query);
+        const res = await fetch(`${apiUrl}/api/v1/admin/contacts?${params.toString()}`, {
           headers: {
             'Authorization': `Bearer ${token}`,
           },
@@ -43,8 +71,10 @@ export default function AdminContactsPage() {
           throw new Error('Failed to fetch contacts');
         }
 
-        const data: Contact[] = await res.json();
-        setContacts(data);
+        const body: ContactPage = await res.json();
+        setContacts(body.data);
+        setTotalPages(body.totalPages);
+        setTotalItems(body.totalItems);
       } catch (err: unknown) {
         if (err instanceof Error) {
           setError(err.message);
@@ -55,12 +85,77 @@ export default function AdminContactsPage() {
     };
 
     fetchContacts();
-  }, []);
+  }, [page, status, query]);
+
+  const markRead = async (contact: Contact) => {
+    try {
+      const token = Cookies.get('jwt_token');
+      const apiUrl = process.env.NEXT_PUBLIC_API_URL;
+      const res = await fetch(`${apiUrl}/api/v1/admin/contacts/${contact.id}/read`, {
+        method: 'POST',
+        headers: {
+          'Authorization': `Bearer ${token}`,
+        },
+      });
+      if (res.ok) {
+        setContacts((prev) => prev.map((c) => (c.id === contact.id ? { ...c, is_read: true } : c)));
+      }
+    } catch {
+      // Leave the message unread; it can be opened again later.
+    }
+  };
+
+  const toggleContact = (contact: Contact) => {
+    if (openId === contact.id) {
+      setOpenId(null);
+      return;
+    }
+    setOpenId(contact.id);
+    if (!contact.is_read) {
+      markRead(contact);
+    }
+  };
+
+  const handleSearch = (e: React.FormEvent) => {
+    e.preventDefault();
+    setPage(1);
+    setQuery(search.trim());
+  };
 
   return (
     <AdminLayout>
       <h1 className="text-3xl font-bold text-gray-800 mb-6">Pesan Masuk</h1>
 
+      <div className="flex flex-wrap items-center gap-4 mb-4">
+        <select
+          value={status}
+          onChange={(e) => {
+            setPage(1);
+            setStatus(e.target.value);
+          }}
+          className="px-3 py-2 border border-gray-300 rounded-md bg-white text-sm"
+        >
+          <option value="">Semua kecuali spam</option>
+          {Object.entries(statusLabels).map(([value, label]) => (
+            <option key={value} value={value}>{label}</option>
+          ))}
+          <option value="all">Semua termasuk spam</option>
+        </select>
+        <form onSubmit={handleSearch} className="flex gap-2">
+          <input
+            type="text"
+            value={search}
+            onChange={(e) => setSearch(e.target.value)}
+            placeholder="Cari nama, email, subjek, atau pesan"
+            className="px-3 py-2 border border-gray-300 rounded-md text-sm w-72"
+          />
+          <button type="submit" className="px-4 py-2 bg-blue-600 text-white rounded-md text-sm hover:bg-blue-700">
+            Cari
+          </button>
+        </form>
+        <span className="text-sm text-gray-600">{totalItems} pesan</span>
+      </div>
+
       {(loading || roleLoading) && <p>Loading...</p>}
       {error && <p className="text-red-500">Error: {error}</p>}
 
@@ -84,11 +179,18 @@ export default function AdminContactsPage() {
                 <th className="px-5 py-3 border-b-2 border-gray-200 bg-gray-100 text-left text-xs font-semibold text-gray-600 uppercase tracking-wider">
                   Pesan
                 </th>
+                <th className="px-5 py-3 border-b-2 border-gray-200 bg-gray-100 text-left text-xs font-semibold text-gray-600 uppercase tracking-wider">
+                  Status
+                </th>
               </tr>
             </thead>
             <tbody>
               {contacts.map((contact) => (
-                <tr key={contact.id}>
+                <tr
+                  key={contact.id}
+                  onClick={() => toggleContact(contact)}
+                  className={`cursor-pointer ${contact.is_read ? '' : 'font-semibold'}`}
+                >
                   <td className="px-5 py-5 border-b border-gray-200 bg-white text-sm">
                     <p className="text-gray-900 whitespace-no-wrap">{new Date(contact.created_at).toLocaleString()}</p>
                   </td>
@@ -102,7 +204,11 @@ export default function AdminContactsPage() {
                     <p className="text-gray-900 whitespace-no-wrap">{contact.subject}</p>
                   </td>
                   <td className="px-5 py-5 border-b border-gray-200 bg-white text-sm">
-                    <p className="text-gray-900 whitespace-pre-wrap">{contact.message}</p>
+                    <p className={`text-gray-900 whitespace-pre-wrap ${openId === contact.id ? '' : 'line-clamp-2'}`}>{contact.message}</p>
+                  </td>
+                  <td className="px-5 py-5 border-b border-gray-200 bg-white text-sm">
+                    <p className="text-gray-900 whitespace-no-wrap">{statusLabels[contact.status] ?? contact.status}</p>
+                    {!contact.is_read && <p className="text-xs text-blue-600">Belum dibaca</p>}
                   </td>
                 </tr>
               ))}
@@ -110,6 +216,28 @@ export default function AdminContactsPage() {
           </table>
         </div>
       )}
+
+      {!loading && !error && totalPages > 1 && (
+        <div className="flex justify-center items-center space-x-2 mt-6">
+          <button
+            onClick={() => setPage(page - 1)}
+            disabled={page <= 1}
+            className="px-4 py-2 bg-white text-gray-700 rounded-md hover:bg-gray-100 disabled:text-gray-400 disabled:pointer-events-none"
+          >
+            Previous
+          </button>
+          <span className="px-4 py-2 text-sm text-gray-600">
+            Halaman {page} dari {totalPages}
+          </span>
+          <button
+            onClick={() => setPage(page + 1)}
+            disabled={page >= totalPages}
+            className="px-4 py-2 bg-white text-gray-700 rounded-md hover:bg-gray-100 disabled:text-gray-400 disabled:pointer-events-none"
+          >
+            Next
+          </button>
+        </div>
+      )}
     </AdminLayout>
   );
 }
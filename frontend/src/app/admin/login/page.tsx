@@ -1,47 +1,78 @@
 'use client';
 
 import { useState } from 'react';
+import Link from 'next/link';
 import { useRouter } from 'next/navigation';
-import Cookies from 'js-cookie';
 import { FaEye, FaEyeSlash } from 'react-icons/fa';
+import { AuthSession, storeSession } from '@/services/api';
+
+type LoginResponse = AuthSession & {
+  recovery_codes?: string[];
+};
+
+type TwoFactorChallenge = {
+  two_factor_required: boolean;
+  two_factor_setup_required: boolean;
+  challenge_token: string;
+};
+
+type TwoFactorSetup = {
+  secret: string;
+  provisioning_uri: string;
+};
+
+// password -> (verify | setup -> recovery-codes) -> redirect
+type Step = 'password' | 'verify' | 'setup' | 'recovery-codes';
+
+const inputClassName = 'w-full px-4 py-2 mt-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500';
+const buttonClassName = 'w-full py-2 px-4 font-semibold text-white bg-blue-600 rounded-lg hover:bg-blue-700 disabled:bg-gray-400';
+
+async function postAuth<T>(path: string, body: object): Promise<T> {
+  const apiUrl = process.env.NEXT_PUBLIC_API_URL;
+  const res = await fetch(`${apiUrl}/api/v1/auth/${path}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  });
+
+  const data = await res.json();
+  if (!res.ok) {
+    throw new Error(data.error || 'Login failed');
+  }
+  return data;
+}
 
 export default function LoginPage() {
   const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
   const [showPassword, setShowPassword] = useState(false);
+  const [step, setStep] = useState<Step>('password');
+  const [challengeToken, setChallengeToken] = useState('');
+  const [setup, setSetup] = useState<TwoFactorSetup | null>(null);
+  const [code, setCode] = useState('');
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+  const [session, setSession] = useState<LoginResponse | null>(null);
   const [error, setError] = useState<string | null>(null);
   const [loading, setLoading] = useState(false);
   const router = useRouter();
 
-  const handleLogin = async (e: React.FormEvent<HTMLFormElement>) => {
-    e.preventDefault();
+  const finishLogin = (data: LoginResponse) => {
+    // Store the access token, refresh token and role in cookies
+    storeSession(data);
+
+    // Redirect based on role
+    if (data.role === 'author') {
+      router.push('/admin/news');
+    } else {
+      router.push('/admin/dashboard');
+    }
+  };
+
+  const run = async (action: () => Promise<void>) => {
     setLoading(true);
     setError(null);
-
     try {
-            const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-      const res = await fetch(`${apiUrl}/api/v1/auth/login`, {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ username, password }),
-      });
-
-      if (!res.ok) {
-        const data = await res.json();
-        throw new Error(data.error || 'Login failed');
-      }
-
-      const data = await res.json();
-      // Store the token and role in cookies
-      Cookies.set('jwt_token', data.token, { expires: 1 }); // Expires in 1 day
-      Cookies.set('user_role', data.role, { expires: 1 }); // Expires in 1 day
-
-      // Redirect based on role
-      if (data.role === 'author') {
-        router.push('/admin/news');
-      } else {
-        router.push('/admin/dashboard');
-      }
+      await action();
     } catch (err: unknown) {
       if (err instanceof Error) {
         setError(err.message);
@@ -51,56 +82,192 @@ export default function LoginPage() {
     }
   };
 
+  const handleLogin = (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    run(async () => {
+      const data = await postAuth<LoginResponse | TwoFactorChallenge>('login', { username, password });
+      if (!('two_factor_required' in data) || !data.two_factor_required) {
+        finishLogin(data as LoginResponse);
+        return;
+      }
+
+      setChallengeToken(data.challenge_token);
+      setCode('');
+      if (data.two_factor_setup_required) {
+        // The role requires 2FA but the account has not enrolled yet
+        setSetup(await postAuth<TwoFactorSetup>('2fa/setup', { challenge_token: data.challenge_token }));
+        setStep('setup');
+      } else {
+        setStep('verify');
+      }
+    });
+  };
+
+  const handleVerify = (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    run(async () => {
+      const body = useRecoveryCode
+        ? { challenge_token: challengeToken, recovery_code: code.trim() }
+        : { challenge_token: challengeToken, code: code.trim() };
+      finishLogin(await postAuth<LoginResponse>('2fa/verify', body));
+    });
+  };
+
+  const handleEnable = (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    run(async () => {
+      const data = await postAuth<LoginResponse>('2fa/enable', { challenge_token: challengeToken, code: code.trim() });
+      if (data.recovery_codes && data.recovery_codes.length > 0) {
+        // Show the recovery codes once before leaving the page
+        setSession(data);
+        setStep('recovery-codes');
+      } else {
+        finishLogin(data);
+      }
+    });
+  };
+
   return (
     <div className="flex items-center justify-center min-h-screen bg-gray-100">
       <div className="w-full max-w-md p-8 space-y-6 bg-white rounded-lg shadow-md">
         <h1 className="text-2xl font-bold text-center text-gray-800">Admin Login</h1>
-        <form onSubmit={handleLogin} className="space-y-6">
-          <div>
-            <label htmlFor="username" className="text-sm font-semibold text-gray-600">Username</label>
-            <input
-              id="username"
-              name="username"
-              type="text"
-              value={username}
-              onChange={(e) => setUsername(e.target.value)}
-              required
-              className="w-full px-4 py-2 mt-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500"
-            />
-          </div>
-          <div>
-            <label htmlFor="password" className="text-sm font-semibold text-gray-600">Password</label>
-            <div className="relative mt-2">
+
+        {step === 'password' && (
+          <form onSubmit={handleLogin} className="space-y-6">
+            <div>
+              <label htmlFor="username" className="text-sm font-semibold text-gray-600">Username</label>
               <input
-                id="password"
-                name="password"
-                type={showPassword ? 'text' : 'password'}
-                value={password}
-                onChange={(e) => setPassword(e.target.value)}
+                id="username"
+                name="username"
+                type="text"
+                value={username}
+                onChange={(e) => setUsername(e.target.value)}
                 required
-                className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500 pr-10"
+                className={inputClassName}
               />
-              <button
-                type="button"
-                onClick={() => setShowPassword(!showPassword)}
-                className="absolute right-3 top-1/2 transform -translate-y-1/2 text-gray-500 hover:text-gray-700 focus:outline-none"
-                aria-label={showPassword ? 'Hide password' : 'Show password'}
-              >
-                {showPassword ? <FaEyeSlash /> : <FaEye />}
+            </div>
+            <div>
+              <label htmlFor="password" className="text-sm font-semibold text-gray-600">Password</label>
+              <div className="relative mt-2">
+                <input
+                  id="password"
+                  name="password"
+                  type={showPassword ? 'text' : 'password'}
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  required
+                  className="w-full px-4 py-2 border rounded-lg focus:outline-none focus:ring-2 focus:ring-blue-500 pr-10"
+                />
+                <button
+                  type="button"
+                  onClick={() => setShowPassword(!showPassword)}
+                  className="absolute right-3 top-1/2 transform -translate-y-1/2 text-gray-500 hover:text-gray-700 focus:outline-none"
+                  aria-label={showPassword ? 'Hide password' : 'Show password'}
+                >
+                  {showPassword ? <FaEyeSlash /> : <FaEye />}
+                </button>
+              </div>
+            </div>
+            <div>
+              <button type="submit" disabled={loading} className={buttonClassName}>
+                {loading ? 'Logging in...' : 'Login'}
+              </button>
+            </div>
+            {error && <p className="text-sm text-center text-red-500">{error}</p>}
+          </form>
+        )}
+
+        {step === 'verify' && (
+          <form onSubmit={handleVerify} className="space-y-6">
+            <div>
+              <label htmlFor="code" className="text-sm font-semibold text-gray-600">
+                {useRecoveryCode ? 'Recovery code' : 'Authentication code'}
+              </label>
+              <input
+                id="code"
+                name="code"
+                type="text"
+                inputMode={useRecoveryCode ? 'text' : 'numeric'}
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                required
+                autoFocus
+                className={inputClassName}
+              />
+            </div>
+            <div>
+              <button type="submit" disabled={loading} className={buttonClassName}>
+                {loading ? 'Verifying...' : 'Verify'}
               </button>
             </div>
-          </div>
-          <div>
             <button
-              type="submit"
-              disabled={loading}
-              className="w-full py-2 px-4 font-semibold text-white bg-blue-600 rounded-lg hover:bg-blue-700 disabled:bg-gray-400"
+              type="button"
+              onClick={() => {
+                setUseRecoveryCode(!useRecoveryCode);
+                setCode('');
+              }}
+              className="w-full text-sm text-blue-600 hover:underline"
             >
-              {loading ? 'Logging in...' : 'Login'}
+              {useRecoveryCode ? 'Use the authenticator app instead' : 'Use a recovery code instead'}
+            </button>
+            {error && <p className="text-sm text-center text-red-500">{error}</p>}
+          </form>
+        )}
+
+        {step === 'setup' && setup && (
+          <form onSubmit={handleEnable} className="space-y-6">
+            <p className="text-sm text-gray-600">
+              Your role requires two-factor authentication. Add this key to your authenticator app, then enter the code it shows.
+            </p>
+            <div className="p-3 bg-gray-100 rounded-lg font-mono text-sm text-center break-all">{setup.secret}</div>
+            <p className="text-sm text-center">
+              <a href={setup.provisioning_uri} className="text-blue-600 hover:underline">Open in authenticator app</a>
+            </p>
+            <div>
+              <label htmlFor="setup_code" className="text-sm font-semibold text-gray-600">Authentication code</label>
+              <input
+                id="setup_code"
+                name="setup_code"
+                type="text"
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                required
+                className={inputClassName}
+              />
+            </div>
+            <div>
+              <button type="submit" disabled={loading} className={buttonClassName}>
+                {loading ? 'Verifying...' : 'Enable and log in'}
+              </button>
+            </div>
+            {error && <p className="text-sm text-center text-red-500">{error}</p>}
+          </form>
+        )}
+
+        {step === 'recovery-codes' && session && (
+          <div className="space-y-6">
+            <p className="text-sm text-gray-600">
+              Save these recovery codes somewhere safe. Each one can be used once if you lose access to your authenticator app. They will not be shown again.
+            </p>
+            <ul className="grid grid-cols-2 gap-2 p-3 bg-gray-100 rounded-lg font-mono text-sm">
+              {session.recovery_codes?.map((recoveryCode) => (
+                <li key={recoveryCode}>{recoveryCode}</li>
+              ))}
+            </ul>
+            <button type="button" onClick={() => finishLogin(session)} className={buttonClassName}>
+              Continue
             </button>
           </div>
-          {error && <p className="text-sm text-center text-red-500">{error}</p>}
-        </form>
+        )}
+
+        {step === 'password' && (
+          <p className="text-sm text-center">
+            <Link href="/admin/forgot-password" className="text-blue-600 hover:underline">Forgot password?</Link>
+          </p>
+        )}
       </div>
     </div>
   );
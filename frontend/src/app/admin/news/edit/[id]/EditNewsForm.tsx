@@ -82,7 +82,12 @@ export default function EditNewsForm() {
       if (!newsId) return;
       setLoading(true);
       try {
-        const res = await fetch(`${apiUrl}/api/v1/posts/${newsId}`);
+        const token = Cookies.get('jwt_token');
+        const res = await fetch(`${apiUrl}/api/v1/admin/posts/${newsId}`, {
+          headers: {
+            'Authorization': `Bearer ${token}`,
+          },
+        });
         if (!res.ok) {
           throw new Error('Failed to fetch news');
         }
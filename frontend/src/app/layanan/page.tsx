@@ -69,7 +69,7 @@ export default async function LayananPage() {
       <h1 className="text-3xl font-bold text-center text-gray-800 mb-12">Layanan Administrasi Desa</h1>
       <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-8">
         {services.map((service) => (
-          <div key={service.id} className="bg-white p-8 rounded-lg shadow-md hover:shadow-xl transition-shadow duration-300 flex flex-col items-center text-center">
+          <div key={service.id} id={`layanan-${service.id}`} className="bg-white p-8 rounded-lg shadow-md hover:shadow-xl transition-shadow duration-300 flex flex-col items-center text-center">
             <h3 className="text-xl font-semibold text-gray-800 mb-2">{service.service_name}</h3>
             <p className="text-gray-600">{service.description}</p>
             {service.requirements && (
@@ -1,67 +0,0 @@
-import { MetadataRoute } from 'next';
-
-export default async function sitemap(): Promise<MetadataRoute.Sitemap> {
-  const baseUrl = 'https://seirotan.desa.id';
-
-  // Static pages
-  const staticPages = [
-    {
-      url: baseUrl,
-      lastModified: new Date(),
-      changeFrequency: 'daily' as const,
-      priority: 1.0,
-    },
-    {
-      url: `${baseUrl}/profil`,
-      lastModified: new Date(),
-      changeFrequency: 'monthly' as const,
-      priority: 0.8,
-    },
-    {
-      url: `${baseUrl}/pemerintahan`,
-      lastModified: new Date(),
-      changeFrequency: 'monthly' as const,
-      priority: 0.8,
-    },
-    {
-      url: `${baseUrl}/layanan`,
-      lastModified: new Date(),
-      changeFrequency: 'weekly' as const,
-      priority: 0.7,
-    },
-    {
-      url: `${baseUrl}/potensi`,
-      lastModified: new Date(),
-      changeFrequency: 'weekly' as const,
-      priority: 0.7,
-    },
-    {
-      url: `${baseUrl}/berita`,
-      lastModified: new Date(),
-      changeFrequency: 'daily' as const,
-      priority: 0.9,
-    },
-    {
-      url: `${baseUrl}/kontak`,
-      lastModified: new Date(),
-      changeFrequency: 'monthly' as const,
-      priority: 0.6,
-    },
-  ];
-
-  // TODO: Fetch dynamic pages (news articles) from API
-  // const apiUrl = process.env.NEXT_PUBLIC_API_URL;
-  // const newsResponse = await fetch(`${apiUrl}/api/v1/posts`);
-  // const news = await newsResponse.json();
-  // const newsPages = news.data.map((item: any) => ({
-  //   url: `${baseUrl}/berita/${item.slug}`,
-  //   lastModified: new Date(item.updated_at),
-  //   changeFrequency: 'weekly' as const,
-  //   priority: 0.6,
-  // }));
-
-  return [
-    ...staticPages,
-    // ...newsPages,
-  ];
-}
@@ -5,6 +5,7 @@ import { useRouter } from 'next/navigation';
 import Cookies from 'js-cookie';
 import { User, LogOut, UserCircle } from 'lucide-react';
 import { jwtDecode } from 'jwt-decode';
+import { logout } from '@/services/api';
 
 interface JWTPayload {
   user_id: number;
@@ -44,8 +45,8 @@ export default function AdminHeader() {
     };
   }, []);
 
-  const handleLogout = () => {
-    Cookies.remove('jwt_token');
+  const handleLogout = async () => {
+    await logout();
     router.push('/admin/login');
   };
 
@@ -1,13 +1,50 @@
 'use client';
 
+import { useEffect, useState } from 'react';
+import { useRouter } from 'next/navigation';
 import AdminHeader from './AdminHeader';
 import AdminSidebar from './AdminSidebar';
+import { ensureFreshToken, secondsUntilExpiry } from '@/services/api';
 
 interface AdminLayoutProps {
   children: React.ReactNode;
 }
 
 export default function AdminLayout({ children }: AdminLayoutProps) {
+  const router = useRouter();
+  const [sessionReady, setSessionReady] = useState(false);
+
+  // Access tokens are short-lived: refresh before rendering the page (its requests read the
+  // jwt_token cookie directly) and again shortly before the token expires
+  useEffect(() => {
+    let timer: ReturnType<typeof setTimeout> | undefined;
+    let cancelled = false;
+
+    const keepSessionFresh = async () => {
+      const token = await ensureFreshToken();
+      if (cancelled) {
+        return;
+      }
+      if (!token) {
+        router.push('/admin/login');
+        return;
+      }
+      setSessionReady(true);
+      const refreshInMs = Math.max((secondsUntilExpiry(token) - 60) * 1000, 5000);
+      timer = setTimeout(keepSessionFresh, refreshInMs);
+    };
+
+    keepSessionFresh();
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [router]);
+
+  if (!sessionReady) {
+    return null;
+  }
+
   return (
     <div className="flex min-h-screen bg-gray-100">
       <AdminSidebar />
@@ -19,4 +56,4 @@ export default function AdminLayout({ children }: AdminLayoutProps) {
       </div>
     </div>
   );
-}
\ No newline at end of file
+}
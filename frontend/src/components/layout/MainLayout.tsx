@@ -3,6 +3,7 @@
 import { usePathname } from 'next/navigation';
 import Header from './Header';
 import Footer from './Footer';
+import PageViewTracker from './PageViewTracker';
 
 export default function MainLayout({ children }: { children: React.ReactNode }) {
   const pathname = usePathname();
@@ -10,6 +11,7 @@ export default function MainLayout({ children }: { children: React.ReactNode })
 
   return (
     <>
+      {!isAdminPage && <PageViewTracker />}
       {!isAdminPage && <Header />}
       {children}
       {!isAdminPage && <Footer />}
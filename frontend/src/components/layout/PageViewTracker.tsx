@@ -0,0 +1,49 @@
+'use client';
+
+import { useEffect, useRef } from 'react';
+import { usePathname } from 'next/navigation';
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8081';
+
+function hasOptedOut(): boolean {
+  const nav = navigator as Navigator & { globalPrivacyControl?: boolean };
+  return nav.doNotTrack === '1' || nav.globalPrivacyControl === true;
+}
+
+/**
+ * Reports every public page a visitor opens to the backend's analytics beacon (POST /api/v1/track).
+ * The body is sent as text/plain so the cross-origin beacon needs no CORS preflight.
+ * Nothing is sent when the visitor opted out with Do Not Track or Global Privacy Control.
+ */
+export default function PageViewTracker() {
+  const pathname = usePathname();
+  const previousUrl = useRef<string | null>(null);
+
+  useEffect(() => {
+    if (!pathname || pathname.startsWith('/admin') || hasOptedOut()) {
+      return;
+    }
+
+    // Wait a tick so the title of the new page is set
+    const timer = setTimeout(() => {
+      const body = JSON.stringify({
+        // The backend keeps only the utm_* parameters of the query string
+        path: pathname + window.location.search,
+        title: document.title,
+        // Navigations inside the site come from the previous page, the first one from document.referrer
+        referrer: previousUrl.current ?? document.referrer,
+      });
+      previousUrl.current = window.location.href;
+
+      const url = `${API_URL}/api/v1/track`;
+      const blob = new Blob([body], { type: 'text/plain' });
+      if (!navigator.sendBeacon?.(url, blob)) {
+        fetch(url, { method: 'POST', body, keepalive: true, headers: { 'Content-Type': 'text/plain' } }).catch(() => {});
+      }
+    }, 0);
+
+    return () => clearTimeout(timer);
+  }, [pathname]);
+
+  return null;
+}
@@ -1,5 +1,6 @@
-import axios from 'axios';
+import axios, { InternalAxiosRequestConfig } from 'axios';
 import Cookies from 'js-cookie';
+import { jwtDecode } from 'jwt-decode';
 
 const API_URL = (typeof window === 'undefined' && process.env.INTERNAL_API_URL)
   ? process.env.INTERNAL_API_URL
@@ -26,6 +27,121 @@ api.interceptors.request.use(
   }
 );
 
+// Session tokens as returned by /auth/login, /auth/2fa/verify and /auth/refresh
+export type AuthSession = {
+  token: string;
+  refresh_token: string;
+  expires_in: number;
+  role: string;
+};
+
+// Refresh the access token this many seconds before it expires
+const TOKEN_REFRESH_MARGIN = 60;
+
+// The cookies live as long as the refresh token; the access token inside expires much sooner
+const SESSION_COOKIE_DAYS = 7;
+
+export const storeSession = (session: AuthSession) => {
+  const options = { expires: SESSION_COOKIE_DAYS, sameSite: 'strict' as const };
+  Cookies.set('jwt_token', session.token, options);
+  Cookies.set('refresh_token', session.refresh_token, options);
+  Cookies.set('user_role', session.role, options);
+};
+
+export const clearSession = () => {
+  Cookies.remove('jwt_token');
+  Cookies.remove('refresh_token');
+  Cookies.remove('user_role');
+};
+
+// Seconds until the access token expires, or 0 if it cannot be read
+export const secondsUntilExpiry = (token: string): number => {
+  try {
+    const { exp } = jwtDecode<{ exp?: number }>(token);
+    return exp ? exp - Date.now() / 1000 : 0;
+  } catch {
+    return 0;
+  }
+};
+
+let refreshing: Promise<string | null> | null = null;
+
+// Exchange the refresh token for a new session. Concurrent callers share one request,
+// because the server rotates the refresh token and only accepts it once.
+export const refreshSession = (): Promise<string | null> => {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = Cookies.get('refresh_token');
+      if (!refreshToken) {
+        return null;
+      }
+      try {
+        const response = await axios.post<AuthSession>(`${API_URL}/api/v1/auth/refresh`, {
+          refresh_token: refreshToken,
+        });
+        storeSession(response.data);
+        return response.data.token;
+      } catch {
+        clearSession();
+        return null;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
+// Return an access token that is valid for at least TOKEN_REFRESH_MARGIN seconds,
+// refreshing the session if needed, or null if the user has to log in again
+export const ensureFreshToken = async (): Promise<string | null> => {
+  const token = Cookies.get('jwt_token');
+  if (token && secondsUntilExpiry(token) > TOKEN_REFRESH_MARGIN) {
+    return token;
+  }
+  if (!Cookies.get('refresh_token')) {
+    return token && secondsUntilExpiry(token) > 0 ? token : null;
+  }
+  return refreshSession();
+};
+
+// Revoke the session on the server and forget its tokens
+export const logout = async () => {
+  const refreshToken = Cookies.get('refresh_token');
+  clearSession();
+  if (refreshToken) {
+    await axios.post(`${API_URL}/api/v1/auth/logout`, { refresh_token: refreshToken }).catch(() => undefined);
+  }
+};
+
+// On a 401, refresh the session once and retry the request with the new access token
+api.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+    if (
+      typeof window === 'undefined' ||
+      error.response?.status !== 401 ||
+      !original ||
+      original._retried ||
+      original.url?.includes('/auth/')
+    ) {
+      return Promise.reject(error);
+    }
+
+    original._retried = true;
+    const token = await refreshSession();
+    if (!token) {
+      if (window.location.pathname.startsWith('/admin')) {
+        window.location.href = '/admin/login';
+      }
+      return Promise.reject(error);
+    }
+    original.headers.Authorization = `Bearer ${token}`;
+    return api(original);
+  }
+);
+
 export type News = {
   id: number;
   title: string;
@@ -200,6 +316,8 @@ export interface ContentStats {
   total_services: number;
   total_contacts: number;
   unread_contacts: number;
+  open_contacts: number;
+  spam_contacts: number;
 }
 
 export interface AnalyticsStats {